@@ -1,16 +1,19 @@
 package main
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
+	"context"
 	"finance-backend/internal/app"
 	"finance-backend/internal/delivery/http/handlers"
 	approuters "finance-backend/internal/delivery/http/routers"
+	attemptrepo "finance-backend/internal/repository/attempt"
+	mfarepo "finance-backend/internal/repository/mfa"
+	oidcrepo "finance-backend/internal/repository/oidc"
+	orgrepo "finance-backend/internal/repository/organization"
+	tokenrepo "finance-backend/internal/repository/token"
 	userrepo "finance-backend/internal/repository/user"
 	userusecase "finance-backend/internal/usecase/user"
 	"log"
 	"net/http"
-	"time"
 )
 
 func main() {
@@ -23,25 +26,39 @@ func main() {
 	}
 	defer deps.CloseDependencies()
 
-	// Генерация ключа для JWT
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		logger.Fatalf("Failed to generate private key: %v", err)
-	}
-
 	// Инициализация репозиториев
 	userRepo := userrepo.NewUserRepository(deps.DB, deps.Logger)
+	tokenRepo := tokenrepo.NewTokenRepository(deps.Logger, deps.DB)
+	mfaRepo := mfarepo.NewMFARepository(deps.Logger, deps.DB)
+	attemptRepo := attemptrepo.NewAttemptRepository(deps.Logger, deps.DB)
+	oidcRepo := oidcrepo.NewOIDCRepository(deps.Logger, deps.DB)
+	orgRepo := orgrepo.NewOrganizationRepository(deps.Logger, deps.DB)
 
 	// Инициализация use cases
 	transactionService := deps.TransactionService
-	userUseCase := userusecase.NewUserUseCase(userRepo, privateKey, 24*time.Hour)
+	userUseCase := userusecase.NewUserUseCase(userRepo, tokenRepo, mfaRepo, attemptRepo, oidcRepo, orgRepo, deps.AuditUseCase, deps.JWTKeys, deps.Mailer, deps.SSO, app.NewUserSettings(deps.Config))
 
 	// Инициализация обработчиков
 	userHandler := handlers.NewUserHandler(logger, userUseCase)
-	analyticsHandler := handlers.NewAnalyticsHandler(deps.DB, deps.Logger)
+	analyticsHandler := handlers.NewAnalyticsHandler(deps.DB, deps.Logger, deps.BankDirectory)
+	bankHandler := handlers.NewBankHandler(deps.BankDirectory)
+	counterpartyHandler := handlers.NewCounterpartyHandler(deps.Logger, deps.CounterpartyUseCase)
+	attachmentHandler := handlers.NewAttachmentHandler(deps.Logger, deps.AttachmentUseCase, deps.Config.Attachments.MaxSize)
+	adminUserHandler := handlers.NewAdminUserHandler(deps.Logger, userUseCase)
+	apiKeyHandler := handlers.NewAPIKeyHandler(deps.Logger, deps.APIKeyUseCase)
+	organizationHandler := handlers.NewOrganizationHandler(deps.Logger, deps.OrganizationUseCase)
+	auditHandler := handlers.NewAuditHandler(deps.Logger, deps.AuditUseCase)
+	periodHandler := handlers.NewPeriodHandler(deps.Logger, deps.PeriodUseCase)
+	categoryHandler := handlers.NewCategoryHandler(deps.Logger, deps.CategoryUseCase)
 
 	// Настройка маршрутизации
-	router := approuters.NewMuxRouter(userHandler, analyticsHandler, transactionService)
+	router := approuters.NewMuxRouter(userHandler, analyticsHandler, bankHandler, counterpartyHandler, attachmentHandler, adminUserHandler, apiKeyHandler, organizationHandler, auditHandler, periodHandler, categoryHandler, deps.FileServer, transactionService, userUseCase, deps.APIKeyUseCase, deps.JWTKeys, deps.Config.Server.TrustProxyHeaders)
+
+	// Очистка корзины и брошенных загрузок работает, пока запущен сервер
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	app.StartTrashPurge(purgeCtx, deps)
+	app.StartOrphanSweep(purgeCtx, deps)
 
 	// Запуск сервера
 	logger.Println("Server starting on :8089")
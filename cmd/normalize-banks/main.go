@@ -0,0 +1,28 @@
+// normalize-banks проставляет БИК банка отправителя транзакциям, сохраненным
+// только с текстовым названием банка. Запускается один раз после загрузки или
+// обновления справочника ED807; новые транзакции получают БИК при записи.
+package main
+
+import (
+	"context"
+	"log"
+
+	"finance-backend/internal/app"
+)
+
+func main() {
+	deps, err := app.InitDependencies()
+	if err != nil {
+		log.Fatalf("Failed to initialize dependencies: %v", err)
+	}
+	defer deps.CloseDependencies()
+
+	if len(deps.BankDirectory.Search("", 1)) == 0 {
+		log.Fatalf("Bank directory is empty, check BANK_DIRECTORY_PATH=%s", deps.Config.BankDirectory.Path)
+	}
+
+	if err := deps.TransactionService.NormalizeSenderBanks(context.Background()); err != nil {
+		log.Fatalf("Failed to normalize sender banks: %v", err)
+	}
+	log.Println("Sender banks normalized")
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"finance-backend/internal/usecase/attachment"
+	"finance-backend/pkg/logger"
+)
+
+// StartOrphanSweep запускает SweepOrphanObjects в фоне, если очистка включена в конфигурации.
+// Как и очистка корзины, останавливается с отменой ctx и запускается только сервером.
+func StartOrphanSweep(ctx context.Context, deps *AppDependencies) {
+	cfg := deps.Config.Attachments
+	if cfg.OrphanRetention <= 0 || cfg.OrphanSweepInterval <= 0 {
+		return
+	}
+	go SweepOrphanObjects(ctx, deps.AttachmentUseCase, cfg.OrphanRetention, cfg.OrphanSweepInterval, deps.Logger)
+}
+
+// SweepOrphanObjects периодически удаляет из хранилища файлы вложений, для которых так и не
+// появилась запись в БД за retention. Первая очистка выполняется сразу при запуске.
+func SweepOrphanObjects(ctx context.Context, useCase attachment.IAttachmentUseCase, retention, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := useCase.DeleteOrphanObjects(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Error(ctx, "failed to delete orphan attachment objects", map[string]interface{}{"error": err.Error()})
+		} else if deleted > 0 {
+			log.Info(ctx, "orphan_attachment_objects_deleted", map[string]interface{}{"count": deleted})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
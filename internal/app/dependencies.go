@@ -2,44 +2,75 @@ package app
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto/rand"
 	"errors"
 	"finance-backend/internal/config"
 	handlers "finance-backend/internal/delivery/http/handlers"
+	"finance-backend/internal/domain"
 	"finance-backend/internal/domain/transaction"
+	"finance-backend/internal/gateways/bank_directory"
 	"finance-backend/internal/gateways/file_gateway"
+	"finance-backend/internal/gateways/mail_gateway"
+	"finance-backend/internal/gateways/oidc_gateway"
+	apiKeyRepository "finance-backend/internal/repository/apikey"
 	articleRepository "finance-backend/internal/repository/article"
+	attachmentRepository "finance-backend/internal/repository/attachment"
+	attemptRepository "finance-backend/internal/repository/attempt"
+	auditRepository "finance-backend/internal/repository/audit"
 	categoryRepository "finance-backend/internal/repository/category"
+	counterpartyRepository "finance-backend/internal/repository/counterparty"
+	mfaRepository "finance-backend/internal/repository/mfa"
+	oidcRepository "finance-backend/internal/repository/oidc"
+	organizationRepository "finance-backend/internal/repository/organization"
+	periodRepository "finance-backend/internal/repository/period"
+	tokenRepository "finance-backend/internal/repository/token"
 	transactionRepository "finance-backend/internal/repository/transaction"
 	userRepository "finance-backend/internal/repository/user"
-	"os"
-	"strings"
-	"time"
+	"fmt"
+	"net/http"
 
+	"finance-backend/internal/usecase/apikey"
 	"finance-backend/internal/usecase/article"
+	"finance-backend/internal/usecase/attachment"
+	"finance-backend/internal/usecase/audit"
 	"finance-backend/internal/usecase/category"
+	"finance-backend/internal/usecase/counterparty"
+	"finance-backend/internal/usecase/organization"
+	"finance-backend/internal/usecase/period"
 	"finance-backend/internal/usecase/user"
 
+	"finance-backend/pkg/jwtkeys"
 	"finance-backend/pkg/logger"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
 // AppDependencies содержит все зависимости приложения.
 type AppDependencies struct {
-	Config             *config.Config
-	Logger             *logger.Logger
-	CategoryUseCase    category.ICategoryUseCase
-	ArticleUseCase     article.IArticleUseCase
-	UserUseCase        user.IUserUseCase
-	TransactionService transaction.Service
-	AnalyticsHandler   *handlers.AnalyticsHandler
-	DB                 *sqlx.DB
+	Config              *config.Config
+	Logger              *logger.Logger
+	Mailer              mail_gateway.IMailGateway
+	SSO                 oidc_gateway.IOIDCGateway // nil, если OIDC_ISSUER не задан
+	CategoryUseCase     category.ICategoryUseCase
+	CounterpartyUseCase counterparty.ICounterpartyUseCase
+	ArticleUseCase      article.IArticleUseCase
+	AttachmentUseCase   attachment.IAttachmentUseCase
+	UserUseCase         user.IUserUseCase
+	UserAdminUseCase    user.IUserAdminUseCase
+	APIKeyUseCase       apikey.IAPIKeyUseCase
+	OrganizationUseCase organization.IOrganizationUseCase
+	AuditUseCase        audit.IAuditUseCase
+	PeriodUseCase       period.IPeriodUseCase
+	TransactionService  transaction.Service
+	AnalyticsHandler    *handlers.AnalyticsHandler
+	BankDirectory       bank_directory.IBankDirectory
+	FileServer          http.Handler // обслуживает подписанные ссылки локального хранилища, nil для S3
+	JWTKeys             *jwtkeys.KeyManager
+	DB                  *sqlx.DB
 }
 
 func InitDependencies() (*AppDependencies, error) {
@@ -65,52 +96,89 @@ func InitDependencies() (*AppDependencies, error) {
 		return nil, err
 	}
 
-	// 3.1 Подключение к S3
-	sess, err := session.NewSession(&aws.Config{
-		Endpoint:         aws.String(cfg.S3.Url),
-		Credentials:      credentials.NewStaticCredentials(cfg.S3.S3RootUser, cfg.S3.S3RootPassword, ""),
-		DisableSSL:       aws.Bool(true),
-		S3ForcePathStyle: aws.Bool(true),
-		Region:           aws.String("us-east-1"), // может быть любое, главное заполнить
-	})
-
+	// 3.1 Файловое хранилище
+	file_gw, err := NewFileGateway(cfg, log)
 	if err != nil {
-		log.Fatal(context.TODO(), "Failed to create AWS session", map[string]interface{}{"error": err.Error()})
+		log.Fatal(context.TODO(), "Failed to init file storage", map[string]interface{}{"error": err.Error(), "driver": cfg.FileStorage.Driver})
 	}
 
-	log.Info(context.TODO(), "connected_to_s3", map[string]interface{}{"endpoint": cfg.S3.Url})
-
-	key, err := loadPrivateKeyFromEnv("JWT_PRIVATE_KEY")
-
+	jwtKeys, err := NewJWTKeyManager(cfg, log)
+	if err != nil {
+		log.Fatal(context.TODO(), "Failed to load JWT keys", map[string]interface{}{"error": err.Error()})
+	}
+	mailer, err := NewMailGateway(cfg, log)
+	if err != nil {
+		log.Fatal(context.TODO(), "Failed to init mail sender", map[string]interface{}{"error": err.Error(), "driver": cfg.Mail.Driver})
+	}
+	sso, err := NewOIDCGateway(cfg, log)
 	if err != nil {
-		log.Fatal(context.TODO(), "Failed to get JWT private key", map[string]interface{}{"error": err.Error()})
+		log.Fatal(context.TODO(), "Failed to init OIDC provider", map[string]interface{}{"error": err.Error(), "issuer": cfg.OIDC.Issuer})
 	}
 	// 4. Репозитории
 	categoryRepo := categoryRepository.NewCategoryRepository(log, db)
 	articleRepo := articleRepository.NewArticleRepository(log, db)
 	userRepo := userRepository.NewUserRepository(db, log)
+	tokenRepo := tokenRepository.NewTokenRepository(log, db)
+	mfaRepo := mfaRepository.NewMFARepository(log, db)
+	attemptRepo := attemptRepository.NewAttemptRepository(log, db)
+	oidcRepo := oidcRepository.NewOIDCRepository(log, db)
+	organizationRepo := organizationRepository.NewOrganizationRepository(log, db)
+	apiKeyRepo := apiKeyRepository.NewAPIKeyRepository(log, db)
 	transactionRepo := transactionRepository.NewTransactionRepository(db, log)
+	counterpartyRepo := counterpartyRepository.NewCounterpartyRepository(log, db)
+	attachmentRepo := attachmentRepository.NewAttachmentRepository(log, db)
+	auditRepo := auditRepository.NewAuditRepository(log, db)
+	periodRepo := periodRepository.NewPeriodRepository(log, db)
 
 	// 4.1 Гейтвеи
-	file_gw := file_gateway.NewS3Gateway(sess, log)
+	bankDirectory := bank_directory.NewED807Directory(log)
+	if err := bankDirectory.LoadFile(cfg.BankDirectory.Path); err != nil {
+		log.Warn(context.TODO(), "bank directory is not loaded, sender banks will not be normalized", map[string]interface{}{
+			"error": err.Error(),
+			"path":  cfg.BankDirectory.Path,
+		})
+	}
 
 	// 5. Бизнес-логика
-	categoryUseCase := category.NewCategoryUseCase(log, categoryRepo)
+	auditUseCase := audit.NewAuditUseCase(log, auditRepo)
+	categoryUseCase := category.NewCategoryUseCase(log, categoryRepo, auditUseCase)
+	periodUseCase := period.NewPeriodUseCase(log, periodRepo, auditUseCase)
 	articleUseCase := article.NewArticleUseCase(log, articleRepo, file_gw, cfg.ImageBucketName)
-	userUseCase := user.NewUserUseCase(userRepo, key, time.Hour*24)
-	transactionService := transaction.NewService(transactionRepo)
+	userUseCase := user.NewUserUseCase(userRepo, tokenRepo, mfaRepo, attemptRepo, oidcRepo, organizationRepo, auditUseCase, jwtKeys, mailer, sso, NewUserSettings(cfg))
+	apiKeyUseCase := apikey.NewAPIKeyUseCase(log, apiKeyRepo)
+	organizationUseCase := organization.NewOrganizationUseCase(log, organizationRepo, userRepo, mailer, organization.Settings{
+		InvitationTTL: cfg.Organizations.InvitationTTL,
+		InvitationURL: cfg.Organizations.InvitationURL,
+	})
+	counterpartyUseCase := counterparty.NewCounterpartyUseCase(log, counterpartyRepo, categoryRepo)
+	attachmentUseCase := attachment.NewAttachmentUseCase(log, attachmentRepo, file_gw, cfg.Attachments.BucketName, cfg.Attachments.MaxSize, cfg.Attachments.URLTTL)
+	transactionService := transaction.NewService(transactionRepo, bankDirectory, counterpartyRepo, attachmentUseCase, auditUseCase)
+
+	analyticsHandler := handlers.NewAnalyticsHandler(db, log, bankDirectory)
 
-	analyticsHandler := handlers.NewAnalyticsHandler(db, log)
+	fileServer, _ := file_gw.(http.Handler)
 
 	return &AppDependencies{
-		Config:             cfg,
-		Logger:             log,
-		CategoryUseCase:    categoryUseCase,
-		ArticleUseCase:     articleUseCase,
-		UserUseCase:        userUseCase,
-		TransactionService: transactionService,
-		AnalyticsHandler:   analyticsHandler,
-		DB:                 db,
+		Config:              cfg,
+		Logger:              log,
+		Mailer:              mailer,
+		SSO:                 sso,
+		CategoryUseCase:     categoryUseCase,
+		CounterpartyUseCase: counterpartyUseCase,
+		ArticleUseCase:      articleUseCase,
+		AttachmentUseCase:   attachmentUseCase,
+		UserUseCase:         userUseCase,
+		UserAdminUseCase:    userUseCase,
+		APIKeyUseCase:       apiKeyUseCase,
+		OrganizationUseCase: organizationUseCase,
+		AuditUseCase:        auditUseCase,
+		PeriodUseCase:       periodUseCase,
+		TransactionService:  transactionService,
+		AnalyticsHandler:    analyticsHandler,
+		BankDirectory:       bankDirectory,
+		FileServer:          fileServer,
+		JWTKeys:             jwtKeys,
+		DB:                  db,
 	}, nil
 }
 
@@ -123,18 +191,136 @@ func (d *AppDependencies) CloseDependencies() {
 	d.Logger.Info(context.TODO(), "Application dependencies closed successfully", nil)
 }
 
-func loadPrivateKeyFromEnv(envVar string) (*rsa.PrivateKey, error) {
-	raw := os.Getenv(envVar)
-	if raw == "" {
-		return nil, errors.New("missing environment variable: " + envVar)
+// NewUserSettings собирает параметры входа и восстановления доступа из конфигурации.
+func NewUserSettings(cfg *config.Config) user.Settings {
+	return user.Settings{
+		AccessTokenTTL:   cfg.Auth.AccessTokenTTL,
+		RefreshTokenTTL:  cfg.Auth.RefreshTokenTTL,
+		PasswordResetTTL: cfg.Auth.PasswordResetTTL,
+		PasswordResetURL: cfg.Auth.PasswordResetURL,
+		TOTPIssuer:       cfg.Auth.TOTPIssuer,
+		LoginLimits: user.LoginLimits{
+			LoginFreeAttempts:  cfg.Auth.LoginFreeAttempts,
+			IPFreeAttempts:     cfg.Auth.IPFreeAttempts,
+			RegistrationsPerIP: cfg.Auth.RegistrationsPerIP,
+			LockoutBase:        cfg.Auth.LockoutBase,
+			LockoutMax:         cfg.Auth.LockoutMax,
+			Window:             cfg.Auth.AttemptsWindow,
+		},
+		OIDC: user.OIDCSettings{
+			AutoProvision:  cfg.OIDC.AutoProvision,
+			AllowedDomains: cfg.OIDC.AllowedDomains,
+			DefaultRole:    domain.Role(cfg.OIDC.DefaultRole),
+			LinkByEmail:    cfg.OIDC.LinkByEmail,
+		},
+	}
+}
+
+// NewOIDCGateway создает клиент провайдера OIDC. Возвращает nil, если OIDC_ISSUER не задан:
+// вход через провайдера тогда отключен.
+func NewOIDCGateway(cfg *config.Config, log *logger.Logger) (oidc_gateway.IOIDCGateway, error) {
+	if cfg.OIDC.Issuer == "" {
+		return nil, nil
+	}
+	if cfg.OIDC.ClientID == "" {
+		return nil, errors.New("OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+	}
+	if role := domain.Role(cfg.OIDC.DefaultRole); !role.IsValid() {
+		return nil, fmt.Errorf("unknown OIDC_DEFAULT_ROLE: %s", cfg.OIDC.DefaultRole)
+	}
+	log.Info(context.TODO(), "using_oidc_provider", map[string]interface{}{"issuer": cfg.OIDC.Issuer, "auto_provision": cfg.OIDC.AutoProvision})
+	return oidc_gateway.NewProvider(oidc_gateway.Config{
+		Issuer:       cfg.OIDC.Issuer,
+		ClientID:     cfg.OIDC.ClientID,
+		ClientSecret: cfg.OIDC.ClientSecret,
+		RedirectURL:  cfg.OIDC.RedirectURL,
+		Scopes:       cfg.OIDC.Scopes,
+	}), nil
+}
+
+// NewMailGateway создает отправитель писем, выбранный в конфигурации.
+func NewMailGateway(cfg *config.Config, log *logger.Logger) (mail_gateway.IMailGateway, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		if cfg.Mail.SMTPHost == "" {
+			return nil, errors.New("SMTP_HOST is required for MAIL_DRIVER=smtp")
+		}
+		log.Info(context.TODO(), "using_smtp_mail", map[string]interface{}{"host": cfg.Mail.SMTPHost, "port": cfg.Mail.SMTPPort})
+		return mail_gateway.NewSMTPGateway(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.SMTPUser, cfg.Mail.SMTPPassword, cfg.Mail.From), nil
+	case "outbox", "":
+		log.Info(context.TODO(), "using_mail_outbox", map[string]interface{}{"dir": cfg.Mail.OutboxDir})
+		return mail_gateway.NewOutboxGateway(cfg.Mail.OutboxDir, cfg.Mail.From, log)
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Mail.Driver)
 	}
+}
 
-	pemStr := strings.ReplaceAll(raw, `\n`, "\n")
+// NewFileGateway создает файловое хранилище, выбранное в конфигурации.
+func NewFileGateway(cfg *config.Config, log *logger.Logger) (file_gateway.IFileGateway, error) {
+	switch cfg.FileStorage.Driver {
+	case "fs":
+		signingKey := []byte(cfg.FileStorage.SigningKey)
+		if len(signingKey) == 0 {
+			signingKey = make([]byte, 32)
+			if _, err := rand.Read(signingKey); err != nil {
+				return nil, err
+			}
+			log.Warn(context.TODO(), "FILE_STORAGE_SIGNING_KEY is not set, presigned links will not survive restart", nil)
+		}
+		gw, err := file_gateway.NewFSGateway(cfg.FileStorage.Path, cfg.FileStorage.PublicURL, signingKey, log)
+		if err != nil {
+			return nil, err
+		}
+		log.Info(context.TODO(), "using_fs_storage", map[string]interface{}{"path": cfg.FileStorage.Path})
+		return gw, nil
+	case "s3", "":
+		sess, err := session.NewSession(&aws.Config{
+			Endpoint:         aws.String(cfg.S3.Url),
+			Credentials:      credentials.NewStaticCredentials(cfg.S3.S3RootUser, cfg.S3.S3RootPassword, ""),
+			DisableSSL:       aws.Bool(true),
+			S3ForcePathStyle: aws.Bool(true),
+			Region:           aws.String("us-east-1"), // может быть любое, главное заполнить
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Info(context.TODO(), "connected_to_s3", map[string]interface{}{"endpoint": cfg.S3.Url})
+		return file_gateway.NewS3Gateway(sess, log), nil
+	}
+	return nil, fmt.Errorf("unknown file storage driver: %s", cfg.FileStorage.Driver)
+}
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(pemStr))
-	if err != nil {
-		return nil, err
+// NewJWTKeyManager загружает ключи подписи токенов из каталога JWT_KEYS_DIR (и следит
+// за его изменениями) либо из переменных окружения.
+func NewJWTKeyManager(cfg *config.Config, log *logger.Logger) (*jwtkeys.KeyManager, error) {
+	keys := jwtkeys.NewKeyManager()
+
+	if cfg.Auth.KeysDir != "" {
+		signing, verification, err := jwtkeys.LoadDir(cfg.Auth.KeysDir)
+		if err != nil {
+			return nil, err
+		}
+		if err := keys.SetKeys(signing, verification); err != nil {
+			return nil, err
+		}
+		go keys.WatchDir(context.Background(), cfg.Auth.KeysDir, cfg.Auth.KeysReloadInterval, log)
+	} else {
+		if cfg.Auth.PrivateKey == "" {
+			return nil, errors.New("missing environment variable: JWT_PRIVATE_KEY")
+		}
+		signing, err := jwtkeys.ParsePrivateKey(cfg.Auth.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		verification, err := jwtkeys.ParsePublicKeys(cfg.Auth.PublicKey + "\n" + cfg.Auth.VerificationKeys)
+		if err != nil {
+			return nil, err
+		}
+		if err := keys.SetKeys(signing, verification); err != nil {
+			return nil, err
+		}
 	}
 
-	return privateKey, nil
+	log.Info(context.TODO(), "jwt_keys_loaded", map[string]interface{}{"signing_kid": keys.SigningKeyID(), "kids": keys.KeyIDs()})
+	return keys, nil
 }
@@ -15,14 +15,33 @@ func StartHTTPServer(deps *AppDependencies) *http.Server {
 	server := &http.Server{
 		Addr: fmt.Sprintf("%s:%s", deps.Config.Server.Address, deps.Config.Server.Port),
 		Handler: routers.NewMuxRouter(
-			// handlers.NewCategoryHandler(*deps.Logger, deps.CategoryUseCase),
 			// handlers.NewArticleHandler(*deps.Logger, deps.ArticleUseCase),
 			handlers.NewUserHandler(stdLogger, deps.UserUseCase),
 			deps.AnalyticsHandler,
+			handlers.NewBankHandler(deps.BankDirectory),
+			handlers.NewCounterpartyHandler(deps.Logger, deps.CounterpartyUseCase),
+			handlers.NewAttachmentHandler(deps.Logger, deps.AttachmentUseCase, deps.Config.Attachments.MaxSize),
+			handlers.NewAdminUserHandler(deps.Logger, deps.UserAdminUseCase),
+			handlers.NewAPIKeyHandler(deps.Logger, deps.APIKeyUseCase),
+			handlers.NewOrganizationHandler(deps.Logger, deps.OrganizationUseCase),
+			handlers.NewAuditHandler(deps.Logger, deps.AuditUseCase),
+			handlers.NewPeriodHandler(deps.Logger, deps.PeriodUseCase),
+			handlers.NewCategoryHandler(deps.Logger, deps.CategoryUseCase),
+			deps.FileServer,
 			deps.TransactionService,
+			deps.UserUseCase,
+			deps.APIKeyUseCase,
+			deps.JWTKeys,
+			deps.Config.Server.TrustProxyHeaders,
 		),
 	}
 
+	// Очистка корзины и брошенных загрузок живет, пока работает сервер, и останавливается при Shutdown.
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	server.RegisterOnShutdown(stopPurge)
+	StartTrashPurge(purgeCtx, deps)
+	StartOrphanSweep(purgeCtx, deps)
+
 	go func() {
 		deps.Logger.Info(context.Background(), "Starting HTTP server on", map[string]interface{}{"address": deps.Config.Server.Address, "port": deps.Config.Server.Port})
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
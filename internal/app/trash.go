@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"finance-backend/internal/domain/transaction"
+	"finance-backend/pkg/logger"
+)
+
+// StartTrashPurge запускает PurgeTrash в фоне, если очистка корзины включена в конфигурации.
+// Очистка останавливается с отменой ctx. Вызывается только сервером: разовые команды,
+// которые тоже собирают зависимости, корзину не очищают.
+func StartTrashPurge(ctx context.Context, deps *AppDependencies) {
+	cfg := deps.Config.Transactions
+	if cfg.TrashRetention <= 0 || cfg.TrashPurgeInterval <= 0 {
+		return
+	}
+	go PurgeTrash(ctx, deps.TransactionService, cfg.TrashRetention, cfg.TrashPurgeInterval, deps.Logger)
+}
+
+// PurgeTrash периодически окончательно удаляет транзакции, пролежавшие в корзине дольше
+// retention. Первая очистка выполняется сразу при запуске.
+func PurgeTrash(ctx context.Context, service transaction.Service, retention, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := service.PurgeDeletedTransactions(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Error(ctx, "failed to purge deleted transactions", map[string]interface{}{"error": err.Error()})
+		} else if purged > 0 {
+			log.Info(ctx, "deleted_transactions_purged", map[string]interface{}{"count": purged})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -31,6 +32,8 @@ func (db DatabaseConfig) DSN() string {
 type Server struct {
 	Address string `env:"APP_ADDRESS" env-default:"0.0.0.0"`
 	Port    string `env:"APP_PORT" env-default:"8089"`
+	// Брать адрес клиента из X-Forwarded-For/X-Real-IP. Включать только за обратным прокси.
+	TrustProxyHeaders bool `env:"APP_TRUST_PROXY_HEADERS" env-default:"false"`
 }
 
 type S3 struct {
@@ -39,15 +42,105 @@ type S3 struct {
 	S3RootPassword string `env:"S3_ROOT_PASSWORD" env-default:"development_minio_secret"`
 }
 
+// FileStorage выбирает реализацию файлового хранилища: s3 (MinIO) или fs (локальная директория).
+type FileStorage struct {
+	Driver string `env:"FILE_STORAGE_DRIVER" env-default:"s3"`
+	Path   string `env:"FILE_STORAGE_PATH" env-default:"data/storage"`
+	// Для fs: внешний адрес /files, на который ведут подписанные ссылки, и ключ подписи.
+	PublicURL  string `env:"FILE_STORAGE_PUBLIC_URL" env-default:"http://localhost:8089/api/v1/files"`
+	SigningKey string `env:"FILE_STORAGE_SIGNING_KEY"`
+}
+
+type BankDirectory struct {
+	Path string `env:"BANK_DIRECTORY_PATH" env-default:"data/ED807.xml"`
+}
+
+// Attachments — вложения к транзакциям. Файлы без записи о вложении (неподтвержденные
+// прямые загрузки) удаляются через ATTACHMENT_ORPHAN_RETENTION после загрузки; 0 отключает очистку.
+type Attachments struct {
+	BucketName          string        `env:"ATTACHMENT_BUCKET_NAME" env-default:"attachments"`
+	MaxSize             int64         `env:"ATTACHMENT_MAX_SIZE" env-default:"10485760"` // байт
+	URLTTL              time.Duration `env:"ATTACHMENT_URL_TTL" env-default:"15m"`       // срок действия подписанных ссылок
+	OrphanRetention     time.Duration `env:"ATTACHMENT_ORPHAN_RETENTION" env-default:"24h"`
+	OrphanSweepInterval time.Duration `env:"ATTACHMENT_ORPHAN_SWEEP_INTERVAL" env-default:"1h"`
+}
+
+// Auth — ключи подписи токенов. Ключи задаются либо переменными окружения, либо каталогом
+// JWT_KEYS_DIR с файлами *.pem, который перечитывается каждые JWT_KEYS_RELOAD_INTERVAL.
 type Auth struct {
-	PublicKey string `env:"JWT_PUBLIC"`
+	PrivateKey         string        `env:"JWT_PRIVATE_KEY"`
+	PublicKey          string        `env:"JWT_PUBLIC"`
+	VerificationKeys   string        `env:"JWT_VERIFICATION_KEYS"` // открытые ключи PEM: прежние и будущие ключи подписи
+	KeysDir            string        `env:"JWT_KEYS_DIR"`
+	KeysReloadInterval time.Duration `env:"JWT_KEYS_RELOAD_INTERVAL" env-default:"1m"`
+	AccessTokenTTL     time.Duration `env:"AUTH_ACCESS_TOKEN_TTL" env-default:"15m"`
+	RefreshTokenTTL    time.Duration `env:"AUTH_REFRESH_TOKEN_TTL" env-default:"720h"`
+	PasswordResetTTL   time.Duration `env:"AUTH_PASSWORD_RESET_TTL" env-default:"1h"`
+	PasswordResetURL   string        `env:"AUTH_PASSWORD_RESET_URL" env-default:"http://localhost:3000/reset-password"` // к ссылке добавляется параметр token
+	TOTPIssuer         string        `env:"AUTH_TOTP_ISSUER" env-default:"Finance"`                                     // название сервиса в приложении-аутентификаторе
+	// Защита от перебора паролей: блокировка после LoginFreeAttempts неудач подряд,
+	// с удвоением срока от LockoutBase до LockoutMax.
+	LoginFreeAttempts  int           `env:"AUTH_LOGIN_FREE_ATTEMPTS" env-default:"5"`
+	IPFreeAttempts     int           `env:"AUTH_IP_FREE_ATTEMPTS" env-default:"20"`
+	RegistrationsPerIP int           `env:"AUTH_REGISTRATIONS_PER_IP" env-default:"10"`
+	LockoutBase        time.Duration `env:"AUTH_LOCKOUT_BASE" env-default:"30s"`
+	LockoutMax         time.Duration `env:"AUTH_LOCKOUT_MAX" env-default:"15m"`
+	AttemptsWindow     time.Duration `env:"AUTH_ATTEMPTS_WINDOW" env-default:"1h"` // 0 отключает ограничения
+}
+
+// Mail выбирает способ отправки писем: smtp или outbox (файлы .eml в MAIL_OUTBOX_DIR,
+// для локальной разработки и тестов).
+type Mail struct {
+	Driver       string `env:"MAIL_DRIVER" env-default:"outbox"`
+	From         string `env:"MAIL_FROM" env-default:"Финансы <noreply@localhost>"`
+	OutboxDir    string `env:"MAIL_OUTBOX_DIR" env-default:"data/outbox"`
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     int    `env:"SMTP_PORT" env-default:"587"`
+	SMTPUser     string `env:"SMTP_USER"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+}
+
+// OIDC — вход через внешнего провайдера (authorization code + PKCE). Пустой OIDC_ISSUER
+// отключает вход. OIDC_REDIRECT_URL — страница фронтенда, которая передает code и state
+// в POST /oidc/callback; ее нужно зарегистрировать у провайдера.
+type OIDC struct {
+	Issuer         string   `env:"OIDC_ISSUER"`
+	ClientID       string   `env:"OIDC_CLIENT_ID"`
+	ClientSecret   string   `env:"OIDC_CLIENT_SECRET"` // пусто для публичного клиента
+	RedirectURL    string   `env:"OIDC_REDIRECT_URL" env-default:"http://localhost:3000/oidc/callback"`
+	Scopes         []string `env:"OIDC_SCOPES" env-default:"email,profile"` // openid добавляется всегда
+	AutoProvision  bool     `env:"OIDC_AUTO_PROVISION" env-default:"false"`
+	AllowedDomains []string `env:"OIDC_ALLOWED_EMAIL_DOMAINS"` // для автосоздания; пусто — любой домен
+	DefaultRole    string   `env:"OIDC_DEFAULT_ROLE" env-default:"user"`
+	LinkByEmail    bool     `env:"OIDC_LINK_BY_EMAIL" env-default:"true"`
+}
+
+// Organizations — приглашения в организации. ORG_INVITATION_URL — страница фронтенда,
+// к ней добавляется параметр token.
+type Organizations struct {
+	InvitationTTL time.Duration `env:"ORG_INVITATION_TTL" env-default:"168h"`
+	InvitationURL string        `env:"ORG_INVITATION_URL" env-default:"http://localhost:3000/invitations"`
+}
+
+// Transactions — корзина удаленных транзакций. По истечении TRASH_RETENTION транзакции
+// удаляются окончательно вместе с вложениями; 0 отключает очистку.
+type Transactions struct {
+	TrashRetention     time.Duration `env:"TRASH_RETENTION" env-default:"720h"`
+	TrashPurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
 }
 
 type Config struct {
 	Database        DatabaseConfig
 	Server          Server
 	Auth            Auth
+	Mail            Mail
+	OIDC            OIDC
+	Organizations   Organizations
+	Transactions    Transactions
 	S3              S3
+	FileStorage     FileStorage
+	BankDirectory   BankDirectory
+	Attachments     Attachments
 	ImageBucketName string `env:"IMAGE_BUCKET_NAME" env-default:"images"`
 	Debug           bool   `env:"DEBUG" env-default:"false"`
 	Name            string `yaml:"name" env:"APP_NAME"`
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"finance-backend/internal/delivery/http/mappers"
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	uc "finance-backend/internal/usecase/user"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// AdminUserHandler — управление пользователями. Маршруты доступны только с правом users:manage.
+type AdminUserHandler struct {
+	userUseCase uc.IUserAdminUseCase
+	log         *logger.Logger
+	validate    *validator.Validate
+}
+
+func NewAdminUserHandler(logger *logger.Logger, userUseCase uc.IUserAdminUseCase) *AdminUserHandler {
+	return &AdminUserHandler{
+		userUseCase: userUseCase,
+		log:         logger,
+		validate:    validation.New(),
+	}
+}
+
+func (h *AdminUserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	limit, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "limit", "20"))
+	offset, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+
+	filter := domain.UserSearchFilter{Search: utils.GetOrNil(queryParams, "search")}
+	if role := queryParams.Get("role"); role != "" {
+		value := domain.Role(role)
+		if !value.IsValid() {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": domain.ErrRoleInvalid.Message})
+			return
+		}
+		filter.Role = &value
+	}
+	if blocked := queryParams.Get("blocked"); blocked != "" {
+		value, err := strconv.ParseBool(blocked)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid blocked parameter"})
+			return
+		}
+		filter.Blocked = &value
+	}
+
+	users, err := h.userUseCase.SearchUsers(r.Context(), limit, offset, filter)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapPaginatedUserAccountsToResponse(users))
+}
+
+func (h *AdminUserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userUseCase.GetUser(r.Context(), mux.Vars(r)["login"])
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapUserAccountToResponse(user))
+}
+
+func (h *AdminUserHandler) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	admin, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	var requestEntity schemas.ChangeUserRoleSchema
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	user, err := h.userUseCase.ChangeUserRole(r.Context(), admin, mux.Vars(r)["login"], requestEntity.Role)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapUserAccountToResponse(user))
+}
+
+func (h *AdminUserHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	admin, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	user, err := h.userUseCase.BlockUser(r.Context(), admin, mux.Vars(r)["login"])
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapUserAccountToResponse(user))
+}
+
+func (h *AdminUserHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userUseCase.UnblockUser(r.Context(), mux.Vars(r)["login"])
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapUserAccountToResponse(user))
+}
+
+// ResetUserPassword отдает временный пароль один раз: сервер хранит только его хеш.
+func (h *AdminUserHandler) ResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	password, err := h.userUseCase.ResetUserPassword(r.Context(), mux.Vars(r)["login"])
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, schemas.TemporaryPasswordSchema{TemporaryPassword: password})
+}
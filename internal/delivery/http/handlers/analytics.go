@@ -3,7 +3,13 @@ package handlers
 import (
 	"encoding/json"
 	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/gateways/bank_directory"
+	"finance-backend/internal/repository/ownership"
+	transactionRepository "finance-backend/internal/repository/transaction"
+	"finance-backend/pkg/utils"
 	"net/http"
+	"strconv"
 
 	"finance-backend/pkg/logger"
 
@@ -14,18 +20,60 @@ import (
 type AnalyticsHandler struct {
 	db       *sqlx.DB
 	logger   *logger.Logger
+	banks    bank_directory.IBankDirectory
 	validate *validator.Validate
 }
 
-func NewAnalyticsHandler(db *sqlx.DB, logger *logger.Logger) *AnalyticsHandler {
+func NewAnalyticsHandler(db *sqlx.DB, logger *logger.Logger, banks bank_directory.IBankDirectory) *AnalyticsHandler {
 	return &AnalyticsHandler{
 		db:       db,
 		logger:   logger,
+		banks:    banks,
 		validate: validator.New(),
 	}
 }
 
+// scope возвращает область данных запроса: аналитика строится по транзакциям активной
+// организации сессии или личного учета пользователя.
+func (h *AnalyticsHandler) scope(w http.ResponseWriter, r *http.Request) (domain.DataScope, bool) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "no user in request"})
+		return domain.DataScope{}, false
+	}
+	return domain.ScopeOf(user), true
+}
+
+// transactions возвращает источник транзакций для запроса: текущие транзакции или, если
+// задан параметр as_of (RFC3339), их состояние на этот момент. Параметр as_of получает
+// номер argN.
+func (h *AnalyticsHandler) transactions(w http.ResponseWriter, r *http.Request, argN int) (string, []interface{}, bool) {
+	asOf, err := parseTimeParam(r.URL.Query(), "as_of")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "as_of must be in RFC3339 format"})
+		return "", nil, false
+	}
+	source, args := transactionRepository.TransactionSource(asOf, argN)
+	return source, args, true
+}
+
+// categoryVisible сообщает, видна ли категория id в области scope.
+func (h *AnalyticsHandler) categoryVisible(r *http.Request, scope domain.DataScope, id int) (bool, error) {
+	condition, args := ownership.CategoryScopeCondition("c", scope, 2)
+	var exists bool
+	err := h.db.GetContext(r.Context(), &exists, `SELECT EXISTS (SELECT 1 FROM categories c WHERE c.id = $1 AND `+condition+`)`,
+		append([]interface{}{id}, args...)...)
+	return exists, err
+}
+
 func (h *AnalyticsHandler) GetDynamicsByPeriod(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
 	period := r.URL.Query().Get("period")
 	if period == "" {
 		period = "month"
@@ -58,6 +106,11 @@ func (h *AnalyticsHandler) GetDynamicsByPeriod(w http.ResponseWriter, r *http.Re
 		interval = "1 day"
 	}
 
+	condition, scopeArgs := ownership.ScopeCondition("t", scope, 4)
+	source, sourceArgs, ok := h.transactions(w, r, 4+len(scopeArgs))
+	if !ok {
+		return
+	}
 	query := `
 		WITH date_series AS (
 			SELECT generate_series(
@@ -77,17 +130,18 @@ func (h *AnalyticsHandler) GetDynamicsByPeriod(w http.ResponseWriter, r *http.Re
 				END
 			), 0) as value
 		FROM date_series ds
-		LEFT JOIN transactions t ON DATE(t.date_time) = DATE(ds.date)
+		LEFT JOIN ` + source + ` t ON DATE(t.date_time) = DATE(ds.date) AND t.deleted_at IS NULL AND ` + condition + `
 		GROUP BY ds.date
 		ORDER BY ds.date
 	`
 
+	params := append(append([]interface{}{request.Date.From, request.Date.To, interval}, scopeArgs...), sourceArgs...)
 	h.logger.Info(r.Context(), "Executing dynamics query", map[string]interface{}{
 		"query":  query,
-		"params": []interface{}{request.Date.From, request.Date.To, interval},
+		"params": params,
 	})
 
-	rows, err := h.db.QueryContext(r.Context(), query, request.Date.From, request.Date.To, interval)
+	rows, err := h.db.QueryContext(r.Context(), query, params...)
 	if err != nil {
 		h.logger.Error(r.Context(), "error getting dynamics", map[string]interface{}{"error": err.Error()})
 		w.WriteHeader(http.StatusInternalServerError)
@@ -127,7 +181,16 @@ func (h *AnalyticsHandler) GetDynamicsByPeriod(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// GetCategoriesSummary возвращает суммы по категориям. По умолчанию категории не
+// группируются по дереву; rollup=true — суммы корневых категорий вместе с подкатегориями,
+// parent_id={id} — суммы непосредственных подкатегорий узла вместе с их потомками и
+// отдельной строкой транзакции самого узла.
 func (h *AnalyticsHandler) GetCategoriesSummary(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
 	transType := r.URL.Query().Get("trans_type")
 	if transType == "" {
 		w.WriteHeader(http.StatusBadRequest)
@@ -135,6 +198,18 @@ func (h *AnalyticsHandler) GetCategoriesSummary(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	var parentID int
+	if v := r.URL.Query().Get("parent_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "parent_id must be a positive integer"})
+			return
+		}
+		parentID = id
+	}
+	rollup := r.URL.Query().Get("rollup") == "true"
+
 	var request schemas.CategoriesSummaryRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -148,26 +223,92 @@ func (h *AnalyticsHandler) GetCategoriesSummary(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	query := `
-		SELECT 
-			COALESCE(c.name, 'Без категории') as category,
-			COALESCE(SUM(t.amount), 0) as value
-		FROM categories c
-		LEFT JOIN transactions t ON c.id = t.category_id 
+	// Условия для категорий и транзакций области используют один и тот же параметр $4.
+	transactionCondition, scopeArgs := ownership.ScopeCondition("t", scope, 4)
+	categoryCondition, _ := ownership.CategoryScopeCondition("c", scope, 4)
+	source, sourceArgs, ok := h.transactions(w, r, 4+len(scopeArgs))
+	if !ok {
+		return
+	}
+	params := append(append([]interface{}{transType, request.Date.From, request.Date.To}, scopeArgs...), sourceArgs...)
+
+	transactionJoin := `t.deleted_at IS NULL
 			AND t.trans_type = $1
 			AND t.date_time >= $2::timestamp with time zone
 			AND t.date_time <= $3::timestamp with time zone
-		WHERE c.type = $1 OR c.type IS NULL
+			AND ` + transactionCondition
+
+	var query string
+	if parentID == 0 && !rollup {
+		query = `
+		SELECT 
+			COALESCE(c.name, 'Без категории') as category,
+			COALESCE(SUM(t.amount), 0) as value,
+			0 as category_id,
+			false as has_children
+		FROM categories c
+		LEFT JOIN ` + source + ` t ON c.id = t.category_id 
+			AND ` + transactionJoin + `
+		WHERE (c.type = $1 OR c.type IS NULL) AND ` + categoryCondition + `
 		GROUP BY c.name
 		ORDER BY value DESC
 	`
+	} else {
+		// Группы — корневые категории (родитель не задан или не виден в области) либо
+		// подкатегории узла parent_id; recurse = false у строки самого узла, чтобы его
+		// подкатегории не попали в его же сумму второй раз.
+		anchor := `SELECT v.id, v.name, v.id, true FROM visible v
+				WHERE v.parent_id IS NULL OR v.parent_id NOT IN (SELECT id FROM visible)`
+		if parentID != 0 {
+			exists, err := h.categoryVisible(r, scope, parentID)
+			if err != nil {
+				h.logger.Error(r.Context(), "error checking category", map[string]interface{}{"error": err.Error()})
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+				return
+			}
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"error": "Category not found"})
+				return
+			}
+			params = append(params, parentID)
+			n := "$" + strconv.Itoa(len(params))
+			anchor = `SELECT v.id, v.name, v.id, true FROM visible v WHERE v.parent_id = ` + n + `
+				UNION ALL
+				SELECT v.id, v.name, v.id, false FROM visible v WHERE v.id = ` + n
+		}
+		query = `
+		WITH RECURSIVE visible AS (
+			SELECT c.id, c.name, c.parent_id
+			FROM categories c
+			WHERE (c.type = $1 OR c.type IS NULL) AND ` + categoryCondition + `
+		), tree (group_id, group_name, id, recurse) AS (
+			` + anchor + `
+			UNION ALL
+			SELECT tree.group_id, tree.group_name, v.id, true
+			FROM visible v
+			JOIN tree ON v.parent_id = tree.id AND tree.recurse
+		)
+		SELECT 
+			tree.group_name as category,
+			COALESCE(SUM(t.amount), 0) as value,
+			tree.group_id as category_id,
+			bool_or(tree.recurse) AND EXISTS (SELECT 1 FROM visible v WHERE v.parent_id = tree.group_id) as has_children
+		FROM tree
+		LEFT JOIN ` + source + ` t ON tree.id = t.category_id 
+			AND ` + transactionJoin + `
+		GROUP BY tree.group_id, tree.group_name
+		ORDER BY value DESC
+	`
+	}
 
 	h.logger.Info(r.Context(), "Executing categories summary query", map[string]interface{}{
 		"query":  query,
-		"params": []interface{}{transType, request.Date.From, request.Date.To},
+		"params": params,
 	})
 
-	rows, err := h.db.QueryContext(r.Context(), query, transType, request.Date.From, request.Date.To)
+	rows, err := h.db.QueryContext(r.Context(), query, params...)
 	if err != nil {
 		h.logger.Error(r.Context(), "error getting categories summary", map[string]interface{}{"error": err.Error()})
 		w.WriteHeader(http.StatusInternalServerError)
@@ -178,23 +319,186 @@ func (h *AnalyticsHandler) GetCategoriesSummary(w http.ResponseWriter, r *http.R
 
 	var response schemas.CategoriesSummaryResponse
 	for rows.Next() {
-		var item struct {
-			Category string  `db:"category"`
-			Value    float64 `db:"value"`
+		var item schemas.CategorySummaryItem
+		if err := rows.Scan(&item.Category, &item.Value, &item.CategoryID, &item.HasChildren); err != nil {
+			h.logger.Error(r.Context(), "error scanning row", map[string]interface{}{"error": err.Error()})
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+			return
+		}
+		response.Data = append(response.Data, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error(r.Context(), "error encoding response", map[string]interface{}{"error": err.Error()})
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+}
+
+func (h *AnalyticsHandler) GetBanksSummary(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	transType := r.URL.Query().Get("trans_type")
+
+	var request schemas.BanksSummaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Транзакции без БИК группируются по исходному тексту банка.
+	condition, scopeArgs := ownership.ScopeCondition("t", scope, 4)
+	source, sourceArgs, ok := h.transactions(w, r, 4+len(scopeArgs))
+	if !ok {
+		return
+	}
+	query := `
+		SELECT 
+			COALESCE(t.sender_bank_bic, '') as bic,
+			CASE WHEN t.sender_bank_bic IS NULL THEN COALESCE(t.sender_bank, '') ELSE '' END as bank,
+			COUNT(t.id) as count,
+			COALESCE(SUM(t.amount), 0) as amount
+		FROM ` + source + ` t
+		WHERE t.deleted_at IS NULL
+			AND ($1 = '' OR t.trans_type = $1)
+			AND t.date_time >= $2::timestamp with time zone
+			AND t.date_time <= $3::timestamp with time zone
+			AND ` + condition + `
+		GROUP BY 1, 2
+		ORDER BY amount DESC
+	`
+
+	params := append(append([]interface{}{transType, request.Date.From, request.Date.To}, scopeArgs...), sourceArgs...)
+	h.logger.Info(r.Context(), "Executing banks summary query", map[string]interface{}{
+		"query":  query,
+		"params": params,
+	})
+
+	rows, err := h.db.QueryContext(r.Context(), query, params...)
+	if err != nil {
+		h.logger.Error(r.Context(), "error getting banks summary", map[string]interface{}{"error": err.Error()})
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+	defer rows.Close()
+
+	response := []schemas.BankSummaryResponse{}
+	for rows.Next() {
+		var item schemas.BankSummaryResponse
+		if err := rows.Scan(&item.BIC, &item.Bank, &item.Count, &item.Amount); err != nil {
+			h.logger.Error(r.Context(), "error scanning row", map[string]interface{}{"error": err.Error()})
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+			return
+		}
+		if bank, ok := h.banks.GetByBIC(item.BIC); ok {
+			item.Bank = bank.Name
+		} else if item.Bank == "" {
+			item.Bank = item.BIC
 		}
-		if err := rows.Scan(&item.Category, &item.Value); err != nil {
+		response = append(response, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error(r.Context(), "error encoding response", map[string]interface{}{"error": err.Error()})
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+}
+
+func (h *AnalyticsHandler) GetTopCounterparties(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	transType := r.URL.Query().Get("trans_type")
+	limit, err := strconv.Atoi(utils.GetOrDefault(r.URL.Query(), "limit", "10"))
+	if err != nil || limit <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "limit must be a positive number"})
+		return
+	}
+
+	var request schemas.TopCounterpartiesRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	condition, scopeArgs := ownership.ScopeCondition("t", scope, 5)
+	source, sourceArgs, ok := h.transactions(w, r, 5+len(scopeArgs))
+	if !ok {
+		return
+	}
+	query := `
+		SELECT 
+			c.id as counterparty_id,
+			c.name,
+			COALESCE(c.inn, '') as inn,
+			COALESCE(c.phone, '') as phone,
+			COUNT(t.id) as count,
+			COALESCE(SUM(t.amount), 0) as amount
+		FROM ` + source + ` t
+		JOIN counterparties c ON c.id = t.counterparty_id
+		WHERE t.deleted_at IS NULL
+			AND ($1 = '' OR t.trans_type = $1)
+			AND t.date_time >= $2::timestamp with time zone
+			AND t.date_time <= $3::timestamp with time zone
+			AND ` + condition + `
+		GROUP BY c.id, c.name, c.inn, c.phone
+		ORDER BY amount DESC, count DESC
+		LIMIT $4
+	`
+
+	params := append(append([]interface{}{transType, request.Date.From, request.Date.To, limit}, scopeArgs...), sourceArgs...)
+	h.logger.Info(r.Context(), "Executing top counterparties query", map[string]interface{}{
+		"query":  query,
+		"params": params,
+	})
+
+	rows, err := h.db.QueryContext(r.Context(), query, params...)
+	if err != nil {
+		h.logger.Error(r.Context(), "error getting top counterparties", map[string]interface{}{"error": err.Error()})
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+	defer rows.Close()
+
+	response := []schemas.CounterpartySummaryResponse{}
+	for rows.Next() {
+		var item schemas.CounterpartySummaryResponse
+		if err := rows.Scan(&item.CounterpartyID, &item.Name, &item.INN, &item.Phone, &item.Count, &item.Amount); err != nil {
 			h.logger.Error(r.Context(), "error scanning row", map[string]interface{}{"error": err.Error()})
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
 			return
 		}
-		response.Data = append(response.Data, struct {
-			Category string  `json:"category"`
-			Value    float64 `json:"value"`
-		}{
-			Category: item.Category,
-			Value:    item.Value,
-		})
+		response = append(response, item)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"finance-backend/internal/delivery/http/mappers"
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/usecase/apikey"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHandler — персональные ключи API текущего пользователя.
+type APIKeyHandler struct {
+	apiKeyUseCase apikey.IAPIKeyUseCase
+	log           *logger.Logger
+	validate      *validator.Validate
+}
+
+func NewAPIKeyHandler(logger *logger.Logger, apiKeyUseCase apikey.IAPIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUseCase: apiKeyUseCase,
+		log:           logger,
+		validate:      validation.New(),
+	}
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	keys, err := h.apiKeyUseCase.ListAPIKeys(r.Context(), user.Login)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapAPIKeysToResponse(keys))
+}
+
+// CreateAPIKey отдает ключ один раз: сервер хранит только его хеш.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	var requestEntity schemas.CreateAPIKeySchema
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	key, err := h.apiKeyUseCase.CreateAPIKey(r.Context(), user, requestEntity.ToDomainEntity())
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusCreated, mappers.MapCreatedAPIKeyToResponse(key))
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := h.apiKeyUseCase.RevokeAPIKey(r.Context(), user.Login, id); err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
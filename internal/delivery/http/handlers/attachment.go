@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"finance-backend/internal/delivery/http/mappers"
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/usecase/attachment"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// multipartOverhead — запас на заголовки multipart сверх максимального размера файла.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentUseCase attachment.IAttachmentUseCase
+	log               *logger.Logger
+	validate          *validator.Validate
+	maxSize           int64
+}
+
+func NewAttachmentHandler(logger *logger.Logger, attachmentUseCase attachment.IAttachmentUseCase, maxSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentUseCase: attachmentUseCase,
+		log:               logger,
+		validate:          validation.New(),
+		maxSize:           maxSize,
+	}
+}
+
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	transactionID, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartOverhead)
+	if err := r.ParseMultipartForm(h.maxSize); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeUseCaseError(w, r, h.log, domain.ErrAttachmentTooLarge)
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid multipart form", "details": err.Error()})
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Field 'file' is required"})
+		return
+	}
+	defer file.Close()
+
+	item, err := h.attachmentUseCase.UploadAttachment(r.Context(), transactionID, user, header.Filename, file, header.Size)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, mappers.MapAttachmentToAttachmentResponse(item))
+}
+
+// CreateUploadURL выдает ссылку для загрузки файла напрямую в хранилище, минуя сервер.
+func (h *AttachmentHandler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
+	transactionID, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	var requestEntity schemas.CreateAttachmentUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	upload, err := h.attachmentUseCase.CreateUploadURL(r.Context(), transactionID, user, requestEntity.ContentType, requestEntity.Size)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapAttachmentUploadToResponse(upload))
+}
+
+// ConfirmUpload создает вложение после того, как клиент загрузил файл по выданной ссылке.
+func (h *AttachmentHandler) ConfirmUpload(w http.ResponseWriter, r *http.Request) {
+	transactionID, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	var requestEntity schemas.ConfirmAttachmentUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	item, err := h.attachmentUseCase.ConfirmUpload(r.Context(), transactionID, user, requestEntity.ObjectKey, requestEntity.FileName)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, mappers.MapAttachmentToAttachmentResponse(item))
+}
+
+func (h *AttachmentHandler) GetDownloadURL(w http.ResponseWriter, r *http.Request) {
+	transactionID, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["attachment_id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid attachment ID"})
+		return
+	}
+
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	presigned, err := h.attachmentUseCase.GetDownloadURL(r.Context(), transactionID, id, user)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapPresignedURLToResponse(presigned))
+}
+
+func (h *AttachmentHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	transactionID, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	items, err := h.attachmentUseCase.ListAttachments(r.Context(), transactionID, user)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapAttachmentsToResponse(items))
+}
+
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	transactionID, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["attachment_id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid attachment ID"})
+		return
+	}
+
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	item, body, err := h.attachmentUseCase.DownloadAttachment(r.Context(), transactionID, id, user)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", item.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(item.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": item.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, body); err != nil {
+		h.log.Error(r.Context(), "failed to stream attachment", map[string]interface{}{"error": err.Error(), "attachment_id": id})
+	}
+}
+
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	transactionID, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["attachment_id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid attachment ID"})
+		return
+	}
+
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	if err := h.attachmentUseCase.DeleteAttachment(r.Context(), transactionID, id, user); err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseTransactionID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid transaction ID"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"finance-backend/internal/delivery/http/mappers"
+	"finance-backend/internal/domain"
+	uc "finance-backend/internal/usecase/audit"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AuditHandler — просмотр журнала аудита. Маршруты доступны только с правом audit:read.
+type AuditHandler struct {
+	auditUseCase uc.IAuditUseCase
+	log          *logger.Logger
+}
+
+func NewAuditHandler(logger *logger.Logger, auditUseCase uc.IAuditUseCase) *AuditHandler {
+	return &AuditHandler{
+		auditUseCase: auditUseCase,
+		log:          logger,
+	}
+}
+
+func (h *AuditHandler) SearchAuditLog(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	limit, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "limit", "50"))
+	offset, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	filter := domain.AuditFilter{
+		Actor:      utils.GetOrNil(queryParams, "actor"),
+		Action:     utils.GetOrNil(queryParams, "action"),
+		EntityType: utils.GetOrNil(queryParams, "entityType"),
+		EntityID:   utils.GetOrNil(queryParams, "entityId"),
+		RequestID:  utils.GetOrNil(queryParams, "requestId"),
+	}
+	if orgID := queryParams.Get("organizationId"); orgID != "" {
+		value, err := strconv.ParseInt(orgID, 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid organizationId parameter"})
+			return
+		}
+		filter.OrgID = &value
+	}
+	var err error
+	if filter.From, err = parseTimeParam(queryParams, "from"); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid from parameter, RFC 3339 expected"})
+		return
+	}
+	if filter.To, err = parseTimeParam(queryParams, "to"); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid to parameter, RFC 3339 expected"})
+		return
+	}
+
+	entries, err := h.auditUseCase.SearchEntries(r.Context(), limit, offset, filter)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapPaginatedAuditEntriesToResponse(entries))
+}
+
+// parseTimeParam разбирает необязательный параметр запроса в формате RFC 3339.
+func parseTimeParam(query url.Values, key string) (*time.Time, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
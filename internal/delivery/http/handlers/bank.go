@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"finance-backend/internal/delivery/http/mappers"
+	"finance-backend/internal/gateways/bank_directory"
+	"finance-backend/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type BankHandler struct {
+	banks bank_directory.IBankDirectory
+}
+
+func NewBankHandler(banks bank_directory.IBankDirectory) *BankHandler {
+	return &BankHandler{
+		banks: banks,
+	}
+}
+
+func (h *BankHandler) SearchBanks(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	limit, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "limit", "20"))
+	search := queryParams.Get("search")
+
+	banks := h.banks.Search(search, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mappers.MapBanksToBanksResponse(banks))
+}
+
+func (h *BankHandler) GetBankByBIC(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	bank, ok := h.banks.GetByBIC(vars["bic"])
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Банк с таким БИК не найден"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mappers.MapBankToBankResponse(bank))
+}
@@ -2,193 +2,247 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"finance-backend/internal/delivery/http/mappers"
 	"finance-backend/internal/delivery/http/schemas"
 	"finance-backend/internal/domain"
 	"finance-backend/internal/usecase/category"
 	"finance-backend/pkg/logger"
 	"finance-backend/pkg/utils"
-	"fmt"
+	"finance-backend/pkg/validation"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
-
 	"github.com/gorilla/mux"
 )
 
+// CategoryHandler — управление категориями активной организации или личного учета.
+// Изменения требуют права categories:write.
 type CategoryHandler struct {
-	CategoryUseCase category.ICategoryUseCase
-	log             logger.Logger
+	categoryUseCase category.ICategoryUseCase
+	log             *logger.Logger
+	validate        *validator.Validate
 }
 
-func NewCategoryHandler(logger logger.Logger, categoryUseCase category.ICategoryUseCase) *CategoryHandler {
+func NewCategoryHandler(logger *logger.Logger, categoryUseCase category.ICategoryUseCase) *CategoryHandler {
 	return &CategoryHandler{
-		CategoryUseCase: categoryUseCase,
+		categoryUseCase: categoryUseCase,
 		log:             logger,
+		validate:        validation.New(),
 	}
 }
 
-func (h *CategoryHandler) GetAdminCategoryById(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+// SearchCategories — постраничный поиск по названию; archived=true — вместе с архивными.
+func (h *CategoryHandler) SearchCategories(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
 
-	category, err := h.CategoryUseCase.GetCategoryByID(r.Context(), id)
+	queryParams := r.URL.Query()
+	limit, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "limit", "20"))
+	offset, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "offset", "0"))
+	search := utils.GetOrNil(queryParams, "search")
+	withArchived := queryParams.Get("archived") == "true"
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
 
+	categories, err := h.categoryUseCase.SearchCategoriesPaginated(r.Context(), scope, limit, offset, search, withArchived)
 	if err != nil {
-		var de *domain.DomainError
-		if errors.As(err, &de) {
-			w.WriteHeader(http.StatusBadRequest)
-
-			json.NewEncoder(w).Encode(map[string]string{"error": de.Message})
-			return
-		}
+		writeUseCaseError(w, r, h.log, err)
+		return
 	}
-	json.NewEncoder(w).Encode(mappers.MapCategoryToCategoryAdminResponse(category))
-}
 
-func (h *CategoryHandler) GetCommonCategoryById(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+	writeJSON(w, http.StatusOK, mappers.MapPaginatedCategoriesToResponse(categories))
+}
 
-	category, err := h.CategoryUseCase.GetCategoryByID(r.Context(), id)
+func (h *CategoryHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.categoryID(w, r)
+	if !ok {
+		return
+	}
 
+	category, err := h.categoryUseCase.GetCategoryByID(r.Context(), scope, id)
 	if err != nil {
-		var de *domain.DomainError
-		if errors.As(err, &de) {
-			w.WriteHeader(http.StatusBadRequest)
-
-			json.NewEncoder(w).Encode(map[string]string{"error": de.Message})
-			return
-		}
+		writeUseCaseError(w, r, h.log, err)
+		return
 	}
-	json.NewEncoder(w).Encode(mappers.MapCategoryToCategoryACommonResponse(category))
-}
 
-func (h *CategoryHandler) SearchCategoriesPaginated(w http.ResponseWriter, r *http.Request) {
-	queryParams := r.URL.Query()
-	limit, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "limit", "10"))
-	offset, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "limit", "0"))
-	search := utils.GetOrNil(queryParams, "search")
+	writeJSON(w, http.StatusOK, mappers.MapCategoryToCategoryResponse(category))
+}
 
-	categories, _ := h.CategoryUseCase.SearchCategoriesPaginated(r.Context(), limit, offset, search)
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
 
-	json.NewEncoder(w).Encode(mappers.MapPaginatedCategoriesToAdminResponse(categories))
-}
+	var requestEntity schemas.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+	requestEntity.Name = strings.TrimSpace(requestEntity.Name)
 
-func (h *CategoryHandler) SearchCategoriesFlat(w http.ResponseWriter, r *http.Request) {
-	queryParams := r.URL.Query()
-	search := utils.GetOrNil(queryParams, "search")
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
 
-	categories, _ := h.CategoryUseCase.SearchCategoriesFlat(r.Context(), search)
+	category, err := h.categoryUseCase.CreateCategory(r.Context(), scope, requestEntity.ToDomainEntity())
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
 
-	json.NewEncoder(w).Encode(mappers.MapCategoriesToCategoriesRepsonse(categories))
+	writeJSON(w, http.StatusCreated, mappers.MapCategoryToCategoryResponse(category))
 }
 
-func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
-	if !utils.CheckIsAdmin(r.Context()) {
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{"msg": "admin role required"})
+func (h *CategoryHandler) UpdateCategoryName(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.categoryID(w, r)
+	if !ok {
 		return
 	}
 
-	var requestEntity schemas.UpdateOrCreateCategoryRequest
+	var requestEntity schemas.UpdateCategoryRequest
 	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
 		return
 	}
+	requestEntity.Name = strings.TrimSpace(requestEntity.Name)
 
-	validate := validator.New()
-	if err := validate.Struct(requestEntity); err != nil {
-		errorMap := make(map[string]string)
-		for _, verr := range err.(validator.ValidationErrors) {
-			errorMap[strings.ToLower(verr.Field())] = fmt.Sprintf("Field validation for '%s' failed on the '%s' tag", strings.ToLower(verr.Field()), verr.Tag())
-		}
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorMap)
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
 		return
 	}
 
-	category, err := h.CategoryUseCase.CreateCategory(r.Context(), requestEntity.Name)
+	category, err := h.categoryUseCase.UpdateCategoryName(r.Context(), scope, id, requestEntity.Name)
 	if err != nil {
-		var de *domain.DomainError
-		if errors.As(err, &de) {
-			w.WriteHeader(http.StatusBadRequest)
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
 
-			json.NewEncoder(w).Encode(map[string]string{"error": de.Message})
-			return
-		}
+	writeJSON(w, http.StatusOK, mappers.MapCategoryToCategoryResponse(category))
+}
+
+func (h *CategoryHandler) ArchiveCategory(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.categoryID(w, r)
+	if !ok {
+		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(mappers.MapCategoryToCategoryAdminResponse(category))
+	category, err := h.categoryUseCase.ArchiveCategory(r.Context(), scope, id)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapCategoryToCategoryResponse(category))
 }
 
-func (h *CategoryHandler) UpdateCategoryName(w http.ResponseWriter, r *http.Request) {
-	if !utils.CheckIsAdmin(r.Context()) {
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{"msg": "admin role required"})
+func (h *CategoryHandler) UnarchiveCategory(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
 		return
 	}
+	id, ok := h.categoryID(w, r)
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryUseCase.UnarchiveCategory(r.Context(), scope, id)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapCategoryToCategoryResponse(category))
+}
 
-	vars := mux.Vars(r)
-	id, _ := strconv.ParseInt(vars["id"], 10, 64)
-	var requestEntity schemas.UpdateOrCreateCategoryRequest
+func (h *CategoryHandler) MergeCategory(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.categoryID(w, r)
+	if !ok {
+		return
+	}
 
+	var requestEntity schemas.MergeCategoryRequest
 	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
 		return
 	}
 
-	validate := validator.New()
-	if err := validate.Struct(requestEntity); err != nil {
-		errorMap := make(map[string]string)
-		for _, verr := range err.(validator.ValidationErrors) {
-			errorMap[strings.ToLower(verr.Field())] = fmt.Sprintf("Field validation for '%s' failed on the '%s' tag", strings.ToLower(verr.Field()), verr.Tag())
-		}
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorMap)
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
 		return
 	}
 
-	category, err := h.CategoryUseCase.UpdateCategoryName(r.Context(), id, requestEntity.Name)
+	target, moved, err := h.categoryUseCase.MergeCategory(r.Context(), scope, id, requestEntity.TargetID)
 	if err != nil {
-		var de *domain.DomainError
-		if errors.As(err, &de) {
-			w.WriteHeader(http.StatusBadRequest)
-
-			json.NewEncoder(w).Encode(map[string]string{"error": de.Message})
-			return
-		}
+		writeUseCaseError(w, r, h.log, err)
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(mappers.MapCategoryToCategoryAdminResponse(category))
-
+	writeJSON(w, http.StatusOK, schemas.MergeCategoryResponse{
+		Category:          mappers.MapCategoryToCategoryResponse(target),
+		TransactionsMoved: moved,
+	})
 }
 
 func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
-	if !utils.CheckIsAdmin(r.Context()) {
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{"msg": "admin role required"})
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.categoryID(w, r)
+	if !ok {
 		return
 	}
 
-	vars := mux.Vars(r)
-	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+	if err := h.categoryUseCase.DeleteCategory(r.Context(), scope, id); err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	err := h.CategoryUseCase.DeleteCategory(r.Context(), id)
+// scope возвращает область данных пользователя запроса; при ошибке ответ уже записан.
+func (h *CategoryHandler) scope(w http.ResponseWriter, r *http.Request) (domain.DataScope, bool) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return domain.DataScope{}, false
+	}
+	return domain.ScopeOf(user), true
+}
+
+// categoryID разбирает ID категории из пути; при ошибке ответ уже записан.
+func (h *CategoryHandler) categoryID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
 	if err != nil {
-		var de *domain.DomainError
-		if errors.As(err, &de) {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": de.Message})
-			return
-		}
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid category ID"})
+		return 0, false
 	}
-	w.WriteHeader(http.StatusNoContent)
+	return id, true
 }
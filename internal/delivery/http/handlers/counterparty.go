@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"finance-backend/internal/delivery/http/mappers"
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/usecase/counterparty"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// CounterpartyHandler — справочник контрагентов активной организации или личного учета.
+type CounterpartyHandler struct {
+	counterpartyUseCase counterparty.ICounterpartyUseCase
+	log                 *logger.Logger
+	validate            *validator.Validate
+}
+
+func NewCounterpartyHandler(logger *logger.Logger, counterpartyUseCase counterparty.ICounterpartyUseCase) *CounterpartyHandler {
+	return &CounterpartyHandler{
+		counterpartyUseCase: counterpartyUseCase,
+		log:                 logger,
+		validate:            validation.New(),
+	}
+}
+
+func (h *CounterpartyHandler) SearchCounterparties(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	queryParams := r.URL.Query()
+	limit, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "limit", "20"))
+	offset, _ := strconv.Atoi(utils.GetOrDefault(queryParams, "offset", "0"))
+	search := utils.GetOrNil(queryParams, "search")
+	if limit <= 0 {
+		limit = 20
+	}
+
+	counterparties, err := h.counterpartyUseCase.SearchCounterpartiesPaginated(r.Context(), scope, limit, offset, search)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapPaginatedCounterpartiesToResponse(counterparties))
+}
+
+func (h *CounterpartyHandler) GetCounterpartyByID(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid counterparty ID"})
+		return
+	}
+
+	counterparty, err := h.counterpartyUseCase.GetCounterpartyByID(r.Context(), scope, id)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapCounterpartyToCounterpartyResponse(counterparty))
+}
+
+func (h *CounterpartyHandler) CreateCounterparty(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	var requestEntity schemas.CreateOrUpdateCounterpartyRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	counterparty, err := h.counterpartyUseCase.CreateCounterparty(r.Context(), scope, requestEntity.ToDomainEntity())
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, mappers.MapCounterpartyToCounterpartyResponse(counterparty))
+}
+
+func (h *CounterpartyHandler) UpdateCounterparty(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid counterparty ID"})
+		return
+	}
+
+	var requestEntity schemas.CreateOrUpdateCounterpartyRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	counterparty, err := h.counterpartyUseCase.UpdateCounterparty(r.Context(), scope, id, requestEntity.ToDomainEntity())
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapCounterpartyToCounterpartyResponse(counterparty))
+}
+
+func (h *CounterpartyHandler) DeleteCounterparty(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid counterparty ID"})
+		return
+	}
+
+	if err := h.counterpartyUseCase.DeleteCounterparty(r.Context(), scope, id); err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scope возвращает область данных пользователя запроса; при ошибке ответ уже записан.
+func (h *CounterpartyHandler) scope(w http.ResponseWriter, r *http.Request) (domain.DataScope, bool) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return domain.DataScope{}, false
+	}
+	return domain.ScopeOf(user), true
+}
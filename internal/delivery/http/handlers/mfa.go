@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
+	"net/http"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const totpQRCodeSize = 256
+
+// CompleteMFALogin — второй шаг входа: токен из ответа /login и код из приложения
+// или код восстановления. Неверный код и истекший токен — 401.
+func (uh *UserHandler) CompleteMFALogin(w http.ResponseWriter, r *http.Request) {
+	var requestEntity schemas.MFALoginSchema
+	if !decodeUserRequest(w, r, &requestEntity) {
+		return
+	}
+
+	token, err := uh.userUseCase.CompleteMFALogin(r.Context(), requestEntity.MFAToken, requestEntity.Code)
+	if err != nil {
+		uh.writeMFAError(w, err, "Error completing 2fa login")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewTokenPairSchema(token))
+}
+
+func (uh *UserHandler) GetMFAStatus(w http.ResponseWriter, r *http.Request) {
+	user, ok := uh.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := uh.userUseCase.GetMFAStatus(r.Context(), user.Login)
+	if err != nil {
+		uh.writeMFAError(w, err, "Error getting 2fa status")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, schemas.MFAStatusSchema{Enabled: state.Enabled(), RecoveryCodesLeft: state.RecoveryCodesLeft})
+}
+
+// EnrollTOTP выдает секрет и QR-код для приложения-аутентификатора.
+func (uh *UserHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := uh.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := uh.userUseCase.EnrollTOTP(r.Context(), user.Login)
+	if err != nil {
+		uh.writeMFAError(w, err, "Error enrolling totp")
+		return
+	}
+
+	png, err := qrcode.Encode(enrollment.OTPAuthURI, qrcode.Medium, totpQRCodeSize)
+	if err != nil {
+		uh.writeMFAError(w, err, "Error rendering totp qr code")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, schemas.TOTPEnrollmentSchema{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.OTPAuthURI,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// ConfirmTOTP включает 2FA. Коды восстановления показываются только в этом ответе.
+func (uh *UserHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := uh.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var requestEntity schemas.MFACodeSchema
+	if !decodeUserRequest(w, r, &requestEntity) {
+		return
+	}
+
+	codes, err := uh.userUseCase.ConfirmTOTP(r.Context(), user.Login, requestEntity.Code)
+	if err != nil {
+		uh.writeMFAError(w, err, "Error confirming totp")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, schemas.RecoveryCodesSchema{RecoveryCodes: codes})
+}
+
+func (uh *UserHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user, ok := uh.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var requestEntity schemas.MFACodeSchema
+	if !decodeUserRequest(w, r, &requestEntity) {
+		return
+	}
+
+	codes, err := uh.userUseCase.RegenerateRecoveryCodes(r.Context(), user.Login, requestEntity.Code)
+	if err != nil {
+		uh.writeMFAError(w, err, "Error regenerating recovery codes")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, schemas.RecoveryCodesSchema{RecoveryCodes: codes})
+}
+
+func (uh *UserHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := uh.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var requestEntity schemas.DisableMFASchema
+	if !decodeUserRequest(w, r, &requestEntity) {
+		return
+	}
+
+	if err := uh.userUseCase.DisableTOTP(r.Context(), user.Login, requestEntity.Password, requestEntity.Code); err != nil {
+		uh.writeMFAError(w, err, "Error disabling totp")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (uh *UserHandler) requireUser(w http.ResponseWriter, r *http.Request) (domain.User, bool) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+	return user, ok
+}
+
+// decodeUserRequest разбирает и валидирует тело запроса; при ошибке ответ уже записан.
+func decodeUserRequest(w http.ResponseWriter, r *http.Request, requestEntity interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return false
+	}
+	if err := validation.New().Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return false
+	}
+	return true
+}
+
+func (uh *UserHandler) writeMFAError(w http.ResponseWriter, err error, logMessage string) {
+	if writeTooManyAttempts(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrMFAChallengeInvalid), errors.Is(err, domain.ErrMFACodeInvalid):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.(*domain.DomainError).Message})
+		return
+	case errors.Is(err, domain.ErrUserBlocked):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": domain.ErrUserBlocked.Message})
+		return
+	}
+
+	var de *domain.DomainError
+	if errors.As(err, &de) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": de.Message})
+		return
+	}
+	uh.logger.Printf("%s: %v", logMessage, err)
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+}
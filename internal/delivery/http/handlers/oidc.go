@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"net/http"
+)
+
+// StartOIDCLogin возвращает адрес страницы входа внешнего провайдера.
+// Если вход через провайдера не настроен — 404.
+func (uh *UserHandler) StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authorization, err := uh.userUseCase.StartOIDCLogin(r.Context())
+	if err != nil {
+		uh.writeOIDCError(w, err, "Error starting oidc login")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, schemas.OIDCAuthorizationSchema{
+		AuthorizationURL: authorization.URL,
+		State:            authorization.State,
+		ExpiresAt:        authorization.ExpiresAt,
+	})
+}
+
+// CompleteOIDCLogin обменивает code и state, полученные от провайдера, на пару токенов.
+func (uh *UserHandler) CompleteOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	var requestEntity schemas.OIDCCallbackSchema
+	if !decodeUserRequest(w, r, &requestEntity) {
+		return
+	}
+
+	token, err := uh.userUseCase.CompleteOIDCLogin(r.Context(), requestEntity.Code, requestEntity.State)
+	if err != nil {
+		uh.writeOIDCError(w, err, "Error completing oidc login")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, schemas.NewTokenPairSchema(token))
+}
+
+func (uh *UserHandler) writeOIDCError(w http.ResponseWriter, err error, logMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrOIDCDisabled):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": domain.ErrOIDCDisabled.Message})
+		return
+	case errors.Is(err, domain.ErrOIDCUserNotProvisioned), errors.Is(err, domain.ErrUserBlocked):
+		var de *domain.DomainError
+		errors.As(err, &de)
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": de.Message})
+		return
+	case errors.Is(err, domain.ErrOIDCLoginFailed):
+		uh.logger.Printf("%s: %v", logMessage, err)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": domain.ErrOIDCLoginFailed.Message})
+		return
+	case errors.Is(err, domain.ErrOIDCStateInvalid):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": domain.ErrOIDCStateInvalid.Message})
+		return
+	}
+
+	var de *domain.DomainError
+	if errors.As(err, &de) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": de.Message})
+		return
+	}
+	uh.logger.Printf("%s: %v", logMessage, err)
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+}
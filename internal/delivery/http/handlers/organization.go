@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"finance-backend/internal/delivery/http/mappers"
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/usecase/organization"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// OrganizationHandler — организации текущего пользователя, их участники и приглашения.
+type OrganizationHandler struct {
+	orgUseCase organization.IOrganizationUseCase
+	log        *logger.Logger
+	validate   *validator.Validate
+}
+
+func NewOrganizationHandler(logger *logger.Logger, orgUseCase organization.IOrganizationUseCase) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgUseCase: orgUseCase,
+		log:        logger,
+		validate:   validation.New(),
+	}
+}
+
+func (h *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	orgs, err := h.orgUseCase.ListOrganizations(r.Context(), user)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapOrganizationsToResponse(orgs))
+}
+
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	var requestEntity schemas.OrganizationSchema
+	if !h.decode(w, r, &requestEntity) {
+		return
+	}
+
+	org, err := h.orgUseCase.CreateOrganization(r.Context(), user, requestEntity.ToDomainEntity())
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, mappers.MapOrganizationToResponse(org))
+}
+
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	orgID, ok := orgIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	org, err := h.orgUseCase.GetOrganization(r.Context(), user, orgID)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapOrganizationToResponse(org))
+}
+
+func (h *OrganizationHandler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	orgID, ok := orgIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var requestEntity schemas.OrganizationSchema
+	if !h.decode(w, r, &requestEntity) {
+		return
+	}
+
+	org, err := h.orgUseCase.UpdateOrganization(r.Context(), user, orgID, requestEntity.ToDomainEntity())
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapOrganizationToResponse(org))
+}
+
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	orgID, ok := orgIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.orgUseCase.ListMembers(r.Context(), user, orgID)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapOrgMembersToResponse(members))
+}
+
+func (h *OrganizationHandler) ChangeMemberRole(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	orgID, ok := orgIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var requestEntity schemas.OrgMemberRoleSchema
+	if !h.decode(w, r, &requestEntity) {
+		return
+	}
+
+	login := mux.Vars(r)["login"]
+	if err := h.orgUseCase.ChangeMemberRole(r.Context(), user, orgID, login, domain.OrgRole(requestEntity.Role)); err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	orgID, ok := orgIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.orgUseCase.RemoveMember(r.Context(), user, orgID, mux.Vars(r)["login"]); err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	orgID, ok := orgIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.orgUseCase.ListInvitations(r.Context(), user, orgID)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapOrgInvitationsToResponse(invitations))
+}
+
+func (h *OrganizationHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	orgID, ok := orgIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var requestEntity schemas.OrgInvitationSchema
+	if !h.decode(w, r, &requestEntity) {
+		return
+	}
+
+	invitation, err := h.orgUseCase.InviteMember(r.Context(), user, orgID, requestEntity.ToDomainEntity())
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, mappers.MapOrgInvitationToResponse(invitation))
+}
+
+func (h *OrganizationHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	orgID, ok := orgIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["invitationId"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid invitation ID"})
+		return
+	}
+
+	if err := h.orgUseCase.RevokeInvitation(r.Context(), user, orgID, id); err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcceptInvitation добавляет текущего пользователя в организацию. Чтобы работать с ее
+// данными, нужно перейти в нее через POST /organizations/switch.
+func (h *OrganizationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	var requestEntity schemas.AcceptOrgInvitationSchema
+	if !h.decode(w, r, &requestEntity) {
+		return
+	}
+
+	org, err := h.orgUseCase.AcceptInvitation(r.Context(), user, requestEntity.Token)
+	if errors.Is(err, domain.ErrOrgInvitationEmailMismatch) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": domain.ErrOrgInvitationEmailMismatch.Message, "code": domain.ErrOrgInvitationEmailMismatch.Code})
+		return
+	}
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapOrganizationToResponse(org))
+}
+
+func (h *OrganizationHandler) decode(w http.ResponseWriter, r *http.Request, requestEntity interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(requestEntity); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return false
+	}
+	if err := h.validate.Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return false
+	}
+	return true
+}
+
+func orgIDFromPath(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid organization ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// SwitchOrganization переводит сессию в организацию или обратно в личный учет
+// и выдает новую пару токенов; прежняя сессия закрывается.
+func (uh *UserHandler) SwitchOrganization(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.GetTokenClaimsFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var requestEntity schemas.SwitchOrganizationSchema
+	if !decodeUserRequest(w, r, &requestEntity) {
+		return
+	}
+
+	token, err := uh.userUseCase.SwitchOrganization(r.Context(), claims, requestEntity.RefreshToken, requestEntity.OrganizationID)
+	if err != nil {
+		var de *domain.DomainError
+		if errors.As(err, &de) {
+			status := http.StatusBadRequest
+			if de == domain.ErrOrganizationNotFound {
+				status = http.StatusNotFound
+			}
+			writeJSON(w, status, map[string]string{"error": de.Message, "code": de.Code})
+			return
+		}
+		uh.logger.Printf("Error switching organization: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, schemas.NewTokenPairSchema(token))
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"finance-backend/internal/delivery/http/mappers"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/usecase/period"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// PeriodHandler — учетные периоды активной организации или личного учета. Закрывать
+// и открывать периоды можно с правом periods:manage.
+type PeriodHandler struct {
+	periodUseCase period.IPeriodUseCase
+	log           *logger.Logger
+}
+
+func NewPeriodHandler(logger *logger.Logger, periodUseCase period.IPeriodUseCase) *PeriodHandler {
+	return &PeriodHandler{
+		periodUseCase: periodUseCase,
+		log:           logger,
+	}
+}
+
+func (h *PeriodHandler) ListPeriods(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+
+	periods, err := h.periodUseCase.ListPeriods(r.Context(), domain.ScopeOf(user))
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapAccountingPeriodsToResponse(periods))
+}
+
+func (h *PeriodHandler) GetPeriod(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	month, ok := h.month(w, r)
+	if !ok {
+		return
+	}
+
+	period, err := h.periodUseCase.GetPeriod(r.Context(), domain.ScopeOf(user), month)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapAccountingPeriodToResponse(period))
+}
+
+func (h *PeriodHandler) ClosePeriod(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	month, ok := h.month(w, r)
+	if !ok {
+		return
+	}
+
+	period, err := h.periodUseCase.ClosePeriod(r.Context(), user, month)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapAccountingPeriodToResponse(period))
+}
+
+func (h *PeriodHandler) ReopenPeriod(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "no user in request"})
+		return
+	}
+	month, ok := h.month(w, r)
+	if !ok {
+		return
+	}
+
+	period, err := h.periodUseCase.ReopenPeriod(r.Context(), user, month)
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mappers.MapAccountingPeriodToResponse(period))
+}
+
+// month разбирает период ГГГГ-ММ из пути; при ошибке ответ уже записан.
+func (h *PeriodHandler) month(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	month, err := domain.ParsePeriodMonth(mux.Vars(r)["month"])
+	if err != nil {
+		writeUseCaseError(w, r, h.log, err)
+		return time.Time{}, false
+	}
+	return month, true
+}
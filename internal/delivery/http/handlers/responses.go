@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+// writeValidationErrors отдает ошибки валидации в том же формате, что и регистрация пользователя.
+func writeValidationErrors(w http.ResponseWriter, err error) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	errorMap := make(map[string]string)
+	for _, verr := range validationErrors {
+		errorMap[strings.ToLower(verr.Field())] = fmt.Sprintf(
+			"Field validation for '%s' failed on the '%s' tag", strings.ToLower(verr.Field()), verr.Tag(),
+		)
+	}
+	writeJSON(w, http.StatusBadRequest, errorMap)
+}
+
+// writeUseCaseError превращает доменные ошибки в 4xx, остальные логирует и отдает как 500.
+func writeUseCaseError(w http.ResponseWriter, r *http.Request, log *logger.Logger, err error) {
+	var de *domain.DomainError
+	if errors.As(err, &de) {
+		status := http.StatusBadRequest
+		switch {
+		case strings.HasSuffix(de.Code, "_NOT_FOUND"):
+			status = http.StatusNotFound
+		case de == domain.ErrForbidden, de == domain.ErrUserBlocked:
+			status = http.StatusForbidden
+		case de == domain.ErrAttachmentTooLarge:
+			status = http.StatusRequestEntityTooLarge
+		case de == domain.ErrTooManyAttempts:
+			status = http.StatusTooManyRequests
+		case de == domain.ErrPeriodClosed, de == domain.ErrPeriodAlreadyClosed, de == domain.ErrPeriodNotClosed,
+			de == domain.ErrCategoryInUse:
+			status = http.StatusConflict
+		}
+		writeJSON(w, status, map[string]string{"error": de.Message, "code": de.Code})
+		return
+	}
+
+	log.Error(r.Context(), "unhandled use case error", map[string]interface{}{"error": err.Error(), "path": r.URL.Path})
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+}
+
+// writeTooManyAttempts отвечает 429 с заголовком Retry-After, если попытку отклонил
+// ограничитель входа; false — ошибка другая и ответ не записан.
+func writeTooManyAttempts(w http.ResponseWriter, err error) bool {
+	var tooMany *domain.TooManyAttemptsError
+	if !errors.As(err, &tooMany) {
+		return false
+	}
+
+	seconds := int(math.Ceil(tooMany.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+		"error":      domain.ErrTooManyAttempts.Message,
+		"retryAfter": seconds,
+	})
+	return true
+}
@@ -2,10 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
 	"finance-backend/internal/domain/transaction"
+	"finance-backend/pkg/paymentqr"
+	"finance-backend/pkg/qrdecode"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
 	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
 	"log"
+	"mime"
 	"net/http"
 	"strconv"
 
@@ -13,6 +23,15 @@ import (
 	"github.com/gorilla/mux"
 )
 
+const (
+	defaultPaymentQRSize = 256
+	minPaymentQRSize     = 64
+	maxPaymentQRSize     = 1024
+)
+
+// maxReceiptImageSize — предельный размер фотографии чека, загружаемой для распознавания QR-кода.
+const maxReceiptImageSize = 10 << 20
+
 type TransactionHandler struct {
 	validate     *validator.Validate
 	transService transaction.Service
@@ -20,12 +39,27 @@ type TransactionHandler struct {
 
 func NewTransactionHandler(transService transaction.Service) *TransactionHandler {
 	return &TransactionHandler{
-		validate:     validator.New(),
+		validate:     validation.New(),
 		transService: transService,
 	}
 }
 
+// scope возвращает область данных запроса: активную организацию сессии или личный учет.
+func (h *TransactionHandler) scope(w http.ResponseWriter, r *http.Request) (domain.DataScope, bool) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "No user in request", http.StatusUnauthorized)
+		return domain.DataScope{}, false
+	}
+	return domain.ScopeOf(user), true
+}
+
 func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
 	var filter schemas.TransactionFilter
 	if r.Method == "POST" {
 		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
@@ -34,8 +68,18 @@ func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	// as_of в строке запроса задает момент, на который показываются транзакции
+	asOf, err := parseTimeParam(r.URL.Query(), "as_of")
+	if err != nil {
+		http.Error(w, "Invalid as_of, expected RFC3339", http.StatusBadRequest)
+		return
+	}
+	if asOf != nil {
+		filter.AsOf = asOf
+	}
+
 	// Получаем транзакции из базы данных
-	transactions, err := h.transService.GetTransactions(r.Context(), filter)
+	transactions, err := h.transService.GetTransactions(r.Context(), scope, filter)
 	if err != nil {
 		log.Printf("Error getting transactions: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -46,8 +90,40 @@ func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(transactions)
 }
 
+func (h *TransactionHandler) GetTransactionHistory(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.transService.GetTransactionHistory(r.Context(), scope, id)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting transaction history: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
 func (h *TransactionHandler) GetPreparedTransactions(w http.ResponseWriter, r *http.Request) {
-	transactions, err := h.transService.GetPreparedTransactions(r.Context())
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.transService.GetPreparedTransactions(r.Context(), scope)
 	if err != nil {
 		log.Printf("Error getting prepared transactions: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -59,7 +135,12 @@ func (h *TransactionHandler) GetPreparedTransactions(w http.ResponseWriter, r *h
 }
 
 func (h *TransactionHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
-	categories, err := h.transService.GetCategories(r.Context())
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	categories, err := h.transService.GetCategories(r.Context(), scope)
 	if err != nil {
 		log.Printf("Error getting categories: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -70,6 +151,84 @@ func (h *TransactionHandler) GetCategories(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(categories)
 }
 
+// GetCategoryTree возвращает дерево категорий; с {id} в пути — поддерево этой категории.
+func (h *TransactionHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	var rootID int64
+	if v, found := mux.Vars(r)["id"]; found {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid category ID", http.StatusBadRequest)
+			return
+		}
+		rootID = id
+	}
+
+	tree, err := h.transService.GetCategoryTree(r.Context(), scope, rootID)
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting category tree: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+func (h *TransactionHandler) SetCategoryParent(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	var req schemas.CategoryParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	var parentID int64
+	if req.ParentID != nil {
+		parentID = int64(*req.ParentID)
+	}
+
+	category, err := h.transService.SetCategoryParent(r.Context(), scope, id, parentID)
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrForbidden):
+		http.Error(w, "Shared categories cannot be changed", http.StatusForbidden)
+		return
+	case errors.Is(err, domain.ErrCategoryParentInvalid):
+		http.Error(w, domain.ErrCategoryParentInvalid.Message, http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrCategoryCycle):
+		http.Error(w, domain.ErrCategoryCycle.Message, http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Error setting category parent: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
 func (h *TransactionHandler) GetTransactionStatuses(w http.ResponseWriter, r *http.Request) {
 	statuses, err := h.transService.GetTransactionStatuses(r.Context())
 	if err != nil {
@@ -83,6 +242,12 @@ func (h *TransactionHandler) GetTransactionStatuses(w http.ResponseWriter, r *ht
 }
 
 func (h *TransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	user, ok := utils.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "No user in request", http.StatusUnauthorized)
+		return
+	}
+
 	vars := mux.Vars(r)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
@@ -90,7 +255,15 @@ func (h *TransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	err = h.transService.DeleteTransaction(r.Context(), id)
+	err = h.transService.DeleteTransaction(r.Context(), domain.ScopeOf(user), id, user.Login)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrPeriodClosed) {
+		http.Error(w, domain.ErrPeriodClosed.Message, http.StatusConflict)
+		return
+	}
 	if err != nil {
 		log.Printf("Error deleting transaction: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -99,11 +272,64 @@ func (h *TransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Re
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
-		"message": fmt.Sprintf("Transaction %d deleted successfully", id),
+		"message": fmt.Sprintf("Transaction %d moved to trash", id),
 	})
 }
 
+func (h *TransactionHandler) GetDeletedTransactions(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.transService.GetDeletedTransactions(r.Context(), scope)
+	if err != nil {
+		log.Printf("Error getting deleted transactions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transactions)
+}
+
+func (h *TransactionHandler) RestoreTransaction(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	restored, err := h.transService.RestoreTransaction(r.Context(), scope, id)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		http.Error(w, "Transaction not found in trash", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrPeriodClosed) {
+		http.Error(w, domain.ErrPeriodClosed.Message, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error restoring transaction: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(restored)
+}
+
 func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
 	var transaction schemas.Transaction
 	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -116,8 +342,17 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	createdTransaction, err := h.transService.CreateTransaction(r.Context(), transaction)
+	createdTransaction, err := h.transService.CreateTransaction(r.Context(), scope, transaction)
 	if err != nil {
+		var de *domain.DomainError
+		if errors.As(err, &de) {
+			status := http.StatusBadRequest
+			if de == domain.ErrPeriodClosed {
+				status = http.StatusConflict
+			}
+			http.Error(w, de.Message, status)
+			return
+		}
 		log.Printf("Error creating transaction: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -129,6 +364,11 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 }
 
 func (h *TransactionHandler) CreatePreparedTransaction(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
 	var transaction schemas.PreparedTransaction
 	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -141,8 +381,13 @@ func (h *TransactionHandler) CreatePreparedTransaction(w http.ResponseWriter, r
 		return
 	}
 
-	createdTransaction, err := h.transService.CreatePreparedTransaction(r.Context(), transaction)
+	createdTransaction, err := h.transService.CreatePreparedTransaction(r.Context(), scope, transaction)
 	if err != nil {
+		var de *domain.DomainError
+		if errors.As(err, &de) {
+			http.Error(w, de.Message, http.StatusBadRequest)
+			return
+		}
 		log.Printf("Error creating prepared transaction: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -152,3 +397,154 @@ func (h *TransactionHandler) CreatePreparedTransaction(w http.ResponseWriter, r
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(createdTransaction)
 }
+
+// ImportReceipt создает транзакцию по кассовому чеку. Принимает JSON со строкой из QR-кода
+// либо multipart/form-data с изображением чека в поле image и теми же полями формы.
+func (h *TransactionHandler) ImportReceipt(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	var receipt schemas.ReceiptImport
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		var ok bool
+		if receipt, ok = h.readReceiptForm(w, r); !ok {
+			return
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&receipt); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(receipt); err != nil {
+		http.Error(w, "Validation failed", http.StatusBadRequest)
+		return
+	}
+
+	imported, err := h.transService.ImportReceipt(r.Context(), scope, receipt)
+	if err != nil {
+		var de *domain.DomainError
+		if errors.As(err, &de) {
+			status := http.StatusBadRequest
+			if de == domain.ErrReceiptAlreadyImported || de == domain.ErrPeriodClosed {
+				status = http.StatusConflict
+			}
+			http.Error(w, de.Message, status)
+			return
+		}
+		log.Printf("Error importing receipt: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(imported)
+}
+
+// readReceiptForm читает поля формы и распознает QR-код на изображении чека.
+// При ошибке ответ уже записан и возвращается false.
+func (h *TransactionHandler) readReceiptForm(w http.ResponseWriter, r *http.Request) (schemas.ReceiptImport, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxReceiptImageSize+1<<20)
+	if err := r.ParseMultipartForm(maxReceiptImageSize); err != nil {
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return schemas.ReceiptImport{}, false
+	}
+
+	categoryID, _ := strconv.Atoi(r.FormValue("category_id"))
+	receipt := schemas.ReceiptImport{
+		UserType:   r.FormValue("user_type"),
+		SellerINN:  r.FormValue("seller_inn"),
+		CategoryID: categoryID,
+		Comment:    r.FormValue("comment"),
+	}
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "Field image is required", http.StatusBadRequest)
+		return schemas.ReceiptImport{}, false
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		http.Error(w, "Image must be JPEG or PNG", http.StatusBadRequest)
+		return schemas.ReceiptImport{}, false
+	}
+
+	receipt.QR, err = qrdecode.Decode(img)
+	if err != nil {
+		http.Error(w, domain.ErrReceiptQRNotFound.Message, http.StatusUnprocessableEntity)
+		return schemas.ReceiptImport{}, false
+	}
+
+	return receipt, true
+}
+
+// GetPaymentQR отдает платежный QR-код подготовленного платежа в формате PNG (по умолчанию) или SVG.
+func (h *TransactionHandler) GetPaymentQR(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	size := defaultPaymentQRSize
+	if value := r.URL.Query().Get("size"); value != "" {
+		size, err = strconv.Atoi(value)
+		if err != nil || size < minPaymentQRSize || size > maxPaymentQRSize {
+			http.Error(w, fmt.Sprintf("size must be between %d and %d", minPaymentQRSize, maxPaymentQRSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "svg" {
+		http.Error(w, "format must be png or svg", http.StatusBadRequest)
+		return
+	}
+
+	payload, err := h.transService.GetPaymentQRPayload(r.Context(), scope, id)
+	if err != nil {
+		var de *domain.DomainError
+		if errors.As(err, &de) {
+			http.Error(w, de.Message, http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, transaction.ErrTransactionNotFound) {
+			http.Error(w, "Prepared transaction not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error building payment qr: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var content []byte
+	contentType := "image/png"
+	if format == "svg" {
+		content, err = paymentqr.SVG(payload, size)
+		contentType = "image/svg+xml"
+	} else {
+		content, err = paymentqr.PNG(payload, size)
+	}
+	if err != nil {
+		log.Printf("Error encoding payment qr: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"payment-%d.%s\"", id, format))
+	w.Write(content)
+}
@@ -6,11 +6,14 @@ import (
 	"finance-backend/internal/delivery/http/schemas"
 	"finance-backend/internal/domain"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strings"
 
 	uc "finance-backend/internal/usecase/user"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -51,7 +54,7 @@ func (uh *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	validate := validator.New()
+	validate := validation.New()
 	if err := validate.Struct(requestEntity); err != nil {
 		errorMap := make(map[string]string)
 		for _, verr := range err.(validator.ValidationErrors) {
@@ -67,6 +70,9 @@ func (uh *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	token, err := uh.userUseCase.RegisterUser(r.Context(), requestEntity.ToDomainEntity())
 
 	if err != nil {
+		if writeTooManyAttempts(w, err) {
+			return
+		}
 		var de *domain.DomainError
 		if errors.As(err, &de) {
 			w.WriteHeader(http.StatusBadRequest)
@@ -81,7 +87,7 @@ func (uh *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": token.String()})
+	json.NewEncoder(w).Encode(schemas.NewTokenPairSchema(token))
 }
 
 func (uh *UserHandler) GetAccessToken(w http.ResponseWriter, r *http.Request) {
@@ -93,7 +99,7 @@ func (uh *UserHandler) GetAccessToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	validate := validator.New()
+	validate := validation.New()
 	if err := validate.Struct(requestEntity); err != nil {
 		errorMap := make(map[string]string)
 		for _, verr := range err.(validator.ValidationErrors) {
@@ -106,9 +112,17 @@ func (uh *UserHandler) GetAccessToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := uh.userUseCase.GetAccessToken(r.Context(), requestEntity.Login, requestEntity.Password)
+	result, err := uh.userUseCase.GetAccessToken(r.Context(), requestEntity.Login, requestEntity.Password)
 
 	if err != nil {
+		if writeTooManyAttempts(w, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrUserBlocked) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": domain.ErrUserBlocked.Message})
+			return
+		}
 		var de *domain.DomainError
 		if errors.As(err, &de) {
 			w.WriteHeader(http.StatusBadRequest)
@@ -123,5 +137,186 @@ func (uh *UserHandler) GetAccessToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": token.String()})
+	if result.MFAToken != "" {
+		json.NewEncoder(w).Encode(schemas.MFAChallengeSchema{
+			MFARequired:       true,
+			MFAToken:          result.MFAToken,
+			MFATokenExpiresAt: result.MFATokenExpires,
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(schemas.NewTokenPairSchema(result.Tokens))
+}
+
+func (uh *UserHandler) RefreshTokens(w http.ResponseWriter, r *http.Request) {
+	var requestEntity schemas.RefreshTokenSchema
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := validation.New().Struct(requestEntity); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"refreshtoken": "Field validation for 'refreshtoken' failed on the 'required' tag"})
+		return
+	}
+
+	token, err := uh.userUseCase.RefreshTokens(r.Context(), requestEntity.RefreshToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": domain.ErrInvalidRefreshToken.Message})
+			return
+		}
+		if errors.Is(err, domain.ErrUserBlocked) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": domain.ErrUserBlocked.Message})
+			return
+		}
+		uh.logger.Printf("Error refreshing tokens: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewTokenPairSchema(token))
+}
+
+// Logout завершает текущую сессию. Тело запроса необязательно: refresh-токен в нем
+// отзывается вместе с токеном доступа.
+func (uh *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.GetTokenClaimsFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var requestEntity schemas.LogoutSchema
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := uh.userUseCase.Logout(r.Context(), claims, requestEntity.RefreshToken); err != nil {
+		uh.logger.Printf("Error logging out: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (uh *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.GetTokenClaimsFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	if err := uh.userUseCase.LogoutAll(r.Context(), claims); err != nil {
+		uh.logger.Printf("Error logging out from all sessions: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestPasswordReset всегда отвечает 202, чтобы по ответу нельзя было проверить, зарегистрирован ли email.
+func (uh *UserHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var requestEntity schemas.PasswordResetRequestSchema
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := validation.New().Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	if err := uh.userUseCase.RequestPasswordReset(r.Context(), requestEntity.Email); err != nil {
+		uh.logger.Printf("Error requesting password reset: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (uh *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var requestEntity schemas.PasswordResetSchema
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := validation.New().Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	if err := uh.userUseCase.ResetPassword(r.Context(), requestEntity.Token, requestEntity.Password); err != nil {
+		if errors.Is(err, domain.ErrInvalidPasswordResetToken) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": domain.ErrInvalidPasswordResetToken.Message})
+			return
+		}
+		uh.logger.Printf("Error resetting password: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangePassword меняет пароль текущего пользователя и возвращает новую пару токенов:
+// все прежние сессии, включая текущую, завершаются.
+func (uh *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.GetTokenClaimsFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var requestEntity schemas.ChangePasswordSchema
+	if err := json.NewDecoder(r.Body).Decode(&requestEntity); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"msg": "JSON decode error", "error": err.Error()})
+		return
+	}
+
+	if err := validation.New().Struct(requestEntity); err != nil {
+		writeValidationErrors(w, err)
+		return
+	}
+
+	token, err := uh.userUseCase.ChangePassword(r.Context(), claims, requestEntity.CurrentPassword, requestEntity.NewPassword)
+	if err != nil {
+		var de *domain.DomainError
+		if errors.As(err, &de) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": de.Message})
+			return
+		}
+		uh.logger.Printf("Error changing password: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewTokenPairSchema(token))
 }
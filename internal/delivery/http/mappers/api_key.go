@@ -0,0 +1,37 @@
+package mappers
+
+import (
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+)
+
+func MapAPIKeyToResponse(key *domain.APIKey) schemas.APIKeyResponse {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+	return schemas.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
+
+func MapAPIKeysToResponse(keys []domain.APIKey) []schemas.APIKeyResponse {
+	result := make([]schemas.APIKeyResponse, len(keys))
+	for i := range keys {
+		result[i] = MapAPIKeyToResponse(&keys[i])
+	}
+	return result
+}
+
+func MapCreatedAPIKeyToResponse(key *domain.CreatedAPIKey) schemas.CreatedAPIKeyResponse {
+	return schemas.CreatedAPIKeyResponse{
+		APIKeyResponse: MapAPIKeyToResponse(&key.APIKey),
+		Key:            key.Key,
+	}
+}
@@ -7,9 +7,9 @@ import (
 )
 
 func MapArticleToArticleResponse(article *domain.Article) schemas.ArticleResponse {
-	categories := make([]schemas.CategoryResponse, 0, len(article.Categories))
+	categories := make([]schemas.CategoryShortResponse, 0, len(article.Categories))
 	for _, category := range article.Categories {
-		categories = append(categories, MapCategoryToCategoryACommonResponse(&category))
+		categories = append(categories, MapCategoryToCategoryShortResponse(&category))
 	}
 	return schemas.ArticleResponse{
 		ID:          article.ID,
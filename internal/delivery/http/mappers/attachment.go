@@ -0,0 +1,47 @@
+package mappers
+
+import (
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"net/http"
+)
+
+func MapAttachmentToAttachmentResponse(attachment *domain.Attachment) schemas.AttachmentResponse {
+	return schemas.AttachmentResponse{
+		ID:            attachment.ID,
+		TransactionID: attachment.TransactionID,
+		FileName:      attachment.FileName,
+		ContentType:   attachment.ContentType,
+		Size:          attachment.Size,
+		OwnerLogin:    attachment.OwnerLogin,
+		CreatedAt:     attachment.CreatedAt,
+	}
+}
+
+func MapAttachmentsToResponse(attachments []domain.Attachment) []schemas.AttachmentResponse {
+	result := make([]schemas.AttachmentResponse, len(attachments))
+	for i := range attachments {
+		result[i] = MapAttachmentToAttachmentResponse(&attachments[i])
+	}
+	return result
+}
+
+func MapPresignedURLToResponse(presigned *domain.PresignedURL) schemas.PresignedURLResponse {
+	response := schemas.PresignedURLResponse{
+		URL:       presigned.URL,
+		Method:    presigned.Method,
+		ExpiresAt: presigned.ExpiresAt,
+	}
+	// Для загрузки Content-Type входит в подпись и должен совпадать в запросе клиента.
+	if presigned.Method == http.MethodPut {
+		response.Headers = map[string]string{"Content-Type": presigned.ContentType}
+	}
+	return response
+}
+
+func MapAttachmentUploadToResponse(upload *domain.AttachmentUpload) schemas.AttachmentUploadResponse {
+	return schemas.AttachmentUploadResponse{
+		ObjectKey:            upload.ObjectKey,
+		PresignedURLResponse: MapPresignedURLToResponse(&upload.PresignedURL),
+	}
+}
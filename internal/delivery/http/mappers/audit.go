@@ -0,0 +1,50 @@
+package mappers
+
+import (
+	"encoding/json"
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+)
+
+func MapAuditEntryToResponse(entry *domain.AuditEntry) schemas.AuditEntryResponse {
+	return schemas.AuditEntryResponse{
+		ID:         entry.ID,
+		Actor:      entry.Actor,
+		APIKeyID:   entry.APIKeyID,
+		Action:     string(entry.Action),
+		EntityType: string(entry.EntityType),
+		EntityID:   entry.EntityID,
+		OrgID:      entry.OrgID,
+		Before:     auditState(entry.Before),
+		After:      auditState(entry.After),
+		RequestID:  entry.RequestID,
+		IP:         entry.IP,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+func MapPaginatedAuditEntriesToResponse(
+	input utils.PaginatedEntities[domain.AuditEntry],
+) utils.PaginatedEntities[schemas.AuditEntryResponse] {
+	mappedItems := make([]schemas.AuditEntryResponse, len(input.Items))
+	for i := range input.Items {
+		mappedItems[i] = MapAuditEntryToResponse(&input.Items[i])
+	}
+
+	return utils.PaginatedEntities[schemas.AuditEntryResponse]{
+		Items:            mappedItems,
+		Total:            input.Total,
+		PageNumber:       input.PageNumber,
+		ObjectsCount:     input.ObjectsCount,
+		ObjectsCounTotal: input.ObjectsCounTotal,
+		PageCount:        input.PageCount,
+	}
+}
+
+func auditState(state string) json.RawMessage {
+	if state == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(state)
+}
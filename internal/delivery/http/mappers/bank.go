@@ -0,0 +1,25 @@
+package mappers
+
+import (
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+)
+
+func MapBankToBankResponse(bank *domain.Bank) schemas.BankResponse {
+	return schemas.BankResponse{
+		BIC:         bank.BIC,
+		Name:        bank.Name,
+		EnglishName: bank.EnglishName,
+		CorrAccount: bank.CorrAccount,
+		Settlement:  bank.Settlement,
+		Address:     bank.Address,
+	}
+}
+
+func MapBanksToBanksResponse(banks []domain.Bank) []schemas.BankResponse {
+	mappedItems := make([]schemas.BankResponse, len(banks))
+	for i := range banks {
+		mappedItems[i] = MapBankToBankResponse(&banks[i])
+	}
+	return mappedItems
+}
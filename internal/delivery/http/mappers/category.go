@@ -6,36 +6,35 @@ import (
 	"finance-backend/pkg/utils"
 )
 
-func MapCategoryToCategoryAdminResponse(category *domain.Category) schemas.CategoryAdminResponse {
-	return schemas.CategoryAdminResponse{
-		ID:        category.ID,
-		Name:      category.Name,
-		CreatedAt: category.CreatedAt,
-		UpdatedAt: category.UpdatedAt,
+func MapCategoryToCategoryResponse(category *domain.Category) schemas.CategoryResponse {
+	return schemas.CategoryResponse{
+		ID:         category.ID,
+		Name:       category.Name,
+		Type:       category.Type,
+		ParentID:   category.ParentID,
+		Shared:     category.IsShared(),
+		ArchivedAt: category.ArchivedAt,
+		CreatedAt:  category.CreatedAt,
+		UpdatedAt:  category.UpdatedAt,
 	}
 }
 
-func MapCategoryToCategoryACommonResponse(category *domain.Category) schemas.CategoryResponse {
-	return schemas.CategoryResponse{
+func MapCategoryToCategoryShortResponse(category *domain.Category) schemas.CategoryShortResponse {
+	return schemas.CategoryShortResponse{
 		ID:   category.ID,
 		Name: category.Name,
 	}
 }
 
-func MapPaginatedCategoriesToAdminResponse(
+func MapPaginatedCategoriesToResponse(
 	input utils.PaginatedEntities[domain.Category],
-) utils.PaginatedEntities[schemas.CategoryAdminResponse] {
-	mappedItems := make([]schemas.CategoryAdminResponse, len(input.Items))
-	for i, category := range input.Items {
-		mappedItems[i] = schemas.CategoryAdminResponse{
-			ID:        category.ID,
-			Name:      category.Name,
-			CreatedAt: category.CreatedAt,
-			UpdatedAt: category.UpdatedAt,
-		}
+) utils.PaginatedEntities[schemas.CategoryResponse] {
+	mappedItems := make([]schemas.CategoryResponse, len(input.Items))
+	for i := range input.Items {
+		mappedItems[i] = MapCategoryToCategoryResponse(&input.Items[i])
 	}
 
-	return utils.PaginatedEntities[schemas.CategoryAdminResponse]{
+	return utils.PaginatedEntities[schemas.CategoryResponse]{
 		Items:            mappedItems,
 		Total:            input.Total,
 		PageNumber:       input.PageNumber,
@@ -44,14 +43,3 @@ func MapPaginatedCategoriesToAdminResponse(
 		PageCount:        input.PageCount,
 	}
 }
-
-func MapCategoriesToCategoriesRepsonse(categories []domain.Category) []schemas.CategoryResponse {
-	mappedItems := make([]schemas.CategoryResponse, len(categories))
-	for i, category := range categories {
-		mappedItems[i] = schemas.CategoryResponse{
-			ID:   category.ID,
-			Name: category.Name,
-		}
-	}
-	return mappedItems
-}
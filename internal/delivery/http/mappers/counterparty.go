@@ -0,0 +1,40 @@
+package mappers
+
+import (
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+)
+
+func MapCounterpartyToCounterpartyResponse(counterparty *domain.Counterparty) schemas.CounterpartyResponse {
+	return schemas.CounterpartyResponse{
+		ID:                counterparty.ID,
+		Name:              counterparty.Name,
+		INN:               counterparty.INN,
+		Phone:             counterparty.Phone,
+		Bank:              counterparty.Bank,
+		BankBIC:           counterparty.BankBIC,
+		Account:           counterparty.Account,
+		DefaultCategoryID: counterparty.DefaultCategoryID,
+		CreatedAt:         counterparty.CreatedAt,
+		UpdatedAt:         counterparty.UpdatedAt,
+	}
+}
+
+func MapPaginatedCounterpartiesToResponse(
+	input utils.PaginatedEntities[domain.Counterparty],
+) utils.PaginatedEntities[schemas.CounterpartyResponse] {
+	mappedItems := make([]schemas.CounterpartyResponse, len(input.Items))
+	for i := range input.Items {
+		mappedItems[i] = MapCounterpartyToCounterpartyResponse(&input.Items[i])
+	}
+
+	return utils.PaginatedEntities[schemas.CounterpartyResponse]{
+		Items:            mappedItems,
+		Total:            input.Total,
+		PageNumber:       input.PageNumber,
+		ObjectsCount:     input.ObjectsCount,
+		ObjectsCounTotal: input.ObjectsCounTotal,
+		PageCount:        input.PageCount,
+	}
+}
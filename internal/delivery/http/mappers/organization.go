@@ -0,0 +1,60 @@
+package mappers
+
+import (
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+)
+
+func MapOrganizationToResponse(org *domain.Organization) schemas.OrganizationResponse {
+	return schemas.OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		INN:       org.INN,
+		CreatedBy: org.CreatedBy,
+		CreatedAt: org.CreatedAt,
+		Role:      org.Role.String(),
+	}
+}
+
+func MapOrganizationsToResponse(orgs []domain.Organization) []schemas.OrganizationResponse {
+	result := make([]schemas.OrganizationResponse, len(orgs))
+	for i := range orgs {
+		result[i] = MapOrganizationToResponse(&orgs[i])
+	}
+	return result
+}
+
+func MapOrgMembersToResponse(members []domain.OrgMember) []schemas.OrgMemberResponse {
+	result := make([]schemas.OrgMemberResponse, len(members))
+	for i, member := range members {
+		result[i] = schemas.OrgMemberResponse{
+			Login:    member.Login,
+			Name:     member.Name,
+			Email:    member.Email,
+			Role:     member.Role.String(),
+			JoinedAt: member.JoinedAt,
+		}
+	}
+	return result
+}
+
+func MapOrgInvitationToResponse(invitation *domain.OrgInvitation) schemas.OrgInvitationResponse {
+	return schemas.OrgInvitationResponse{
+		ID:               invitation.ID,
+		OrganizationID:   invitation.OrgID,
+		OrganizationName: invitation.OrgName,
+		Email:            invitation.Email,
+		Role:             invitation.Role.String(),
+		InvitedBy:        invitation.InvitedBy,
+		CreatedAt:        invitation.CreatedAt,
+		ExpiresAt:        invitation.ExpiresAt,
+	}
+}
+
+func MapOrgInvitationsToResponse(invitations []domain.OrgInvitation) []schemas.OrgInvitationResponse {
+	result := make([]schemas.OrgInvitationResponse, len(invitations))
+	for i := range invitations {
+		result[i] = MapOrgInvitationToResponse(&invitations[i])
+	}
+	return result
+}
@@ -0,0 +1,29 @@
+package mappers
+
+import (
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+)
+
+func MapAccountingPeriodToResponse(period *domain.AccountingPeriod) schemas.AccountingPeriodResponse {
+	response := schemas.AccountingPeriodResponse{
+		Month:      period.Month.Format(domain.PeriodMonthLayout),
+		Closed:     period.Closed,
+		ClosedBy:   period.ClosedBy,
+		ReopenedBy: period.ReopenedBy,
+		ReopenedAt: period.ReopenedAt,
+	}
+	if !period.ClosedAt.IsZero() {
+		closedAt := period.ClosedAt
+		response.ClosedAt = &closedAt
+	}
+	return response
+}
+
+func MapAccountingPeriodsToResponse(periods []domain.AccountingPeriod) []schemas.AccountingPeriodResponse {
+	result := make([]schemas.AccountingPeriodResponse, len(periods))
+	for i := range periods {
+		result[i] = MapAccountingPeriodToResponse(&periods[i])
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package mappers
+
+import (
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+)
+
+func MapUserAccountToResponse(user *domain.UserAccount) schemas.UserAccountResponse {
+	return schemas.UserAccountResponse{
+		Login:                 user.Login,
+		Role:                  user.Role.String(),
+		UserType:              user.UserType,
+		Name:                  user.Name,
+		INN:                   user.INN,
+		Phone:                 user.Phone,
+		Email:                 user.Email,
+		Blocked:               user.BlockedAt != nil,
+		BlockedAt:             user.BlockedAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+	}
+}
+
+func MapPaginatedUserAccountsToResponse(
+	input utils.PaginatedEntities[domain.UserAccount],
+) utils.PaginatedEntities[schemas.UserAccountResponse] {
+	mappedItems := make([]schemas.UserAccountResponse, len(input.Items))
+	for i := range input.Items {
+		mappedItems[i] = MapUserAccountToResponse(&input.Items[i])
+	}
+
+	return utils.PaginatedEntities[schemas.UserAccountResponse]{
+		Items:            mappedItems,
+		Total:            input.Total,
+		PageNumber:       input.PageNumber,
+		ObjectsCount:     input.ObjectsCount,
+		ObjectsCounTotal: input.ObjectsCounTotal,
+		PageCount:        input.PageCount,
+	}
+}
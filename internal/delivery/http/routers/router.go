@@ -1,40 +1,93 @@
 package http
 
 import (
+	"net/http"
+
 	"finance-backend/internal/delivery/http/handlers"
+	"finance-backend/internal/domain"
 	"finance-backend/internal/domain/transaction"
+	"finance-backend/pkg/jwtkeys"
 	"finance-backend/pkg/middleware"
 
 	"github.com/gorilla/mux"
 )
 
 func NewMuxRouter(
-	// categoryHandler *handlers.CategoryHandler,
 	// articleHandler *handlers.ArticleHandler,
 	userHandler *handlers.UserHandler,
 	analyticsHandler *handlers.AnalyticsHandler,
+	bankHandler *handlers.BankHandler,
+	counterpartyHandler *handlers.CounterpartyHandler,
+	attachmentHandler *handlers.AttachmentHandler,
+	adminUserHandler *handlers.AdminUserHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	organizationHandler *handlers.OrganizationHandler,
+	auditHandler *handlers.AuditHandler,
+	periodHandler *handlers.PeriodHandler,
+	categoryHandler *handlers.CategoryHandler,
+	fileServer http.Handler,
 	transactionService transaction.Service,
+	sessions middleware.SessionChecker,
+	apiKeys middleware.APIKeyAuthenticator,
+	jwtKeys *jwtkeys.KeyManager,
+	trustProxyHeaders bool,
 ) *mux.Router {
 	router := mux.NewRouter().PathPrefix("/api/v1").Subrouter()
 
 	router.Use(middleware.RequestIdMiddleware)
+	router.Use(middleware.ClientIPMiddleware(trustProxyHeaders))
 	router.Use(middleware.LoggingProcessTimeMiddleware)
 	router.Use(middleware.RecoverMiddleware)
 
 	authRouter := router.NewRoute().Subrouter()
-	authRouter.Use(middleware.JWTParserMiddleware)
+	authRouter.Use(middleware.JWTParserMiddleware(jwtKeys.Keyfunc, sessions, apiKeys))
+
+	// Управление учетной записью — только в сессии пользователя, не по ключу API
+	sessionRouter := authRouter.NewRoute().Subrouter()
+	sessionRouter.Use(middleware.RequireSession)
+
+	// Открытые ключи для проверки токенов другими сервисами
+	router.Handle("/.well-known/jwks.json", jwtKeys).Methods("GET")
 
 	router.HandleFunc("/subject_types", userHandler.GetSubjectTypes).Methods("GET")
 	router.HandleFunc("/registration", userHandler.RegisterUser).Methods("POST")
 	router.HandleFunc("/login", userHandler.GetAccessToken).Methods("POST")
+	router.HandleFunc("/login/2fa", userHandler.CompleteMFALogin).Methods("POST")
+	router.HandleFunc("/oidc/authorize", userHandler.StartOIDCLogin).Methods("POST")
+	router.HandleFunc("/oidc/callback", userHandler.CompleteOIDCLogin).Methods("POST")
+	router.HandleFunc("/token/refresh", userHandler.RefreshTokens).Methods("POST")
+	sessionRouter.HandleFunc("/logout", userHandler.Logout).Methods("POST")
+	sessionRouter.HandleFunc("/logout-all", userHandler.LogoutAll).Methods("POST")
+	router.HandleFunc("/password/reset-request", userHandler.RequestPasswordReset).Methods("POST")
+	router.HandleFunc("/password/reset", userHandler.ResetPassword).Methods("POST")
+	sessionRouter.HandleFunc("/password/change", userHandler.ChangePassword).Methods("POST")
+
+	// Двухфакторная аутентификация (TOTP) — настраивает сам пользователь
+	sessionRouter.HandleFunc("/2fa", userHandler.GetMFAStatus).Methods("GET")
+	sessionRouter.HandleFunc("/2fa/enroll", userHandler.EnrollTOTP).Methods("POST")
+	sessionRouter.HandleFunc("/2fa/verify", userHandler.ConfirmTOTP).Methods("POST")
+	sessionRouter.HandleFunc("/2fa/recovery-codes", userHandler.RegenerateRecoveryCodes).Methods("POST")
+	sessionRouter.HandleFunc("/2fa/disable", userHandler.DisableTOTP).Methods("POST")
+
+	// Персональные ключи API для скриптов и интеграций
+	sessionRouter.HandleFunc("/api-keys", apiKeyHandler.ListAPIKeys).Methods("GET")
+	sessionRouter.HandleFunc("/api-keys", apiKeyHandler.CreateAPIKey).Methods("POST")
+	sessionRouter.HandleFunc("/api-keys/{id:[0-9]+}", apiKeyHandler.RevokeAPIKey).Methods("DELETE")
 
-	// authRouter.HandleFunc("/admin/categories/{id}", categoryHandler.GetAdminCategoryById).Methods("GET")
-	// authRouter.HandleFunc("/categories/{id}", categoryHandler.GetCommonCategoryById).Methods("GET")
-	// authRouter.HandleFunc("/categories", categoryHandler.SearchCategoriesFlat).Methods("GET")
-	// authRouter.HandleFunc("/admin/categories", categoryHandler.SearchCategoriesPaginated).Methods("GET")
-	// authRouter.HandleFunc("/categories", categoryHandler.CreateCategory).Methods("POST")
-	// authRouter.HandleFunc("/categories/{id}", categoryHandler.UpdateCategoryName).Methods("PUT")
-	// authRouter.HandleFunc("/categories/{id}", categoryHandler.DeleteCategory).Methods("DELETE")
+	// Организации: участники, роли и приглашения. Активная организация сессии
+	// выбирается через /organizations/switch и записывается в токены.
+	sessionRouter.HandleFunc("/organizations", organizationHandler.ListOrganizations).Methods("GET")
+	sessionRouter.HandleFunc("/organizations", organizationHandler.CreateOrganization).Methods("POST")
+	sessionRouter.HandleFunc("/organizations/switch", userHandler.SwitchOrganization).Methods("POST")
+	sessionRouter.HandleFunc("/organizations/invitations/accept", organizationHandler.AcceptInvitation).Methods("POST")
+	sessionRouter.HandleFunc("/organizations/{id:[0-9]+}", organizationHandler.GetOrganization).Methods("GET")
+	sessionRouter.HandleFunc("/organizations/{id:[0-9]+}", organizationHandler.UpdateOrganization).Methods("PUT")
+	sessionRouter.HandleFunc("/organizations/{id:[0-9]+}/members", organizationHandler.ListMembers).Methods("GET")
+	sessionRouter.HandleFunc("/organizations/{id:[0-9]+}/members/{login}", organizationHandler.ChangeMemberRole).Methods("PUT")
+	sessionRouter.HandleFunc("/organizations/{id:[0-9]+}/members/{login}", organizationHandler.RemoveMember).Methods("DELETE")
+	sessionRouter.HandleFunc("/organizations/{id:[0-9]+}/invitations", organizationHandler.ListInvitations).Methods("GET")
+	sessionRouter.HandleFunc("/organizations/{id:[0-9]+}/invitations", organizationHandler.InviteMember).Methods("POST")
+	sessionRouter.HandleFunc("/organizations/{id:[0-9]+}/invitations/{invitationId:[0-9]+}", organizationHandler.RevokeInvitation).Methods("DELETE")
 
 	// authRouter.HandleFunc("/articles/{id}", articleHandler.GetCommonArticleById).Methods("GET")
 	// authRouter.HandleFunc("/articles", articleHandler.SearchArticlesPaginated).Methods("GET")
@@ -44,27 +97,113 @@ func NewMuxRouter(
 	// authRouter.HandleFunc("/articles/{id}", articleHandler.DeleteArticle).Methods("DELETE")
 	// authRouter.HandleFunc("/articles/{id}/categories", articleHandler.LinkCategories).Methods("PUT")
 
-	router.HandleFunc("/analytics/dynamics/by-period", analyticsHandler.GetDynamicsByPeriod).Methods("POST")
-	router.HandleFunc("/analytics/categories-summary", analyticsHandler.GetCategoriesSummary).Methods("POST")
+	analyticsRouter := withPermissions(authRouter, domain.PermAnalyticsRead)
+	analyticsRouter.HandleFunc("/analytics/dynamics/by-period", analyticsHandler.GetDynamicsByPeriod).Methods("POST")
+	analyticsRouter.HandleFunc("/analytics/categories-summary", analyticsHandler.GetCategoriesSummary).Methods("POST")
+	analyticsRouter.HandleFunc("/analytics/banks-summary", analyticsHandler.GetBanksSummary).Methods("POST")
+	analyticsRouter.HandleFunc("/analytics/top-counterparties", analyticsHandler.GetTopCounterparties).Methods("POST")
+
+	referencesRouter := withPermissions(authRouter, domain.PermReferencesRead)
+	referencesRouter.HandleFunc("/banks", bankHandler.SearchBanks).Methods("GET")
+	referencesRouter.HandleFunc("/banks/{bic}", bankHandler.GetBankByBIC).Methods("GET")
+
+	counterpartiesReadRouter := withPermissions(authRouter, domain.PermCounterpartiesRead)
+	counterpartiesWriteRouter := withPermissions(authRouter, domain.PermCounterpartiesWrite)
+	counterpartiesReadRouter.HandleFunc("/counterparties", counterpartyHandler.SearchCounterparties).Methods("GET")
+	counterpartiesWriteRouter.HandleFunc("/counterparties", counterpartyHandler.CreateCounterparty).Methods("POST")
+	counterpartiesReadRouter.HandleFunc("/counterparties/{id}", counterpartyHandler.GetCounterpartyByID).Methods("GET")
+	counterpartiesWriteRouter.HandleFunc("/counterparties/{id}", counterpartyHandler.UpdateCounterparty).Methods("PUT")
+	counterpartiesWriteRouter.HandleFunc("/counterparties/{id}", counterpartyHandler.DeleteCounterparty).Methods("DELETE")
+
+	// Вложения ключуются по пользователю, поэтому доступны только с токеном
+	attachmentsReadRouter := withPermissions(authRouter, domain.PermTransactionsRead)
+	attachmentsWriteRouter := withPermissions(authRouter, domain.PermTransactionsWrite)
+	attachmentsReadRouter.HandleFunc("/transactions/{id:[0-9]+}/attachments", attachmentHandler.ListAttachments).Methods("GET")
+	attachmentsWriteRouter.HandleFunc("/transactions/{id:[0-9]+}/attachments", attachmentHandler.UploadAttachment).Methods("POST")
+	attachmentsReadRouter.HandleFunc("/transactions/{id:[0-9]+}/attachments/{attachment_id:[0-9]+}", attachmentHandler.DownloadAttachment).Methods("GET")
+	attachmentsWriteRouter.HandleFunc("/transactions/{id:[0-9]+}/attachments/{attachment_id:[0-9]+}", attachmentHandler.DeleteAttachment).Methods("DELETE")
+	attachmentsReadRouter.HandleFunc("/transactions/{id:[0-9]+}/attachments/{attachment_id:[0-9]+}/url", attachmentHandler.GetDownloadURL).Methods("GET")
+	attachmentsWriteRouter.HandleFunc("/transactions/{id:[0-9]+}/attachments/upload-url", attachmentHandler.CreateUploadURL).Methods("POST")
+	attachmentsWriteRouter.HandleFunc("/transactions/{id:[0-9]+}/attachments/confirm", attachmentHandler.ConfirmUpload).Methods("POST")
+
+	adminRouter := withPermissions(sessionRouter, domain.PermUsersManage)
+	adminRouter.HandleFunc("/admin/users", adminUserHandler.SearchUsers).Methods("GET")
+	adminRouter.HandleFunc("/admin/users/{login}", adminUserHandler.GetUser).Methods("GET")
+	adminRouter.HandleFunc("/admin/users/{login}/role", adminUserHandler.ChangeUserRole).Methods("PUT")
+	adminRouter.HandleFunc("/admin/users/{login}/block", adminUserHandler.BlockUser).Methods("POST")
+	adminRouter.HandleFunc("/admin/users/{login}/unblock", adminUserHandler.UnblockUser).Methods("POST")
+	adminRouter.HandleFunc("/admin/users/{login}/password-reset", adminUserHandler.ResetUserPassword).Methods("POST")
+
+	auditRouter := withPermissions(sessionRouter, domain.PermAuditRead)
+	auditRouter.HandleFunc("/admin/audit-log", auditHandler.SearchAuditLog).Methods("GET")
+
+	// Учетные периоды: закрытый месяц запрещает изменения транзакций с датой в нем
+	periodsReadRouter := withPermissions(authRouter, domain.PermTransactionsRead)
+	periodsManageRouter := withPermissions(sessionRouter, domain.PermPeriodsManage)
+	periodsReadRouter.HandleFunc("/periods", periodHandler.ListPeriods).Methods("GET")
+	periodsReadRouter.HandleFunc("/periods/{month}", periodHandler.GetPeriod).Methods("GET")
+	periodsManageRouter.HandleFunc("/periods/{month}/close", periodHandler.ClosePeriod).Methods("POST")
+	periodsManageRouter.HandleFunc("/periods/{month}/reopen", periodHandler.ReopenPeriod).Methods("POST")
+
+	// Собственные категории пользователя или организации; общий справочник только для чтения
+	categoriesReadRouter := withPermissions(authRouter, domain.PermReferencesRead)
+	categoriesWriteRouter := withPermissions(authRouter, domain.PermCategoriesWrite)
+	categoriesReadRouter.HandleFunc("/categories/search", categoryHandler.SearchCategories).Methods("GET")
+	categoriesReadRouter.HandleFunc("/categories/{id:[0-9]+}", categoryHandler.GetCategoryByID).Methods("GET")
+	categoriesWriteRouter.HandleFunc("/categories", categoryHandler.CreateCategory).Methods("POST")
+	categoriesWriteRouter.HandleFunc("/categories/{id:[0-9]+}", categoryHandler.UpdateCategoryName).Methods("PUT")
+	categoriesWriteRouter.HandleFunc("/categories/{id:[0-9]+}", categoryHandler.DeleteCategory).Methods("DELETE")
+	categoriesWriteRouter.HandleFunc("/categories/{id:[0-9]+}/archive", categoryHandler.ArchiveCategory).Methods("POST")
+	categoriesWriteRouter.HandleFunc("/categories/{id:[0-9]+}/unarchive", categoryHandler.UnarchiveCategory).Methods("POST")
+	categoriesWriteRouter.HandleFunc("/categories/{id:[0-9]+}/merge", categoryHandler.MergeCategory).Methods("POST")
+
+	// Подписанные ссылки локального хранилища: доступ проверяется подписью, а не токеном
+	if fileServer != nil {
+		router.PathPrefix("/files/").Handler(http.StripPrefix("/api/v1/files", fileServer))
+	}
 
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
-	SetupRoutes(router, transactionHandler)
+	SetupRoutes(authRouter, transactionHandler)
 
 	return router
 }
 
-func SetupRoutes(router *mux.Router, transactionHandler *handlers.TransactionHandler) {
+// withPermissions возвращает подмаршрутизатор, маршруты которого доступны только
+// пользователям со всеми правами perms. Подключается к authRouter, чтобы токен уже был разобран.
+func withPermissions(authRouter *mux.Router, perms ...domain.Permission) *mux.Router {
+	router := authRouter.NewRoute().Subrouter()
+	router.Use(middleware.RequirePermission(perms...))
+	return router
+}
+
+// SetupRoutes регистрирует маршруты транзакций на authRouter — маршрутизаторе с проверкой токена.
+func SetupRoutes(authRouter *mux.Router, transactionHandler *handlers.TransactionHandler) {
+	readRouter := withPermissions(authRouter, domain.PermTransactionsRead)
+	writeRouter := withPermissions(authRouter, domain.PermTransactionsWrite)
+	referencesRouter := withPermissions(authRouter, domain.PermReferencesRead)
+
 	// Маршруты для транзакций
-	router.HandleFunc("/transactions", transactionHandler.GetTransactions).Methods("GET")
-	router.HandleFunc("/transactions/filter", transactionHandler.GetTransactions).Methods("POST")
-	router.HandleFunc("/transactions", transactionHandler.CreateTransaction).Methods("POST")
-	router.HandleFunc("/transactions/{id}", transactionHandler.DeleteTransaction).Methods("DELETE")
+	readRouter.HandleFunc("/transactions", transactionHandler.GetTransactions).Methods("GET")
+	readRouter.HandleFunc("/transactions/filter", transactionHandler.GetTransactions).Methods("POST")
+	readRouter.HandleFunc("/transactions/{id:[0-9]+}/history", transactionHandler.GetTransactionHistory).Methods("GET")
+	writeRouter.HandleFunc("/transactions", transactionHandler.CreateTransaction).Methods("POST")
+	writeRouter.HandleFunc("/transactions/receipts", transactionHandler.ImportReceipt).Methods("POST")
+	writeRouter.HandleFunc("/transactions/{id}", transactionHandler.DeleteTransaction).Methods("DELETE")
+
+	// Корзина: удаленные транзакции хранятся TRASH_RETENTION, затем удаляются окончательно
+	readRouter.HandleFunc("/transactions/trash", transactionHandler.GetDeletedTransactions).Methods("GET")
+	writeRouter.HandleFunc("/transactions/{id:[0-9]+}/restore", transactionHandler.RestoreTransaction).Methods("POST")
 
 	// Маршруты для подготовленных транзакций
-	router.HandleFunc("/transactions/prepared", transactionHandler.GetPreparedTransactions).Methods("GET")
-	router.HandleFunc("/transactions/prepared", transactionHandler.CreatePreparedTransaction).Methods("POST")
+	readRouter.HandleFunc("/transactions/prepared", transactionHandler.GetPreparedTransactions).Methods("GET")
+	writeRouter.HandleFunc("/transactions/prepared", transactionHandler.CreatePreparedTransaction).Methods("POST")
+	readRouter.HandleFunc("/transactions/prepared/{id:[0-9]+}/payment-qr", transactionHandler.GetPaymentQR).Methods("GET")
 
 	// Маршруты для категорий и статусов
-	router.HandleFunc("/categories", transactionHandler.GetCategories).Methods("GET")
-	router.HandleFunc("/trans_statuses", transactionHandler.GetTransactionStatuses).Methods("GET")
+	referencesRouter.HandleFunc("/categories", transactionHandler.GetCategories).Methods("GET")
+	referencesRouter.HandleFunc("/categories/tree", transactionHandler.GetCategoryTree).Methods("GET")
+	referencesRouter.HandleFunc("/categories/{id:[0-9]+}/tree", transactionHandler.GetCategoryTree).Methods("GET")
+	categoriesWriteRouter := withPermissions(authRouter, domain.PermCategoriesWrite)
+	categoriesWriteRouter.HandleFunc("/categories/{id:[0-9]+}/parent", transactionHandler.SetCategoryParent).Methods("PUT")
+	referencesRouter.HandleFunc("/trans_statuses", transactionHandler.GetTransactionStatuses).Methods("GET")
 }
@@ -25,8 +25,18 @@ type CategoriesSummaryRequest struct {
 }
 
 type CategoriesSummaryResponse struct {
-	Data []struct {
-		Category string  `json:"category"`
-		Value    float64 `json:"value"`
-	} `json:"data"`
+	Data []CategorySummaryItem `json:"data"`
+}
+
+// CategorySummaryItem — сумма по категории. В режимах rollup и parent_id сумма включает
+// подкатегории, CategoryID и HasChildren позволяют перейти на уровень ниже.
+type CategorySummaryItem struct {
+	Category    string  `json:"category"`
+	Value       float64 `json:"value"`
+	CategoryID  int     `json:"category_id,omitempty"`
+	HasChildren bool    `json:"has_children,omitempty"`
+}
+
+type BanksSummaryRequest struct {
+	Date DateRange `json:"date" validate:"required"`
 }
@@ -0,0 +1,40 @@
+package schemas
+
+import (
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type APIKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+// CreatedAPIKeyResponse содержит сам ключ: он показывается только в ответе на создание.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type CreateAPIKeySchema struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=transactions:read transactions:write analytics:read"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+func (s *CreateAPIKeySchema) ToDomainEntity() *domain.APIKeyCreationData {
+	scopes := make([]domain.APIKeyScope, len(s.Scopes))
+	for i, scope := range s.Scopes {
+		scopes[i] = domain.APIKeyScope(scope)
+	}
+	return &domain.APIKeyCreationData{
+		Name:      s.Name,
+		Scopes:    scopes,
+		ExpiresAt: s.ExpiresAt,
+	}
+}
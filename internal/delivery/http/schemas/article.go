@@ -1,12 +1,12 @@
 package schemas
 
 type ArticleResponse struct {
-	ID          int64              `json:"id"`
-	Header      string             `json:"header"`
-	SubHeader   string             `json:"sub_header"`
-	Description string             `json:"description"`
-	Image       *string            `json:"image"`
-	Categories  []CategoryResponse `json:"categories"`
+	ID          int64                   `json:"id"`
+	Header      string                  `json:"header"`
+	SubHeader   string                  `json:"sub_header"`
+	Description string                  `json:"description"`
+	Image       *string                 `json:"image"`
+	Categories  []CategoryShortResponse `json:"categories"`
 }
 
 type CreateArticleRequest struct {
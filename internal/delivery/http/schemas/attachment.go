@@ -0,0 +1,35 @@
+package schemas
+
+import "time"
+
+type AttachmentResponse struct {
+	ID            int64     `json:"id"`
+	TransactionID int64     `json:"transaction_id"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	OwnerLogin    string    `json:"owner_login"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type CreateAttachmentUploadRequest struct {
+	ContentType string `json:"content_type" validate:"required,oneof=image/jpeg image/png application/pdf"`
+	Size        int64  `json:"size" validate:"required,gt=0"`
+}
+
+type ConfirmAttachmentUploadRequest struct {
+	ObjectKey string `json:"object_key" validate:"required,max=512"`
+	FileName  string `json:"file_name" validate:"required,max=255"`
+}
+
+type PresignedURLResponse struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type AttachmentUploadResponse struct {
+	ObjectKey string `json:"object_key"`
+	PresignedURLResponse
+}
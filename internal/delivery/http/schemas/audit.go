@@ -0,0 +1,23 @@
+package schemas
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntryResponse — запись журнала аудита. before и after — состояние сущности
+// до и после изменения, null — состояния нет.
+type AuditEntryResponse struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	APIKeyID   *int64          `json:"apiKeyId"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	OrgID      *int64          `json:"organizationId"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"requestId"`
+	IP         string          `json:"ip"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
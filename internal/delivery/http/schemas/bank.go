@@ -0,0 +1,10 @@
+package schemas
+
+type BankResponse struct {
+	BIC         string `json:"bic"`
+	Name        string `json:"name"`
+	EnglishName string `json:"english_name"`
+	CorrAccount string `json:"corr_account"`
+	Settlement  string `json:"settlement"`
+	Address     string `json:"address"`
+}
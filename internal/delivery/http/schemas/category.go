@@ -1,19 +1,53 @@
 package schemas
 
-import "time"
+import (
+	"finance-backend/internal/domain"
+	"time"
+)
 
+// CategoryResponse — категория со сведениями о владельце: shared — общий справочник,
+// который нельзя изменять.
 type CategoryResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	ParentID   *int64     `json:"parent_id"`
+	Shared     bool       `json:"shared"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CategoryShortResponse — категория в составе другой сущности.
+type CategoryShortResponse struct {
 	ID   int64  `json:"id"`
 	Name string `json:"name"`
 }
 
-type CategoryAdminResponse struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+type CreateCategoryRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=255"`
+	Type     string `json:"type" validate:"required,oneof=credit debit"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+func (r *CreateCategoryRequest) ToDomainEntity() domain.CategoryData {
+	return domain.CategoryData{
+		Name:     r.Name,
+		Type:     r.Type,
+		ParentID: r.ParentID,
+	}
+}
+
+type UpdateCategoryRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=255"`
+}
+
+// MergeCategoryRequest — категория, в которую переносятся операции объединяемой категории.
+type MergeCategoryRequest struct {
+	TargetID int64 `json:"target_id" validate:"required,gt=0"`
 }
 
-type UpdateOrCreateCategoryRequest struct {
-	Name string `json:"name" validate:"required,min=3"`
+type MergeCategoryResponse struct {
+	Category          CategoryResponse `json:"category"` // категория, в которую перенесены операции
+	TransactionsMoved int64            `json:"transactions_moved"`
 }
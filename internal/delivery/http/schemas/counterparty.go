@@ -0,0 +1,61 @@
+package schemas
+
+import (
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type CounterpartyResponse struct {
+	ID                int64     `json:"id"`
+	Name              string    `json:"name"`
+	INN               *string   `json:"inn"`
+	Phone             *string   `json:"phone"`
+	Bank              *string   `json:"bank"`
+	BankBIC           *string   `json:"bank_bic"`
+	Account           *string   `json:"account"`
+	DefaultCategoryID *int64    `json:"default_category_id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type CreateOrUpdateCounterpartyRequest struct {
+	Name              string `json:"name" validate:"required,min=2,max=255"`
+	INN               string `json:"inn" validate:"required_without=Phone,omitempty,inn"`
+	Phone             string `json:"phone" validate:"omitempty,ru_phone"`
+	Bank              string `json:"bank" validate:"max=255"`
+	BankBIC           string `json:"bank_bic" validate:"required_with=Account,omitempty,bic"`
+	Account           string `json:"account" validate:"omitempty,len=20,numeric,account_key=BankBIC"`
+	DefaultCategoryID *int64 `json:"default_category_id"`
+}
+
+func (r *CreateOrUpdateCounterpartyRequest) ToDomainEntity() *domain.CounterpartyData {
+	return &domain.CounterpartyData{
+		Name:              r.Name,
+		INN:               nilIfEmpty(r.INN),
+		Phone:             nilIfEmpty(r.Phone),
+		Bank:              nilIfEmpty(r.Bank),
+		BankBIC:           nilIfEmpty(r.BankBIC),
+		Account:           nilIfEmpty(r.Account),
+		DefaultCategoryID: r.DefaultCategoryID,
+	}
+}
+
+type TopCounterpartiesRequest struct {
+	Date DateRange `json:"date" validate:"required"`
+}
+
+type CounterpartySummaryResponse struct {
+	CounterpartyID int64   `json:"counterparty_id"`
+	Name           string  `json:"name"`
+	INN            string  `json:"inn"`
+	Phone          string  `json:"phone"`
+	Count          int     `json:"count"`
+	Amount         float64 `json:"amount"`
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,75 @@
+package schemas
+
+import (
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type OrganizationResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	INN       *string   `json:"inn"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	Role      string    `json:"role"` // роль текущего пользователя в организации
+}
+
+// OrganizationSchema — данные для создания и изменения организации.
+type OrganizationSchema struct {
+	Name string `json:"name" validate:"required,max=255"`
+	INN  string `json:"inn" validate:"omitempty,inn"`
+}
+
+func (s *OrganizationSchema) ToDomainEntity() *domain.OrganizationData {
+	data := &domain.OrganizationData{Name: s.Name}
+	if s.INN != "" {
+		data.INN = &s.INN
+	}
+	return data
+}
+
+type OrgMemberResponse struct {
+	Login    string    `json:"loginName"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+type OrgMemberRoleSchema struct {
+	Role string `json:"role" validate:"required,oneof=owner admin accountant member viewer"`
+}
+
+type OrgInvitationResponse struct {
+	ID               int64     `json:"id"`
+	OrganizationID   int64     `json:"organizationId"`
+	OrganizationName string    `json:"organizationName"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	InvitedBy        string    `json:"invitedBy"`
+	CreatedAt        time.Time `json:"createdAt"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
+type OrgInvitationSchema struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=owner admin accountant member viewer"`
+}
+
+func (s *OrgInvitationSchema) ToDomainEntity() *domain.OrgInvitationData {
+	return &domain.OrgInvitationData{
+		Email: s.Email,
+		Role:  domain.OrgRole(s.Role),
+	}
+}
+
+type AcceptOrgInvitationSchema struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// SwitchOrganizationSchema — переход в организацию; organizationId = 0 — в личный учет.
+// refreshToken текущей сессии отзывается вместе с токеном доступа.
+type SwitchOrganizationSchema struct {
+	OrganizationID int64  `json:"organizationId" validate:"gte=0"`
+	RefreshToken   string `json:"refreshToken"`
+}
@@ -0,0 +1,14 @@
+package schemas
+
+import "time"
+
+// AccountingPeriodResponse — учетный период. closedBy и closedAt — кто и когда закрыл период
+// последним, reopenedBy и reopenedAt — кто и когда последним открыл его снова.
+type AccountingPeriodResponse struct {
+	Month      string     `json:"month"` // ГГГГ-ММ
+	Closed     bool       `json:"closed"`
+	ClosedBy   string     `json:"closedBy,omitempty"`
+	ClosedAt   *time.Time `json:"closedAt,omitempty"`
+	ReopenedBy string     `json:"reopenedBy,omitempty"`
+	ReopenedAt *time.Time `json:"reopenedAt,omitempty"`
+}
@@ -3,51 +3,109 @@ package schemas
 import "time"
 
 type Transaction struct {
-	ID            int       `json:"id"`
-	UserType      string    `json:"user_type"` // ФЛ или ЮЛ
-	DateTime      time.Time `json:"date_time"` // Дата и время операции
-	TransType     string    `json:"trans_type"`
-	Amount        float64   `json:"amount"`      // Сумма (точность до 5 знаков)
-	CategoryID    int       `json:"category_id"` // ID категории
-	StatusID      int       `json:"status_id"`
-	SenderBank    string    `json:"sender_bank"`    // Банк отправителя
-	ReceiverINN   string    `json:"receiver_inn"`   // ИНН получателя
-	ReceiverPhone string    `json:"receiver_phone"` // Телефон получателя
-	Comment       string    `json:"comment"`        // Комментарий к операции
-	CategoryName  string    `json:"category_name"`
-	StatusName    string    `json:"status_name"`
+	ID             int       `json:"id"`
+	UserType       string    `json:"user_type"` // ФЛ или ЮЛ
+	DateTime       time.Time `json:"date_time"` // Дата и время операции
+	TransType      string    `json:"trans_type"`
+	Amount         float64   `json:"amount"`      // Сумма (точность до 5 знаков)
+	CategoryID     int       `json:"category_id"` // ID категории
+	StatusID       int       `json:"status_id"`
+	SenderBank     string    `json:"sender_bank"`                                  // Банк отправителя (как введен)
+	SenderBankBIC  string    `json:"sender_bank_bic" validate:"omitempty,bic"`     // БИК банка отправителя
+	SenderBankName string    `json:"sender_bank_name"`                             // Название банка по справочнику БИК
+	CounterpartyID int       `json:"counterparty_id"`                              // ID контрагента из справочника
+	ReceiverINN    string    `json:"receiver_inn" validate:"omitempty,inn"`        // ИНН получателя
+	ReceiverPhone  string    `json:"receiver_phone" validate:"omitempty,ru_phone"` // Телефон получателя (E.164)
+	Comment        string    `json:"comment"`                                      // Комментарий к операции
+	CategoryName   string    `json:"category_name"`
+	StatusName     string    `json:"status_name"`
+	// Заполнены только у транзакций в корзине.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty"`
 }
 
 type TransactionFilter struct {
-	UserType      string    `json:"user_type"`
-	TransType     string    `json:"trans_type"`
-	SenderBank    string    `json:"sender_bank"`
-	ReceiverINN   string    `json:"receiver_inn"`
-	ReceiverPhone string    `json:"receiver_phone"`
-	DateFrom      time.Time `json:"date_from"`
-	DateTo        time.Time `json:"date_to"`
-	CategoryID    int       `json:"category_id"`
-	StatusID      int       `json:"status_id"`
+	UserType       string    `json:"user_type"`
+	TransType      string    `json:"trans_type"`
+	SenderBank     string    `json:"sender_bank"`
+	SenderBankBIC  string    `json:"sender_bank_bic"`
+	CounterpartyID int       `json:"counterparty_id"`
+	ReceiverINN    string    `json:"receiver_inn"`
+	ReceiverPhone  string    `json:"receiver_phone"`
+	DateFrom       time.Time `json:"date_from"`
+	DateTo         time.Time `json:"date_to"`
+	CategoryID     int       `json:"category_id"`
+	StatusID       int       `json:"status_id"`
+	// AsOf — показать транзакции в состоянии на этот момент (параметр as_of).
+	AsOf *time.Time `json:"as_of,omitempty"`
+}
+
+// TransactionVersion — состояние транзакции в интервале [valid_from, valid_to).
+type TransactionVersion struct {
+	Transaction
+	VersionID int64      `json:"version_id"`
+	ValidFrom time.Time  `json:"valid_from"`
+	ValidTo   *time.Time `json:"valid_to"`
 }
 
 type PreparedTransaction struct {
-	ID            int       `json:"id"`
-	UserType      string    `json:"user_type"`
-	DateTime      time.Time `json:"date_time"`
-	TransType     string    `json:"trans_type"`
-	Amount        float64   `json:"amount"`
-	CategoryID    int       `json:"category_id"`
-	StatusID      int       `json:"status_id"`
-	SenderBank    string    `json:"sender_bank"`
-	ReceiverINN   string    `json:"receiver_inn"`
-	ReceiverPhone string    `json:"receiver_phone"`
-	Comment       string    `json:"comment"`
+	ID             int       `json:"id"`
+	UserType       string    `json:"user_type"`
+	DateTime       time.Time `json:"date_time"`
+	TransType      string    `json:"trans_type"`
+	Amount         float64   `json:"amount"`
+	CategoryID     int       `json:"category_id"`
+	StatusID       int       `json:"status_id"`
+	SenderBank     string    `json:"sender_bank"`
+	SenderBankBIC  string    `json:"sender_bank_bic" validate:"omitempty,bic"`
+	SenderBankName string    `json:"sender_bank_name"`
+	CounterpartyID int       `json:"counterparty_id"`
+	ReceiverINN    string    `json:"receiver_inn" validate:"omitempty,inn"`
+	ReceiverPhone  string    `json:"receiver_phone" validate:"omitempty,ru_phone"`
+	Comment        string    `json:"comment"`
+}
+
+// ReceiptImport — импорт кассового чека по строке из его QR-кода.
+type ReceiptImport struct {
+	QR         string `json:"qr" validate:"required,max=1024"`        // Строка из QR-кода чека
+	UserType   string `json:"user_type" validate:"required"`          // ФЛ или ЮЛ
+	SellerINN  string `json:"seller_inn" validate:"omitempty,inn"`    // ИНН продавца, если известен
+	CategoryID int    `json:"category_id" validate:"omitempty,min=1"` // Категория; по умолчанию подбирается по продавцу
+	Comment    string `json:"comment"`
+}
+
+type FiscalReceipt struct {
+	FiscalDrive    string    `json:"fiscal_drive"`    // ФН
+	FiscalDocument string    `json:"fiscal_document"` // ФД
+	FiscalSign     string    `json:"fiscal_sign"`     // ФП
+	Operation      int       `json:"operation"`       // Признак расчета: 1 — приход, 2 — возврат прихода, 3 — расход, 4 — возврат расхода
+	Amount         float64   `json:"amount"`
+	DateTime       time.Time `json:"date_time"`
+	SellerINN      string    `json:"seller_inn"`
+}
+
+type ReceiptImportResponse struct {
+	Transaction       Transaction   `json:"transaction"`
+	Receipt           FiscalReceipt `json:"receipt"`
+	CategorySuggested bool          `json:"category_suggested"` // Категория подобрана по прежним транзакциям продавца
 }
 
 type Category struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-	Type string `json:"type"` // credit или debit
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`                // credit или debit
+	ParentID int    `json:"parent_id,omitempty"` // ID родительской категории
+}
+
+// CategoryNode — категория с подкатегориями.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
+
+// CategoryParentRequest — перенос категории; parent_id = null или 0 — на верхний уровень.
+type CategoryParentRequest struct {
+	ParentID *int `json:"parent_id"`
 }
 
 type TransactionStatus struct {
@@ -69,6 +127,7 @@ type CategorySummaryResponse struct {
 }
 
 type BankSummaryResponse struct {
+	BIC    string  `json:"bic"`
 	Bank   string  `json:"bank"`
 	Count  int     `json:"count"`
 	Amount float64 `json:"amount"`
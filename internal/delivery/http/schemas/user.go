@@ -1,6 +1,9 @@
 package schemas
 
-import "finance-backend/internal/domain"
+import (
+	"finance-backend/internal/domain"
+	"time"
+)
 
 type UserRegistrationSchema struct {
 	UserType domain.UserType `json:"userType" validate:"required,oneof=ФЛ ЮЛ"`
@@ -8,9 +11,12 @@ type UserRegistrationSchema struct {
 	Name     string          `json:"partName" validate:"required,min=3,max=50"`
 	Password string          `json:"password" validate:"required,min=6"`
 	Bank     string          `json:"bank" validate:"required"`
-	Account  string          `json:"account" validate:"required,len=20,numeric"`
-	INN      string          `json:"inn" validate:"required,len=11,numeric"`
-	Phone    string          `json:"phone" validate:"required,e164"`
+	BIC      string          `json:"bic" validate:"required,bic"`
+	Account  string          `json:"account" validate:"required,len=20,numeric,account_key=BIC"`
+	INN      string          `json:"inn" validate:"required,inn"`
+	KPP      string          `json:"kpp" validate:"omitempty,kpp,excluded_unless=UserType ЮЛ"`
+	Phone    string          `json:"phone" validate:"required,ru_phone"`
+	Email    string          `json:"email" validate:"omitempty,email,max=255"`
 }
 
 func (us *UserRegistrationSchema) ToDomainEntity() *domain.UserCreationData {
@@ -20,9 +26,12 @@ func (us *UserRegistrationSchema) ToDomainEntity() *domain.UserCreationData {
 		Name:     us.Name,
 		Password: us.Password,
 		Bank:     us.Bank,
+		BIC:      us.BIC,
 		Account:  us.Account,
 		INN:      us.INN,
+		KPP:      us.KPP,
 		Phone:    us.Phone,
+		Email:    us.Email,
 	}
 }
 
@@ -30,3 +39,115 @@ type UserLoginSchema struct {
 	Login    string `json:"loginName" validate:"required,min=3,max=50"`
 	Password string `json:"password" validate:"required,min=6"`
 }
+
+type TokenPairSchema struct {
+	Token                 string    `json:"token"`
+	TokenExpiresAt        time.Time `json:"tokenExpiresAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+
+	PasswordChangeRequired bool `json:"passwordChangeRequired,omitempty"`
+}
+
+func NewTokenPairSchema(pair *domain.TokenPair) TokenPairSchema {
+	return TokenPairSchema{
+		Token:                 pair.AccessToken.String(),
+		TokenExpiresAt:        pair.AccessTokenExpiresAt,
+		RefreshToken:          pair.RefreshToken,
+		RefreshTokenExpiresAt: pair.RefreshTokenExpiresAt,
+
+		PasswordChangeRequired: pair.PasswordChangeRequired,
+	}
+}
+
+type RefreshTokenSchema struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type LogoutSchema struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type UserAccountResponse struct {
+	Login                 string     `json:"loginName"`
+	Role                  string     `json:"role"`
+	UserType              string     `json:"userType"`
+	Name                  string     `json:"partName"`
+	INN                   string     `json:"inn"`
+	Phone                 string     `json:"phone"`
+	Email                 string     `json:"email"`
+	Blocked               bool       `json:"blocked"`
+	BlockedAt             *time.Time `json:"blockedAt"`
+	PasswordResetRequired bool       `json:"passwordResetRequired"`
+}
+
+type ChangeUserRoleSchema struct {
+	Role domain.Role `json:"role" validate:"required,oneof=viewer user accountant admin"`
+}
+
+type TemporaryPasswordSchema struct {
+	TemporaryPassword string `json:"temporaryPassword"`
+}
+
+type PasswordResetRequestSchema struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type PasswordResetSchema struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type ChangePasswordSchema struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required,min=6"`
+}
+
+// MFAChallengeSchema — ответ на вход с паролем, когда нужен второй фактор.
+type MFAChallengeSchema struct {
+	MFARequired       bool      `json:"mfaRequired"`
+	MFAToken          string    `json:"mfaToken"`
+	MFATokenExpiresAt time.Time `json:"mfaTokenExpiresAt"`
+}
+
+type MFALoginSchema struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type MFAStatusSchema struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recoveryCodesLeft"`
+}
+
+type TOTPEnrollmentSchema struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+	QRCode     string `json:"qrCode"` // data:image/png;base64,...
+}
+
+type MFACodeSchema struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableMFASchema struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type RecoveryCodesSchema struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// OIDCAuthorizationSchema — адрес страницы входа провайдера. state вернется
+// в OIDC_REDIRECT_URL вместе с code; фронтенд передает оба в /oidc/callback.
+type OIDCAuthorizationSchema struct {
+	AuthorizationURL string    `json:"authorizationUrl"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
+type OIDCCallbackSchema struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
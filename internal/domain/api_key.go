@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// APIKeyPrefix отличает ключ API от JWT в заголовке Authorization.
+const APIKeyPrefix = "fin_"
+
+// APIKeyScope — область доступа ключа API. Ключ дает права только в пределах своих
+// областей и только те, что есть у роли владельца.
+type APIKeyScope string
+
+const (
+	APIKeyScopeTransactionsRead  APIKeyScope = "transactions:read"
+	APIKeyScopeTransactionsWrite APIKeyScope = "transactions:write"
+	APIKeyScopeAnalyticsRead     APIKeyScope = "analytics:read"
+)
+
+// Чтение операций и аналитики без справочников бесполезно, поэтому области
+// включают и чтение справочников.
+var apiKeyScopePermissions = map[APIKeyScope][]Permission{
+	APIKeyScopeTransactionsRead:  {PermTransactionsRead, PermReferencesRead, PermCounterpartiesRead},
+	APIKeyScopeTransactionsWrite: {PermTransactionsWrite},
+	APIKeyScopeAnalyticsRead:     {PermAnalyticsRead, PermReferencesRead},
+}
+
+func (s APIKeyScope) IsValid() bool {
+	_, ok := apiKeyScopePermissions[s]
+	return ok
+}
+
+func (APIKeyScope) Values() []APIKeyScope {
+	return []APIKeyScope{APIKeyScopeTransactionsRead, APIKeyScopeTransactionsWrite, APIKeyScopeAnalyticsRead}
+}
+
+// Permissions возвращает права, которые дает область.
+func (s APIKeyScope) Permissions() []Permission {
+	return apiKeyScopePermissions[s]
+}
+
+// APIKeyPermissions объединяет права областей ключа.
+func APIKeyPermissions(scopes []APIKeyScope) []Permission {
+	perms := make([]Permission, 0, len(scopes)*2)
+	for _, scope := range scopes {
+		perms = append(perms, scope.Permissions()...)
+	}
+	return perms
+}
+
+// APIKey — персональный ключ API. Сам ключ не хранится, только его хеш и начало (Prefix),
+// по которому пользователь узнает ключ в списке.
+type APIKey struct {
+	ID         int64
+	Login      string
+	Name       string
+	Prefix     string
+	Scopes     []APIKeyScope
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+type APIKeyCreationData struct {
+	Name      string
+	Scopes    []APIKeyScope
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey — только что выпущенный ключ; Key показывается один раз.
+type CreatedAPIKey struct {
+	APIKey
+	Key string
+}
+
+// APIKeyOwner — действующий ключ вместе с ролью и статусом владельца.
+type APIKeyOwner struct {
+	Key     APIKey
+	Role    Role
+	Blocked bool
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Attachment — файл (чек, документ), прикрепленный к транзакции и хранящийся в файловом хранилище.
+type Attachment struct {
+	ID            int64     `db:"id"`
+	TransactionID int64     `db:"transaction_id"`
+	Bucket        string    `db:"bucket"`
+	ObjectKey     string    `db:"object_key"`
+	FileName      string    `db:"file_name"`
+	ContentType   string    `db:"content_type"`
+	Size          int64     `db:"size"`
+	ETag          string    `db:"etag"`
+	OwnerLogin    string    `db:"owner_login"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// PresignedURL — ссылка для прямой работы клиента с файловым хранилищем.
+type PresignedURL struct {
+	URL         string
+	Method      string
+	ContentType string
+	ExpiresAt   time.Time
+}
+
+// AttachmentUpload — выданная клиенту ссылка на загрузку вложения. Вложение появляется
+// у транзакции только после подтверждения загрузки.
+type AttachmentUpload struct {
+	ObjectKey string
+	PresignedURL
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAction — изменение, записанное в журнал аудита.
+type AuditAction string
+
+const (
+	AuditActionCreate         AuditAction = "create"
+	AuditActionUpdate         AuditAction = "update"
+	AuditActionDelete         AuditAction = "delete"  // для транзакций — перенос в корзину
+	AuditActionRestore        AuditAction = "restore" // возврат из корзины
+	AuditActionPurge          AuditAction = "purge"   // окончательное удаление из корзины
+	AuditActionRoleChange     AuditAction = "role_change"
+	AuditActionBlock          AuditAction = "block"
+	AuditActionUnblock        AuditAction = "unblock"
+	AuditActionPasswordChange AuditAction = "password_change"
+	AuditActionPasswordReset  AuditAction = "password_reset"
+	AuditActionMFAEnable      AuditAction = "mfa_enable"
+	AuditActionMFADisable     AuditAction = "mfa_disable"
+	AuditActionRecoveryCodes  AuditAction = "mfa_recovery_codes"
+	AuditActionLogin          AuditAction = "login"
+	AuditActionLogout         AuditAction = "logout"
+	AuditActionLogoutAll      AuditAction = "logout_all"
+	AuditActionSwitchOrg      AuditAction = "switch_organization"
+	AuditActionClose          AuditAction = "close"     // закрытие учетного периода
+	AuditActionReopen         AuditAction = "reopen"    // открытие закрытого периода
+	AuditActionArchive        AuditAction = "archive"   // перенос категории в архив
+	AuditActionUnarchive      AuditAction = "unarchive" // возврат категории из архива
+	AuditActionMerge          AuditAction = "merge"     // объединение категории с другой
+	// События аутентификации из auth_events пишутся с действием, равным типу события
+	// (login_failed, lockout и т. д.).
+)
+
+// AuditEntityType — тип сущности, к которой относится запись журнала.
+type AuditEntityType string
+
+const (
+	AuditEntityTransaction         AuditEntityType = "transaction"
+	AuditEntityPreparedTransaction AuditEntityType = "prepared_transaction"
+	AuditEntityCategory            AuditEntityType = "category"
+	AuditEntityUser                AuditEntityType = "user" // в том числе вход и выход
+	AuditEntityPeriod              AuditEntityType = "accounting_period"
+)
+
+// AuditEvent — изменение, которое нужно записать в журнал. Before и After сериализуются
+// в JSON: состояние сущности до и после изменения, nil — состояния нет (создание, удаление).
+// Пустой Actor означает пользователя из контекста запроса, OrgID = 0 — его активную организацию.
+type AuditEvent struct {
+	Actor      string
+	Action     AuditAction
+	EntityType AuditEntityType
+	EntityID   string
+	OrgID      int64
+	Before     interface{}
+	After      interface{}
+}
+
+// AuditRecorder пишет изменения в журнал аудита. Идентификатор запроса и адрес клиента
+// берутся из контекста.
+type AuditRecorder interface {
+	Record(ctx context.Context, event AuditEvent)
+}
+
+// AuditEntry — запись журнала аудита. Before и After — JSON, пустая строка — состояния нет.
+type AuditEntry struct {
+	ID         int64           `db:"id"`
+	Actor      string          `db:"actor_login"`
+	APIKeyID   *int64          `db:"api_key_id"` // изменение сделано по ключу API
+	Action     AuditAction     `db:"action"`
+	EntityType AuditEntityType `db:"entity_type"`
+	EntityID   string          `db:"entity_id"`
+	OrgID      *int64          `db:"org_id"`
+	Before     string          `db:"before_data"`
+	After      string          `db:"after_data"`
+	RequestID  string          `db:"request_id"`
+	IP         string          `db:"ip"`
+	CreatedAt  time.Time       `db:"created_at"`
+}
+
+// AuditFilter — условия поиска по журналу. Пустые поля не ограничивают выборку.
+type AuditFilter struct {
+	Actor      *string
+	Action     *string
+	EntityType *string
+	EntityID   *string
+	OrgID      *int64
+	RequestID  *string
+	From       *time.Time
+	To         *time.Time
+}
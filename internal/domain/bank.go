@@ -0,0 +1,13 @@
+package domain
+
+// Bank — участник расчетов из справочника БИК Банка России (ED807).
+type Bank struct {
+	BIC             string
+	Name            string
+	EnglishName     string
+	CorrAccount     string
+	Settlement      string
+	Address         string
+	ParticipantType string
+	IsActive        bool
+}
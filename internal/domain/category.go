@@ -2,9 +2,39 @@ package domain
 
 import "time"
 
+// Типы категорий совпадают с типами транзакций.
+const (
+	CategoryTypeCredit = "credit"
+	CategoryTypeDebit  = "debit"
+)
+
+// Category — категория операций. Категории без владельца образуют общий справочник,
+// остальные принадлежат организации (OrgID) или пользователю (OwnerLogin).
 type Category struct {
-	ID        int64     `db:"id"`
-	Name      string    `db:"name"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID         int64      `db:"id"`
+	Name       string     `db:"name"`
+	Type       string     `db:"type"`
+	ParentID   *int64     `db:"parent_id"`
+	OrgID      *int64     `db:"org_id"`
+	OwnerLogin *string    `db:"owner_login"`
+	ArchivedAt *time.Time `db:"archived_at"` // архивная категория недоступна для новых операций
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+}
+
+// IsShared сообщает, относится ли категория к общему справочнику.
+func (c Category) IsShared() bool {
+	return c.OrgID == nil && c.OwnerLogin == nil
+}
+
+// IsArchived сообщает, что категория в архиве.
+func (c Category) IsArchived() bool {
+	return c.ArchivedAt != nil
+}
+
+// CategoryData — изменяемые поля категории.
+type CategoryData struct {
+	Name     string
+	Type     string
+	ParentID *int64
 }
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Counterparty — получатель платежей, собранный из ИНН и телефона транзакций
+// или заведенный пользователем вручную.
+type Counterparty struct {
+	ID                int64     `db:"id"`
+	Name              string    `db:"name"`
+	INN               *string   `db:"inn"`
+	Phone             *string   `db:"phone"`
+	Bank              *string   `db:"bank"`
+	BankBIC           *string   `db:"bank_bic"`
+	Account           *string   `db:"account"`
+	DefaultCategoryID *int64    `db:"default_category_id"`
+	OrgID             *int64    `db:"org_id"`
+	OwnerLogin        *string   `db:"owner_login"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
+}
+
+type CounterpartyData struct {
+	Name              string
+	INN               *string
+	Phone             *string
+	Bank              *string
+	BankBIC           *string
+	Account           *string
+	DefaultCategoryID *int64
+}
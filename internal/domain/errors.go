@@ -25,6 +25,41 @@ var (
 		Message: "Категория с таким названием уже существует",
 	}
 
+	ErrCategoryCycle = &DomainError{
+		Code:    "CATEGORY_CYCLE",
+		Message: "Категория не может быть вложена в себя или в свою подкатегорию",
+	}
+
+	ErrCategoryParentInvalid = &DomainError{
+		Code:    "CATEGORY_PARENT_INVALID",
+		Message: "Родительская категория должна быть того же типа и принадлежать тому же владельцу или общему справочнику",
+	}
+
+	ErrCategoryTypeInvalid = &DomainError{
+		Code:    "CATEGORY_TYPE_INVALID",
+		Message: "Тип категории должен быть credit или debit",
+	}
+
+	ErrCategoryInUse = &DomainError{
+		Code:    "CATEGORY_IN_USE",
+		Message: "Категория используется в операциях или имеет подкатегории: ее можно архивировать или объединить с другой",
+	}
+
+	ErrCategoryArchived = &DomainError{
+		Code:    "CATEGORY_ARCHIVED",
+		Message: "Категория в архиве",
+	}
+
+	ErrCategoryTypeMismatch = &DomainError{
+		Code:    "CATEGORY_TYPE_MISMATCH",
+		Message: "Тип категории не совпадает с типом операции",
+	}
+
+	ErrCategoryMergeInvalid = &DomainError{
+		Code:    "CATEGORY_MERGE_INVALID",
+		Message: "Объединять можно только с другой действующей категорией того же типа, не вложенной в объединяемую",
+	}
+
 	ErrArticleNotFound = &DomainError{
 		Code:    "ARTICLE_NOT_FOUND",
 		Message: "Статья не найдена",
@@ -50,10 +85,270 @@ var (
 		Message: "Пользователь уже зарегистрирован",
 	}
 
+	ErrEmailAlreadyUsed = &DomainError{
+		Code:    "EMAIL_EXISTS_ERROR",
+		Message: "Email уже используется другим пользователем",
+	}
+
+	ErrUserNotFound = &DomainError{
+		Code:    "USER_NOT_FOUND",
+		Message: "Пользователь не найден",
+	}
+
+	ErrUserBlocked = &DomainError{
+		Code:    "USER_BLOCKED",
+		Message: "Учетная запись заблокирована",
+	}
+
+	ErrRoleInvalid = &DomainError{
+		Code:    "ROLE_INVALID",
+		Message: "Неизвестная роль пользователя",
+	}
+
+	ErrOwnAccountModification = &DomainError{
+		Code:    "OWN_ACCOUNT_MODIFICATION",
+		Message: "Нельзя изменить роль или заблокировать собственную учетную запись",
+	}
+
+	ErrInvalidPasswordResetToken = &DomainError{
+		Code:    "PASSWORD_RESET_TOKEN_INVALID",
+		Message: "Ссылка для сброса пароля недействительна или устарела, запросите новую",
+	}
+
+	ErrWrongPassword = &DomainError{
+		Code:    "CURRENT_PASSWORD_INVALID",
+		Message: "Неверный текущий пароль",
+	}
+
+	ErrAPIKeyNotFound = &DomainError{
+		Code:    "API_KEY_NOT_FOUND",
+		Message: "Ключ API не найден",
+	}
+
+	ErrAPIKeyScopeInvalid = &DomainError{
+		Code:    "API_KEY_SCOPE_INVALID",
+		Message: "Неизвестная область доступа ключа API",
+	}
+
+	ErrAPIKeyScopeNotAllowed = &DomainError{
+		Code:    "API_KEY_SCOPE_NOT_ALLOWED",
+		Message: "Роль пользователя не дает прав для этой области доступа",
+	}
+
+	ErrAPIKeyExpiresInPast = &DomainError{
+		Code:    "API_KEY_EXPIRES_IN_PAST",
+		Message: "Срок действия ключа API должен быть в будущем",
+	}
+
+	ErrOIDCDisabled = &DomainError{
+		Code:    "OIDC_DISABLED",
+		Message: "Вход через внешнего провайдера не настроен",
+	}
+
+	ErrOIDCStateInvalid = &DomainError{
+		Code:    "OIDC_STATE_INVALID",
+		Message: "Вход устарел или уже завершен, начните заново",
+	}
+
+	ErrOIDCLoginFailed = &DomainError{
+		Code:    "OIDC_LOGIN_FAILED",
+		Message: "Провайдер не подтвердил вход",
+	}
+
+	ErrOIDCUserNotProvisioned = &DomainError{
+		Code:    "OIDC_USER_NOT_PROVISIONED",
+		Message: "Для этой учетной записи провайдера нет пользователя, обратитесь к администратору",
+	}
+
+	ErrOrganizationNotFound = &DomainError{
+		Code:    "ORGANIZATION_NOT_FOUND",
+		Message: "Организация не найдена",
+	}
+
+	ErrOrganizationINNExists = &DomainError{
+		Code:    "ORGANIZATION_INN_EXISTS",
+		Message: "Организация с таким ИНН уже зарегистрирована",
+	}
+
+	ErrOrgMemberNotFound = &DomainError{
+		Code:    "ORG_MEMBER_NOT_FOUND",
+		Message: "Пользователь не состоит в организации",
+	}
+
+	ErrOrgRoleInvalid = &DomainError{
+		Code:    "ORG_ROLE_INVALID",
+		Message: "Неизвестная роль в организации",
+	}
+
+	ErrOrgLastOwner = &DomainError{
+		Code:    "ORG_LAST_OWNER",
+		Message: "В организации должен остаться хотя бы один владелец",
+	}
+
+	ErrOrgAlreadyMember = &DomainError{
+		Code:    "ORG_ALREADY_MEMBER",
+		Message: "Пользователь уже состоит в организации",
+	}
+
+	ErrOrgInvitationNotFound = &DomainError{
+		Code:    "ORG_INVITATION_NOT_FOUND",
+		Message: "Приглашение не найдено",
+	}
+
+	ErrOrgInvitationInvalid = &DomainError{
+		Code:    "ORG_INVITATION_INVALID",
+		Message: "Приглашение недействительно или устарело, попросите прислать новое",
+	}
+
+	ErrOrgInvitationEmailMismatch = &DomainError{
+		Code:    "ORG_INVITATION_EMAIL_MISMATCH",
+		Message: "Приглашение отправлено на другой email",
+	}
+
+	ErrTooManyAttempts = &DomainError{
+		Code:    "TOO_MANY_ATTEMPTS",
+		Message: "Слишком много попыток, повторите позже",
+	}
+
+	ErrMFAChallengeInvalid = &DomainError{
+		Code:    "MFA_CHALLENGE_INVALID",
+		Message: "Время на ввод кода истекло, выполните вход заново",
+	}
+
+	ErrMFACodeInvalid = &DomainError{
+		Code:    "MFA_CODE_INVALID",
+		Message: "Неверный код подтверждения",
+	}
+
+	ErrMFAAlreadyEnabled = &DomainError{
+		Code:    "MFA_ALREADY_ENABLED",
+		Message: "Двухфакторная аутентификация уже подключена",
+	}
+
+	ErrMFANotEnabled = &DomainError{
+		Code:    "MFA_NOT_ENABLED",
+		Message: "Двухфакторная аутентификация не подключена",
+	}
+
+	ErrMFAEnrollmentNotStarted = &DomainError{
+		Code:    "MFA_ENROLLMENT_NOT_STARTED",
+		Message: "Сначала получите секрет для приложения-аутентификатора",
+	}
+
+	ErrInvalidRefreshToken = &DomainError{
+		Code:    "INVALID_REFRESH_TOKEN",
+		Message: "Refresh-токен недействителен или истек, выполните вход заново",
+	}
+
 	ErrS3Connection = &DomainError{
 		Code:    "S3_CONNECTION_ERROR",
 		Message: "Не удается подключиться к файловому хранилищу",
 	}
 
+	ErrCounterpartyNotFound = &DomainError{
+		Code:    "COUNTERPARTY_NOT_FOUND",
+		Message: "Контрагент не найден",
+	}
+
+	ErrCounterpartyExists = &DomainError{
+		Code:    "COUNTERPARTY_EXISTS",
+		Message: "Контрагент с таким ИНН или телефоном уже существует",
+	}
+
+	ErrCounterpartyCategoryInvalid = &DomainError{
+		Code:    "COUNTERPARTY_CATEGORY_INVALID",
+		Message: "Категория по умолчанию не найдена или перенесена в архив",
+	}
+
+	ErrTransactionNotFound = &DomainError{
+		Code:    "TRANSACTION_NOT_FOUND",
+		Message: "Транзакция не найдена",
+	}
+
+	ErrPeriodClosed = &DomainError{
+		Code:    "PERIOD_CLOSED",
+		Message: "Учетный период закрыт: транзакции с датой в нем нельзя добавлять, изменять и удалять",
+	}
+
+	ErrPeriodAlreadyClosed = &DomainError{
+		Code:    "PERIOD_ALREADY_CLOSED",
+		Message: "Учетный период уже закрыт",
+	}
+
+	ErrPeriodNotClosed = &DomainError{
+		Code:    "PERIOD_NOT_CLOSED",
+		Message: "Учетный период не закрыт",
+	}
+
+	ErrPeriodInvalid = &DomainError{
+		Code:    "PERIOD_INVALID",
+		Message: "Период указывается в формате ГГГГ-ММ",
+	}
+
+	ErrAttachmentNotFound = &DomainError{
+		Code:    "ATTACHMENT_NOT_FOUND",
+		Message: "Вложение не найдено",
+	}
+
+	ErrAttachmentTooLarge = &DomainError{
+		Code:    "ATTACHMENT_TOO_LARGE",
+		Message: "Размер файла превышает допустимый",
+	}
+
+	ErrAttachmentTypeNotAllowed = &DomainError{
+		Code:    "ATTACHMENT_TYPE_NOT_ALLOWED",
+		Message: "Допустимы только файлы JPEG, PNG и PDF",
+	}
+
+	ErrAttachmentUploadNotFound = &DomainError{
+		Code:    "ATTACHMENT_UPLOAD_NOT_FOUND",
+		Message: "Загруженный файл не найден в хранилище",
+	}
+
+	ErrAttachmentUploadInvalid = &DomainError{
+		Code:    "ATTACHMENT_UPLOAD_INVALID",
+		Message: "Ключ загрузки не относится к этой транзакции или пользователю",
+	}
+
+	ErrReceiptInvalid = &DomainError{
+		Code:    "RECEIPT_INVALID",
+		Message: "Строка не является QR-кодом кассового чека",
+	}
+
+	ErrReceiptAlreadyImported = &DomainError{
+		Code:    "RECEIPT_ALREADY_IMPORTED",
+		Message: "Чек уже импортирован",
+	}
+
+	ErrReceiptCategoryRequired = &DomainError{
+		Code:    "RECEIPT_CATEGORY_REQUIRED",
+		Message: "Не удалось подобрать категорию по продавцу, укажите category_id",
+	}
+
+	ErrReceiptQRNotFound = &DomainError{
+		Code:    "RECEIPT_QR_NOT_FOUND",
+		Message: "Не удалось распознать QR-код на изображении",
+	}
+
+	ErrPaymentQRNotOutgoing = &DomainError{
+		Code:    "PAYMENT_QR_NOT_OUTGOING",
+		Message: "QR-код для оплаты формируется только для исходящих платежей",
+	}
+
+	ErrPaymentRequisitesMissing = &DomainError{
+		Code:    "PAYMENT_REQUISITES_MISSING",
+		Message: "У получателя не заполнены реквизиты для оплаты: ИНН, расчетный счет и БИК банка",
+	}
+
+	ErrPaymentAmountInvalid = &DomainError{
+		Code:    "PAYMENT_AMOUNT_INVALID",
+		Message: "Сумма платежа должна быть больше нуля",
+	}
+
+	ErrForbidden = &DomainError{
+		Code:    "FORBIDDEN",
+		Message: "Недостаточно прав для выполнения операции",
+	}
+
 	ErrNotFound = errors.New("entity not found")
 )
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// ThrottleScope — по чему считаются неудачные попытки.
+type ThrottleScope string
+
+const (
+	ThrottleScopeLogin        ThrottleScope = "login"        // неудачные входы в учетную запись
+	ThrottleScopeIP           ThrottleScope = "ip"           // неудачные входы с адреса
+	ThrottleScopeRegistration ThrottleScope = "registration" // регистрации с адреса
+)
+
+// ThrottleKey — счетчик попыток: логин или IP-адрес в своей области.
+type ThrottleKey struct {
+	Scope ThrottleScope
+	Value string
+}
+
+// AuthEventType — тип события аутентификации в журнале auth_events.
+type AuthEventType string
+
+const (
+	AuthEventLoginFailed           AuthEventType = "login_failed"
+	AuthEventLoginThrottled        AuthEventType = "login_throttled" // попытка отклонена без проверки пароля
+	AuthEventLockout               AuthEventType = "lockout"         // логин или адрес временно заблокирован
+	AuthEventRegistrationThrottled AuthEventType = "registration_throttled"
+)
+
+// AuthEvent — запись журнала аутентификации. Login может не соответствовать
+// существующему пользователю: неудачные входы по несуществующим логинам тоже пишутся.
+type AuthEvent struct {
+	ID        int64         `db:"id"`
+	Type      AuthEventType `db:"event_type"`
+	Login     string        `db:"login_name"`
+	IP        string        `db:"ip"`
+	RequestID string        `db:"request_id"`
+	Details   string        `db:"details"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+// TooManyAttemptsError — попытка отклонена ограничителем; RetryAfter — когда можно повторить.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *TooManyAttemptsError) Unwrap() error {
+	return ErrTooManyAttempts
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// TOTPState — состояние двухфакторной аутентификации пользователя.
+// Secret заполнен и EnabledAt пуст, пока подключение не подтверждено кодом из приложения.
+type TOTPState struct {
+	Login             string     `db:"login_name"`
+	Secret            string     `db:"totp_secret"`
+	EnabledAt         *time.Time `db:"totp_enabled_at"`
+	RecoveryCodesLeft int        `db:"recovery_codes_left"`
+}
+
+func (s *TOTPState) Enabled() bool {
+	return s != nil && s.EnabledAt != nil
+}
+
+// TOTPEnrollment — данные для подключения приложения-аутентификатора.
+type TOTPEnrollment struct {
+	Secret     string
+	OTPAuthURI string
+}
+
+// MFAChallenge — незавершенный вход: пароль проверен, ожидается код второго фактора.
+// Сам токен входа не хранится, только его хеш.
+type MFAChallenge struct {
+	ID        int64     `db:"id"`
+	Login     string    `db:"login_name"`
+	TokenHash string    `db:"token_hash"`
+	ExpiresAt time.Time `db:"expires_at"`
+	Attempts  int       `db:"attempts"`
+}
+
+// LoginResult — результат проверки пароля: либо пара токенов, либо токен для второго шага входа.
+type LoginResult struct {
+	Tokens          *TokenPair
+	MFAToken        string
+	MFATokenExpires time.Time
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// OIDCIdentity — данные пользователя из ID-токена провайдера.
+type OIDCIdentity struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// OIDCLoginState — начатый вход через провайдера. Хранится до возврата пользователя
+// с кодом авторизации; сам state хранится только хешем.
+type OIDCLoginState struct {
+	StateHash    string    `db:"state_hash"`
+	CodeVerifier string    `db:"code_verifier"`
+	Nonce        string    `db:"nonce"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+// OIDCAuthorization — адрес страницы входа провайдера и state, который фронтенд
+// сверяет при возврате пользователя.
+type OIDCAuthorization struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// OrgRole — роль участника организации. Ограничивает права глобальной роли
+// пользователя, пока в токене активна организация.
+type OrgRole string
+
+const (
+	OrgRoleOwner      OrgRole = "owner"      // все права, включая назначение владельцев
+	OrgRoleAdmin      OrgRole = "admin"      // управление участниками и приглашениями
+	OrgRoleAccountant OrgRole = "accountant" // операции, категории и учетные периоды организации
+	OrgRoleMember     OrgRole = "member"     // операции организации
+	OrgRoleViewer     OrgRole = "viewer"     // только просмотр
+)
+
+// Права, которые зависят от роли в организации. Остальные права (чтение, справочники,
+// управление пользователями сервиса) определяются только глобальной ролью.
+var orgRolePermissions = map[OrgRole][]Permission{
+	OrgRoleOwner:      {PermTransactionsWrite, PermCategoriesWrite, PermPeriodsManage},
+	OrgRoleAdmin:      {PermTransactionsWrite, PermCategoriesWrite, PermPeriodsManage},
+	OrgRoleAccountant: {PermTransactionsWrite, PermCategoriesWrite, PermPeriodsManage},
+	OrgRoleMember:     {PermTransactionsWrite},
+	OrgRoleViewer:     {},
+}
+
+func (r OrgRole) IsValid() bool {
+	_, ok := orgRolePermissions[r]
+	return ok
+}
+
+func (r OrgRole) String() string {
+	return string(r)
+}
+
+func (OrgRole) Values() []OrgRole {
+	return []OrgRole{OrgRoleOwner, OrgRoleAdmin, OrgRoleAccountant, OrgRoleMember, OrgRoleViewer}
+}
+
+// Can сообщает, не запрещает ли роль в организации право p.
+func (r OrgRole) Can(p Permission) bool {
+	if !r.IsValid() {
+		return false
+	}
+	if p != PermTransactionsWrite && p != PermCategoriesWrite && p != PermPeriodsManage {
+		return true
+	}
+	for _, granted := range orgRolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageMembers — приглашать, удалять участников и менять их роли.
+func (r OrgRole) CanManageMembers() bool {
+	return r == OrgRoleOwner || r == OrgRoleAdmin
+}
+
+// Organization — юридическое лицо с общим для участников учетом.
+// Role — роль текущего пользователя в организации, если она запрошена от его имени.
+type Organization struct {
+	ID        int64     `db:"id"`
+	Name      string    `db:"name"`
+	INN       *string   `db:"inn"`
+	CreatedBy string    `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+	Role      OrgRole   `db:"role"`
+}
+
+type OrganizationData struct {
+	Name string
+	INN  *string
+}
+
+type OrgMember struct {
+	OrgID    int64     `db:"org_id"`
+	Login    string    `db:"login_name"`
+	Name     string    `db:"name"`
+	Email    string    `db:"email"`
+	Role     OrgRole   `db:"role"`
+	JoinedAt time.Time `db:"joined_at"`
+}
+
+// OrgInvitation — приглашение в организацию по email. Сам токен из письма не хранится,
+// только его хеш.
+type OrgInvitation struct {
+	ID         int64      `db:"id"`
+	OrgID      int64      `db:"org_id"`
+	OrgName    string     `db:"org_name"`
+	Email      string     `db:"email"`
+	Role       OrgRole    `db:"role"`
+	InvitedBy  string     `db:"invited_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	AcceptedAt *time.Time `db:"accepted_at"`
+}
+
+type OrgInvitationData struct {
+	Email string
+	Role  OrgRole
+}
+
+// NormalizeEmail приводит адрес к виду, в котором он сравнивается с адресами пользователей.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// DataScope — чьи данные видит запрос: организации (OrgID) или личные данные пользователя.
+type DataScope struct {
+	OrgID int64
+	Login string
+}
+
+// ScopeOf возвращает область данных пользователя с учетом активной организации.
+func ScopeOf(u User) DataScope {
+	if u.OrgID != 0 {
+		return DataScope{OrgID: u.OrgID}
+	}
+	return DataScope{Login: u.Login}
+}
+
+// IsOrganization сообщает, что запрос работает с данными организации.
+func (s DataScope) IsOrganization() bool {
+	return s.OrgID != 0
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// PeriodMonthLayout — формат учетного периода в API: год и месяц.
+const PeriodMonthLayout = "2006-01"
+
+// AccountingPeriod — учетный период (календарный месяц по UTC) организации или личного
+// учета. Пока период закрыт, транзакции с датой в нем нельзя добавлять, изменять и удалять.
+// Запись появляется при первом закрытии и хранит, кто закрыл и кто последним открыл период.
+type AccountingPeriod struct {
+	ID         int64      `db:"id"`
+	Month      time.Time  `db:"month"` // первое число месяца
+	Closed     bool       `db:"closed"`
+	ClosedBy   string     `db:"closed_by"`
+	ClosedAt   time.Time  `db:"closed_at"`
+	ReopenedBy string     `db:"reopened_by"`
+	ReopenedAt *time.Time `db:"reopened_at"`
+}
+
+// PeriodMonth возвращает первое число месяца по UTC, к которому относится момент t.
+func PeriodMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ParsePeriodMonth разбирает период в формате ГГГГ-ММ.
+func ParsePeriodMonth(value string) (time.Time, error) {
+	month, err := time.Parse(PeriodMonthLayout, value)
+	if err != nil {
+		return time.Time{}, ErrPeriodInvalid
+	}
+	return month, nil
+}
@@ -0,0 +1,80 @@
+package domain
+
+// Role — роль пользователя (столбец users.role).
+type Role string
+
+const (
+	RoleViewer     Role = "viewer"     // только просмотр
+	RoleUser       Role = "user"       // ведение своих операций
+	RoleAccountant Role = "accountant" // закрытие периодов
+	RoleAdmin      Role = "admin"      // управление пользователями
+)
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) String() string {
+	return string(r)
+}
+
+func (Role) Values() []Role {
+	return []Role{RoleViewer, RoleUser, RoleAccountant, RoleAdmin}
+}
+
+// Permission — право на группу операций API.
+type Permission string
+
+const (
+	PermTransactionsRead    Permission = "transactions:read"
+	PermTransactionsWrite   Permission = "transactions:write"
+	PermReferencesRead      Permission = "references:read"  // категории, статусы, справочник банков
+	PermCategoriesWrite     Permission = "categories:write" // свои категории пользователя или организации
+	PermCounterpartiesRead  Permission = "counterparties:read"
+	PermCounterpartiesWrite Permission = "counterparties:write"
+	PermAnalyticsRead       Permission = "analytics:read"
+	PermPeriodsManage       Permission = "periods:manage" // закрытие и открытие учетных периодов
+	PermUsersManage         Permission = "users:manage"
+	PermAuditRead           Permission = "audit:read"
+)
+
+// Права ролей накопительные: каждая следующая роль получает права предыдущей.
+var (
+	viewerPermissions = []Permission{
+		PermTransactionsRead,
+		PermReferencesRead,
+		PermCounterpartiesRead,
+		PermAnalyticsRead,
+	}
+	userPermissions       = extendPermissions(viewerPermissions, PermTransactionsWrite, PermCounterpartiesWrite, PermCategoriesWrite)
+	accountantPermissions = extendPermissions(userPermissions, PermPeriodsManage)
+	adminPermissions      = extendPermissions(accountantPermissions, PermUsersManage, PermAuditRead)
+
+	rolePermissions = map[Role][]Permission{
+		RoleViewer:     viewerPermissions,
+		RoleUser:       userPermissions,
+		RoleAccountant: accountantPermissions,
+		RoleAdmin:      adminPermissions,
+	}
+)
+
+// Permissions возвращает права роли. У неизвестной роли прав нет.
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// Can сообщает, есть ли у роли право p.
+func (r Role) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+func extendPermissions(base []Permission, extra ...Permission) []Permission {
+	result := make([]Permission, 0, len(base)+len(extra))
+	return append(append(result, base...), extra...)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// TokenPair — токен доступа и refresh-токен, выдаваемые при входе и обновлении.
+type TokenPair struct {
+	AccessToken           AccessToken
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+
+	// PasswordChangeRequired — администратор сбросил пароль, пользователь должен задать новый.
+	PasswordChangeRequired bool
+}
+
+// RefreshToken — запись о выданном refresh-токене. Сам токен не хранится, только его хеш.
+// Токены одной цепочки обновлений имеют общий FamilyID: повторное предъявление
+// уже использованного токена отзывает всю цепочку.
+type RefreshToken struct {
+	ID            int64      `db:"id"`
+	Login         string     `db:"login_name"`
+	TokenHash     string     `db:"token_hash"`
+	FamilyID      string     `db:"family_id"`
+	AccessTokenID string     `db:"access_token_id"` // jti токена доступа, выданного вместе с этим токеном
+	OrgID         int64      `db:"org_id"`          // активная организация сессии, 0 — личные данные
+	ExpiresAt     time.Time  `db:"expires_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	RevokedAt     *time.Time `db:"revoked_at"`
+}
+
+// TokenClaims — данные токена доступа, по которому пришел запрос.
+type TokenClaims struct {
+	ID        string
+	Login     string
+	OrgID     int64 // активная организация сессии, 0 — личный учет
+	ExpiresAt time.Time
+}
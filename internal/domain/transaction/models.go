@@ -18,6 +18,8 @@ type Transaction struct {
 	CategoryID        int       `db:"category_id"`
 	StatusID          int       `db:"status_id"`
 	SenderBank        string    `db:"sender_bank"`
+	SenderBankBIC     string    `db:"sender_bank_bic"`
+	CounterpartyID    int       `db:"counterparty_id"`
 	ReceiverINN       string    `db:"receiver_inn"`
 	ReceiverPhone     string    `db:"receiver_phone"`
 	Comment           string    `db:"comment"`
@@ -25,6 +27,12 @@ type Transaction struct {
 	CategoryType      string    `db:"category_type"`
 	StatusName        string    `db:"status_name"`
 	StatusDescription string    `db:"status_description"`
+	// Владелец записи: организация или пользователь (личный учет), заполняется при создании.
+	OrgID      int64  `db:"org_id"`
+	OwnerLogin string `db:"owner_login"`
+	// DeletedAt и DeletedBy заполнены у транзакций в корзине.
+	DeletedAt *time.Time `db:"deleted_at"`
+	DeletedBy string     `db:"deleted_by"`
 }
 
 type PreparedTransaction struct {
@@ -36,6 +44,8 @@ type PreparedTransaction struct {
 	CategoryID        int       `db:"category_id"`
 	StatusID          int       `db:"status_id"`
 	SenderBank        string    `db:"sender_bank"`
+	SenderBankBIC     string    `db:"sender_bank_bic"`
+	CounterpartyID    int       `db:"counterparty_id"`
 	ReceiverINN       string    `db:"receiver_inn"`
 	ReceiverPhone     string    `db:"receiver_phone"`
 	Comment           string    `db:"comment"`
@@ -43,12 +53,44 @@ type PreparedTransaction struct {
 	CategoryType      string    `db:"category_type"`
 	StatusName        string    `db:"status_name"`
 	StatusDescription string    `db:"status_description"`
+	OrgID             int64     `db:"-"`
+	OwnerLogin        string    `db:"-"`
+}
+
+// FiscalReceipt — реквизиты кассового чека, по которому создана транзакция.
+// Тройка ФН/ФД/ФП однозначно определяет чек и служит для защиты от повторного импорта.
+type FiscalReceipt struct {
+	ID             int       `db:"id"`
+	TransactionID  int       `db:"transaction_id"`
+	FiscalDrive    string    `db:"fiscal_drive"`
+	FiscalDocument string    `db:"fiscal_document"`
+	FiscalSign     string    `db:"fiscal_sign"`
+	Operation      int       `db:"operation"`
+	Amount         float64   `db:"amount"`
+	ReceiptTime    time.Time `db:"receipt_time"`
+	SellerINN      string    `db:"seller_inn"`
 }
 
 type Category struct {
 	ID   int    `db:"id"`
 	Name string `db:"name"`
 	Type string `db:"type"`
+	// ParentID — родительская категория, 0 у категорий верхнего уровня.
+	ParentID int `db:"parent_id"`
+	// Владелец: организация или пользователь; у общего справочника оба поля пустые.
+	OrgID      int64      `db:"org_id"`
+	OwnerLogin string     `db:"owner_login"`
+	ArchivedAt *time.Time `db:"archived_at"`
+}
+
+// IsShared сообщает, относится ли категория к общему справочнику.
+func (c Category) IsShared() bool {
+	return c.OrgID == 0 && c.OwnerLogin == ""
+}
+
+// IsArchived сообщает, перенесена ли категория в архив.
+func (c Category) IsArchived() bool {
+	return c.ArchivedAt != nil
 }
 
 type TransactionStatus struct {
@@ -58,13 +100,26 @@ type TransactionStatus struct {
 }
 
 type TransactionFilter struct {
-	UserType      string
-	TransType     string
-	SenderBank    string
-	ReceiverINN   string
-	ReceiverPhone string
-	CategoryID    int
-	StatusID      int
-	DateFrom      time.Time
-	DateTo        time.Time
+	UserType       string
+	TransType      string
+	SenderBank     string
+	SenderBankBIC  string
+	CounterpartyID int
+	ReceiverINN    string
+	ReceiverPhone  string
+	CategoryID     int
+	StatusID       int
+	DateFrom       time.Time
+	DateTo         time.Time
+	// AsOf — момент, на который восстанавливается состояние транзакций; nil — текущее.
+	AsOf *time.Time
+}
+
+// TransactionVersion — состояние транзакции в интервале [ValidFrom, ValidTo);
+// ValidTo = nil у действующей версии.
+type TransactionVersion struct {
+	Transaction
+	VersionID int64      `db:"version_id"`
+	ValidFrom time.Time  `db:"valid_from"`
+	ValidTo   *time.Time `db:"valid_to"`
 }
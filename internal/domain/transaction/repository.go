@@ -1,13 +1,52 @@
 package transaction
 
-import "context"
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
 
+// Repository — транзакции и категории. Методы со scope работают только с данными этой
+// области: организации или личного учета пользователя.
 type Repository interface {
-	GetTransactions(ctx context.Context, filter *TransactionFilter) ([]Transaction, error)
-	GetPreparedTransactions(ctx context.Context) ([]PreparedTransaction, error)
-	GetCategories(ctx context.Context) ([]Category, error)
+	GetTransactions(ctx context.Context, scope domain.DataScope, filter *TransactionFilter) ([]Transaction, error)
+	// GetTransactionHistory возвращает версии транзакции от первой к последней, в том числе
+	// после ее окончательного удаления.
+	GetTransactionHistory(ctx context.Context, scope domain.DataScope, id int) ([]TransactionVersion, error)
+	GetPreparedTransactions(ctx context.Context, scope domain.DataScope) ([]PreparedTransaction, error)
+	GetPreparedTransactionByID(ctx context.Context, scope domain.DataScope, id int) (*PreparedTransaction, error)
+	// GetCategories возвращает действующие категории, видимые в области scope: свои и общий
+	// справочник. Архивные категории не возвращаются.
+	GetCategories(ctx context.Context, scope domain.DataScope) ([]Category, error)
+	// SetCategoryParent переносит категорию в parentID (0 — на верхний уровень). Цикл в дереве
+	// категорий — domain.ErrCategoryCycle.
+	SetCategoryParent(ctx context.Context, id int, parentID int) error
+	// GetCategory возвращает категорию, видимую в области scope, в том числе архивную.
+	// Категория другой области — domain.ErrCategoryNotFound.
+	GetCategory(ctx context.Context, scope domain.DataScope, id int) (*Category, error)
 	GetTransactionStatuses(ctx context.Context) ([]TransactionStatus, error)
-	DeleteTransaction(ctx context.Context, id int) error
+	// DeleteTransaction переносит транзакцию в корзину и возвращает ее. Транзакции в корзине
+	// не попадают в выборки, кроме GetDeletedTransactions.
+	DeleteTransaction(ctx context.Context, scope domain.DataScope, id int, deletedBy string) (*Transaction, error)
+	GetDeletedTransactions(ctx context.Context, scope domain.DataScope) ([]Transaction, error)
+	// RestoreTransaction возвращает транзакцию из корзины.
+	RestoreTransaction(ctx context.Context, scope domain.DataScope, id int) (*Transaction, error)
+	// GetPurgeableTransactionIDs возвращает до limit транзакций, удаленных раньше before.
+	GetPurgeableTransactionIDs(ctx context.Context, before time.Time, limit int) ([]int, error)
+	// PurgeTransaction окончательно удаляет транзакцию из корзины вместе с вложениями и чеком.
+	PurgeTransaction(ctx context.Context, id int) (*Transaction, error)
 	CreateTransaction(ctx context.Context, transaction *Transaction) error
 	CreatePreparedTransaction(ctx context.Context, transaction *PreparedTransaction) error
+	GetUnresolvedSenderBanks(ctx context.Context) ([]string, error)
+	SetSenderBankBIC(ctx context.Context, senderBank string, bic string) error
+	// CreateReceiptTransaction сохраняет транзакцию вместе с реквизитами чека в одной транзакции БД.
+	CreateReceiptTransaction(ctx context.Context, transaction *Transaction, receipt *FiscalReceipt) error
+	// GetFiscalReceipt возвращает чек, ранее импортированный в области scope, или nil, если его нет.
+	GetFiscalReceipt(ctx context.Context, scope domain.DataScope, fiscalDrive, fiscalDocument, fiscalSign string) (*FiscalReceipt, error)
+	// GetSellerINNByFiscalDrive возвращает ИНН продавца из прежних чеков того же фискального
+	// накопителя в области scope.
+	GetSellerINNByFiscalDrive(ctx context.Context, scope domain.DataScope, fiscalDrive string) (string, error)
+	// SuggestCategoryByINN возвращает самую частую категорию транзакций с получателем inn
+	// заданного типа или 0, если таких транзакций нет.
+	SuggestCategoryByINN(ctx context.Context, scope domain.DataScope, inn string, transType string) (int, error)
 }
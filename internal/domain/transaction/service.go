@@ -3,14 +3,43 @@ package transaction
 import (
 	"context"
 	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"time"
 )
 
+// Service — транзакции организации или личного учета пользователя: область данных scope
+// определяется активной организацией сессии (см. domain.ScopeOf).
 type Service interface {
-	GetTransactions(ctx context.Context, filter schemas.TransactionFilter) ([]schemas.Transaction, error)
-	GetPreparedTransactions(ctx context.Context) ([]schemas.PreparedTransaction, error)
-	GetCategories(ctx context.Context) ([]schemas.Category, error)
+	// GetTransactions возвращает транзакции в текущем состоянии или, если задан filter.AsOf,
+	// в состоянии на этот момент.
+	GetTransactions(ctx context.Context, scope domain.DataScope, filter schemas.TransactionFilter) ([]schemas.Transaction, error)
+	GetTransactionHistory(ctx context.Context, scope domain.DataScope, id int64) ([]schemas.TransactionVersion, error)
+	GetPreparedTransactions(ctx context.Context, scope domain.DataScope) ([]schemas.PreparedTransaction, error)
+	GetCategories(ctx context.Context, scope domain.DataScope) ([]schemas.Category, error)
+	// GetCategoryTree возвращает дерево видимых категорий; rootID != 0 — только поддерево
+	// этой категории.
+	GetCategoryTree(ctx context.Context, scope domain.DataScope, rootID int64) ([]schemas.CategoryNode, error)
+	// SetCategoryParent переносит собственную категорию области под parentID (0 — на верхний
+	// уровень). Родитель должен быть виден в области и иметь тот же тип.
+	SetCategoryParent(ctx context.Context, scope domain.DataScope, id int64, parentID int64) (schemas.Category, error)
 	GetTransactionStatuses(ctx context.Context) ([]schemas.TransactionStatus, error)
-	DeleteTransaction(ctx context.Context, id int64) error
-	CreateTransaction(ctx context.Context, transaction schemas.Transaction) (schemas.Transaction, error)
-	CreatePreparedTransaction(ctx context.Context, transaction schemas.PreparedTransaction) (schemas.PreparedTransaction, error)
+	// DeleteTransaction переносит транзакцию в корзину; deletedBy — логин удалившего.
+	DeleteTransaction(ctx context.Context, scope domain.DataScope, id int64, deletedBy string) error
+	GetDeletedTransactions(ctx context.Context, scope domain.DataScope) ([]schemas.Transaction, error)
+	RestoreTransaction(ctx context.Context, scope domain.DataScope, id int64) (schemas.Transaction, error)
+	// PurgeDeletedTransactions окончательно удаляет транзакции, попавшие в корзину раньше before,
+	// вместе с файлами вложений. Возвращает число удаленных транзакций.
+	PurgeDeletedTransactions(ctx context.Context, before time.Time) (int, error)
+	CreateTransaction(ctx context.Context, scope domain.DataScope, transaction schemas.Transaction) (schemas.Transaction, error)
+	CreatePreparedTransaction(ctx context.Context, scope domain.DataScope, transaction schemas.PreparedTransaction) (schemas.PreparedTransaction, error)
+	NormalizeSenderBanks(ctx context.Context) error
+	ImportReceipt(ctx context.Context, scope domain.DataScope, receipt schemas.ReceiptImport) (schemas.ReceiptImportResponse, error)
+	// GetPaymentQRPayload возвращает строку платежного QR-кода (ST00012) для подготовленного платежа.
+	GetPaymentQRPayload(ctx context.Context, scope domain.DataScope, id int64) (string, error)
+}
+
+// AttachmentStorage — файлы, прикрепленные к транзакциям. Удаляются вместе с транзакцией.
+type AttachmentStorage interface {
+	ListTransactionAttachments(ctx context.Context, transactionID int64) ([]domain.Attachment, error)
+	DeleteAttachmentObjects(ctx context.Context, attachments []domain.Attachment)
 }
@@ -2,60 +2,125 @@ package transaction
 
 import (
 	"context"
+	"errors"
 	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/gateways/bank_directory"
+	"finance-backend/internal/repository/counterparty"
+	"finance-backend/pkg/fiscal"
+	"finance-backend/pkg/paymentqr"
+	"finance-backend/pkg/validation"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	transTypeDebit  = "debit"
+	transTypeCredit = "credit"
+
+	statusCompleted = 3 // 3 = "Завершена": покупка по чеку уже оплачена
 )
 
 type service struct {
-	repo Repository
+	repo           Repository
+	banks          bank_directory.IBankDirectory
+	counterparties counterparty.ICounterpartyRepository
+	attachments    AttachmentStorage
+	audit          domain.AuditRecorder
 }
 
-func NewService(repo Repository) Service {
+func NewService(
+	repo Repository,
+	banks bank_directory.IBankDirectory,
+	counterparties counterparty.ICounterpartyRepository,
+	attachments AttachmentStorage,
+	audit domain.AuditRecorder,
+) Service {
 	return &service{
-		repo: repo,
+		repo:           repo,
+		banks:          banks,
+		counterparties: counterparties,
+		attachments:    attachments,
+		audit:          audit,
 	}
 }
 
-func (s *service) GetTransactions(ctx context.Context, filter schemas.TransactionFilter) ([]schemas.Transaction, error) {
+func (s *service) GetTransactions(ctx context.Context, scope domain.DataScope, filter schemas.TransactionFilter) ([]schemas.Transaction, error) {
 	domainFilter := &TransactionFilter{
-		UserType:      filter.UserType,
-		TransType:     filter.TransType,
-		SenderBank:    filter.SenderBank,
-		ReceiverINN:   filter.ReceiverINN,
-		ReceiverPhone: filter.ReceiverPhone,
-		DateFrom:      filter.DateFrom,
-		DateTo:        filter.DateTo,
-		CategoryID:    filter.CategoryID,
-		StatusID:      filter.StatusID,
+		UserType:       filter.UserType,
+		TransType:      filter.TransType,
+		SenderBank:     filter.SenderBank,
+		SenderBankBIC:  filter.SenderBankBIC,
+		CounterpartyID: filter.CounterpartyID,
+		ReceiverINN:    filter.ReceiverINN,
+		ReceiverPhone:  validation.NormalizePhoneOrKeep(filter.ReceiverPhone),
+		DateFrom:       filter.DateFrom,
+		DateTo:         filter.DateTo,
+		CategoryID:     filter.CategoryID,
+		StatusID:       filter.StatusID,
+		AsOf:           filter.AsOf,
 	}
 
-	transactions, err := s.repo.GetTransactions(ctx, domainFilter)
+	transactions, err := s.repo.GetTransactions(ctx, scope, domainFilter)
 	if err != nil {
 		return nil, err
 	}
 
 	result := make([]schemas.Transaction, len(transactions))
-	for i, t := range transactions {
-		result[i] = schemas.Transaction{
-			ID:            t.ID,
-			UserType:      t.UserType,
-			DateTime:      t.DateTime,
-			TransType:     t.TransType,
-			Amount:        t.Amount,
-			CategoryID:    t.CategoryID,
-			StatusID:      t.StatusID,
-			SenderBank:    t.SenderBank,
-			ReceiverINN:   t.ReceiverINN,
-			ReceiverPhone: t.ReceiverPhone,
-			Comment:       t.Comment,
-			CategoryName:  t.CategoryName,
-			StatusName:    t.StatusName,
+	for i := range transactions {
+		result[i] = s.transactionSchema(&transactions[i])
+	}
+	return result, nil
+}
+
+func (s *service) GetTransactionHistory(ctx context.Context, scope domain.DataScope, id int64) ([]schemas.TransactionVersion, error) {
+	versions, err := s.repo.GetTransactionHistory(ctx, scope, int(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrTransactionNotFound
+	}
+
+	result := make([]schemas.TransactionVersion, len(versions))
+	for i := range versions {
+		result[i] = schemas.TransactionVersion{
+			Transaction: s.transactionSchema(&versions[i].Transaction),
+			VersionID:   versions[i].VersionID,
+			ValidFrom:   versions[i].ValidFrom,
+			ValidTo:     versions[i].ValidTo,
 		}
 	}
 	return result, nil
 }
 
-func (s *service) GetPreparedTransactions(ctx context.Context) ([]schemas.PreparedTransaction, error) {
-	transactions, err := s.repo.GetPreparedTransactions(ctx)
+func (s *service) transactionSchema(t *Transaction) schemas.Transaction {
+	return schemas.Transaction{
+		ID:             t.ID,
+		UserType:       t.UserType,
+		DateTime:       t.DateTime,
+		TransType:      t.TransType,
+		Amount:         t.Amount,
+		CategoryID:     t.CategoryID,
+		StatusID:       t.StatusID,
+		SenderBank:     t.SenderBank,
+		SenderBankBIC:  t.SenderBankBIC,
+		SenderBankName: s.bankName(t.SenderBankBIC),
+		CounterpartyID: t.CounterpartyID,
+		ReceiverINN:    t.ReceiverINN,
+		ReceiverPhone:  t.ReceiverPhone,
+		Comment:        t.Comment,
+		CategoryName:   t.CategoryName,
+		StatusName:     t.StatusName,
+		DeletedAt:      t.DeletedAt,
+		DeletedBy:      t.DeletedBy,
+	}
+}
+
+func (s *service) GetPreparedTransactions(ctx context.Context, scope domain.DataScope) ([]schemas.PreparedTransaction, error) {
+	transactions, err := s.repo.GetPreparedTransactions(ctx, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -63,39 +128,144 @@ func (s *service) GetPreparedTransactions(ctx context.Context) ([]schemas.Prepar
 	result := make([]schemas.PreparedTransaction, len(transactions))
 	for i, t := range transactions {
 		result[i] = schemas.PreparedTransaction{
-			ID:            t.ID,
-			UserType:      t.UserType,
-			DateTime:      t.DateTime,
-			TransType:     t.TransType,
-			Amount:        t.Amount,
-			CategoryID:    t.CategoryID,
-			StatusID:      t.StatusID,
-			SenderBank:    t.SenderBank,
-			ReceiverINN:   t.ReceiverINN,
-			ReceiverPhone: t.ReceiverPhone,
-			Comment:       t.Comment,
+			ID:             t.ID,
+			UserType:       t.UserType,
+			DateTime:       t.DateTime,
+			TransType:      t.TransType,
+			Amount:         t.Amount,
+			CategoryID:     t.CategoryID,
+			StatusID:       t.StatusID,
+			SenderBank:     t.SenderBank,
+			SenderBankBIC:  t.SenderBankBIC,
+			SenderBankName: s.bankName(t.SenderBankBIC),
+			CounterpartyID: t.CounterpartyID,
+			ReceiverINN:    t.ReceiverINN,
+			ReceiverPhone:  t.ReceiverPhone,
+			Comment:        t.Comment,
 		}
 	}
 	return result, nil
 }
 
-func (s *service) GetCategories(ctx context.Context) ([]schemas.Category, error) {
-	categories, err := s.repo.GetCategories(ctx)
+func (s *service) GetCategories(ctx context.Context, scope domain.DataScope) ([]schemas.Category, error) {
+	categories, err := s.repo.GetCategories(ctx, scope)
 	if err != nil {
 		return nil, err
 	}
 
 	result := make([]schemas.Category, len(categories))
 	for i, c := range categories {
-		result[i] = schemas.Category{
-			ID:   c.ID,
-			Name: c.Name,
-			Type: c.Type,
+		result[i] = categorySchema(c)
+	}
+	return result, nil
+}
+
+func (s *service) GetCategoryTree(ctx context.Context, scope domain.DataScope, rootID int64) ([]schemas.CategoryNode, error) {
+	categories, err := s.repo.GetCategories(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]Category, len(categories))
+	children := make(map[int][]Category)
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	for _, c := range categories {
+		// Родитель может быть не виден в области — тогда категория становится корнем.
+		parent := c.ParentID
+		if _, ok := byID[parent]; !ok {
+			parent = 0
 		}
+		children[parent] = append(children[parent], c)
+	}
+
+	var build func(c Category) schemas.CategoryNode
+	build = func(c Category) schemas.CategoryNode {
+		node := schemas.CategoryNode{Category: categorySchema(c), Children: []schemas.CategoryNode{}}
+		for _, child := range children[c.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	if rootID != 0 {
+		root, ok := byID[int(rootID)]
+		if !ok {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return []schemas.CategoryNode{build(root)}, nil
+	}
+
+	result := make([]schemas.CategoryNode, 0, len(children[0]))
+	for _, c := range children[0] {
+		result = append(result, build(c))
 	}
 	return result, nil
 }
 
+func (s *service) SetCategoryParent(ctx context.Context, scope domain.DataScope, id int64, parentID int64) (schemas.Category, error) {
+	categories, err := s.repo.GetCategories(ctx, scope)
+	if err != nil {
+		return schemas.Category{}, err
+	}
+	byID := make(map[int]Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	category, ok := byID[int(id)]
+	if !ok {
+		return schemas.Category{}, domain.ErrCategoryNotFound
+	}
+	// Общий справочник общий для всех: менять его через API нельзя.
+	if category.IsShared() {
+		return schemas.Category{}, domain.ErrForbidden
+	}
+
+	if parentID != 0 {
+		parent, ok := byID[int(parentID)]
+		if !ok || parent.Type != category.Type {
+			return schemas.Category{}, domain.ErrCategoryParentInvalid
+		}
+		// Быстрая проверка по видимой части дерева; окончательно цикл проверяет триггер.
+		for p, seen := parent, 0; seen <= len(byID); seen++ {
+			if p.ID == category.ID {
+				return schemas.Category{}, domain.ErrCategoryCycle
+			}
+			if p, ok = byID[p.ParentID]; !ok {
+				break
+			}
+		}
+	}
+
+	if err := s.repo.SetCategoryParent(ctx, int(id), int(parentID)); err != nil {
+		return schemas.Category{}, err
+	}
+
+	before := categorySchema(category)
+	category.ParentID = int(parentID)
+	after := categorySchema(category)
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionUpdate,
+		EntityType: domain.AuditEntityCategory,
+		EntityID:   strconv.FormatInt(id, 10),
+		OrgID:      scope.OrgID,
+		Before:     before,
+		After:      after,
+	})
+	return after, nil
+}
+
+func categorySchema(c Category) schemas.Category {
+	return schemas.Category{
+		ID:       c.ID,
+		Name:     c.Name,
+		Type:     c.Type,
+		ParentID: c.ParentID,
+	}
+}
+
 func (s *service) GetTransactionStatuses(ctx context.Context) ([]schemas.TransactionStatus, error) {
 	statuses, err := s.repo.GetTransactionStatuses(ctx)
 	if err != nil {
@@ -112,11 +282,104 @@ func (s *service) GetTransactionStatuses(ctx context.Context) ([]schemas.Transac
 	return result, nil
 }
 
-func (s *service) DeleteTransaction(ctx context.Context, id int64) error {
-	return s.repo.DeleteTransaction(ctx, int(id))
+func (s *service) DeleteTransaction(ctx context.Context, scope domain.DataScope, id int64, deletedBy string) error {
+	// Вложения остаются до окончательного удаления: транзакцию можно восстановить вместе с ними.
+	deleted, err := s.repo.DeleteTransaction(ctx, scope, int(id), deletedBy)
+	if err != nil {
+		return err
+	}
+
+	after := s.transactionSchema(deleted)
+	before := after
+	before.DeletedAt, before.DeletedBy = nil, ""
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionDelete,
+		EntityType: domain.AuditEntityTransaction,
+		EntityID:   strconv.FormatInt(id, 10),
+		OrgID:      scope.OrgID,
+		Before:     before,
+		After:      after,
+	})
+	return nil
+}
+
+func (s *service) GetDeletedTransactions(ctx context.Context, scope domain.DataScope) ([]schemas.Transaction, error) {
+	transactions, err := s.repo.GetDeletedTransactions(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]schemas.Transaction, len(transactions))
+	for i := range transactions {
+		result[i] = s.transactionSchema(&transactions[i])
+	}
+	return result, nil
+}
+
+func (s *service) RestoreTransaction(ctx context.Context, scope domain.DataScope, id int64) (schemas.Transaction, error) {
+	restored, err := s.repo.RestoreTransaction(ctx, scope, int(id))
+	if err != nil {
+		return schemas.Transaction{}, err
+	}
+
+	after := s.transactionSchema(restored)
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionRestore,
+		EntityType: domain.AuditEntityTransaction,
+		EntityID:   strconv.FormatInt(id, 10),
+		OrgID:      scope.OrgID,
+		After:      after,
+	})
+	return after, nil
+}
+
+// purgeBatchSize — сколько транзакций из корзины выбирается за один проход очистки.
+const purgeBatchSize = 100
+
+func (s *service) PurgeDeletedTransactions(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for {
+		ids, err := s.repo.GetPurgeableTransactionIDs(ctx, before, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		for _, id := range ids {
+			attachments, err := s.attachments.ListTransactionAttachments(ctx, int64(id))
+			if err != nil {
+				return purged, err
+			}
+			t, err := s.repo.PurgeTransaction(ctx, id)
+			if errors.Is(err, ErrTransactionNotFound) {
+				// Транзакцию успели восстановить.
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+
+			// Записи о вложениях удаляются каскадом, файлы убираем уже после удаления транзакции,
+			// чтобы при ошибке не остаться с транзакцией без ее документов.
+			s.attachments.DeleteAttachmentObjects(ctx, attachments)
+			s.audit.Record(ctx, domain.AuditEvent{
+				Action:     domain.AuditActionPurge,
+				EntityType: domain.AuditEntityTransaction,
+				EntityID:   strconv.Itoa(id),
+				OrgID:      t.OrgID,
+				Before:     s.transactionSchema(t),
+			})
+			purged++
+		}
+		if len(ids) < purgeBatchSize {
+			return purged, nil
+		}
+	}
 }
 
-func (s *service) CreateTransaction(ctx context.Context, transaction schemas.Transaction) (schemas.Transaction, error) {
+func (s *service) CreateTransaction(ctx context.Context, scope domain.DataScope, transaction schemas.Transaction) (schemas.Transaction, error) {
 	domainTransaction := &Transaction{
 		ID:            transaction.ID,
 		UserType:      transaction.UserType,
@@ -126,20 +389,51 @@ func (s *service) CreateTransaction(ctx context.Context, transaction schemas.Tra
 		CategoryID:    transaction.CategoryID,
 		StatusID:      transaction.StatusID,
 		SenderBank:    transaction.SenderBank,
+		SenderBankBIC: s.resolveSenderBankBIC(transaction.SenderBank, transaction.SenderBankBIC),
 		ReceiverINN:   transaction.ReceiverINN,
-		ReceiverPhone: transaction.ReceiverPhone,
+		ReceiverPhone: validation.NormalizePhoneOrKeep(transaction.ReceiverPhone),
 		Comment:       transaction.Comment,
 	}
 
-	err := s.repo.CreateTransaction(ctx, domainTransaction)
+	cp, err := s.resolveCounterparty(ctx, scope, transaction.CounterpartyID, domainTransaction.ReceiverINN, domainTransaction.ReceiverPhone)
+	if err != nil {
+		return schemas.Transaction{}, err
+	}
+	if cp != nil {
+		domainTransaction.CounterpartyID = int(cp.ID)
+		if err := s.fillFromCounterparty(ctx, scope, cp, domainTransaction.TransType, &domainTransaction.ReceiverINN, &domainTransaction.ReceiverPhone, &domainTransaction.CategoryID); err != nil {
+			return schemas.Transaction{}, err
+		}
+	}
+	if err := s.checkCategory(ctx, scope, domainTransaction.CategoryID, domainTransaction.TransType); err != nil {
+		return schemas.Transaction{}, err
+	}
+	domainTransaction.OrgID, domainTransaction.OwnerLogin = owner(scope)
+
+	err = s.repo.CreateTransaction(ctx, domainTransaction)
 	if err != nil {
 		return schemas.Transaction{}, err
 	}
 
+	transaction.ID = domainTransaction.ID
+	transaction.SenderBankBIC = domainTransaction.SenderBankBIC
+	transaction.CounterpartyID = domainTransaction.CounterpartyID
+	transaction.ReceiverINN = domainTransaction.ReceiverINN
+	transaction.ReceiverPhone = domainTransaction.ReceiverPhone
+	transaction.CategoryID = domainTransaction.CategoryID
+	transaction.SenderBankName = s.bankName(domainTransaction.SenderBankBIC)
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionCreate,
+		EntityType: domain.AuditEntityTransaction,
+		EntityID:   strconv.Itoa(transaction.ID),
+		OrgID:      scope.OrgID,
+		After:      transaction,
+	})
 	return transaction, nil
 }
 
-func (s *service) CreatePreparedTransaction(ctx context.Context, transaction schemas.PreparedTransaction) (schemas.PreparedTransaction, error) {
+func (s *service) CreatePreparedTransaction(ctx context.Context, scope domain.DataScope, transaction schemas.PreparedTransaction) (schemas.PreparedTransaction, error) {
 	domainTransaction := &PreparedTransaction{
 		ID:            transaction.ID,
 		UserType:      transaction.UserType,
@@ -149,15 +443,348 @@ func (s *service) CreatePreparedTransaction(ctx context.Context, transaction sch
 		CategoryID:    transaction.CategoryID,
 		StatusID:      transaction.StatusID,
 		SenderBank:    transaction.SenderBank,
+		SenderBankBIC: s.resolveSenderBankBIC(transaction.SenderBank, transaction.SenderBankBIC),
 		ReceiverINN:   transaction.ReceiverINN,
-		ReceiverPhone: transaction.ReceiverPhone,
+		ReceiverPhone: validation.NormalizePhoneOrKeep(transaction.ReceiverPhone),
 		Comment:       transaction.Comment,
 	}
 
-	err := s.repo.CreatePreparedTransaction(ctx, domainTransaction)
+	cp, err := s.resolveCounterparty(ctx, scope, transaction.CounterpartyID, domainTransaction.ReceiverINN, domainTransaction.ReceiverPhone)
+	if err != nil {
+		return schemas.PreparedTransaction{}, err
+	}
+	if cp != nil {
+		domainTransaction.CounterpartyID = int(cp.ID)
+		if err := s.fillFromCounterparty(ctx, scope, cp, domainTransaction.TransType, &domainTransaction.ReceiverINN, &domainTransaction.ReceiverPhone, &domainTransaction.CategoryID); err != nil {
+			return schemas.PreparedTransaction{}, err
+		}
+	}
+	if err := s.checkCategory(ctx, scope, domainTransaction.CategoryID, domainTransaction.TransType); err != nil {
+		return schemas.PreparedTransaction{}, err
+	}
+	domainTransaction.OrgID, domainTransaction.OwnerLogin = owner(scope)
+
+	err = s.repo.CreatePreparedTransaction(ctx, domainTransaction)
 	if err != nil {
 		return schemas.PreparedTransaction{}, err
 	}
 
+	transaction.ID = domainTransaction.ID
+	transaction.SenderBankBIC = domainTransaction.SenderBankBIC
+	transaction.CounterpartyID = domainTransaction.CounterpartyID
+	transaction.ReceiverINN = domainTransaction.ReceiverINN
+	transaction.ReceiverPhone = domainTransaction.ReceiverPhone
+	transaction.CategoryID = domainTransaction.CategoryID
+	transaction.SenderBankName = s.bankName(domainTransaction.SenderBankBIC)
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionCreate,
+		EntityType: domain.AuditEntityPreparedTransaction,
+		EntityID:   strconv.Itoa(transaction.ID),
+		OrgID:      scope.OrgID,
+		After:      transaction,
+	})
 	return transaction, nil
 }
+
+func (s *service) NormalizeSenderBanks(ctx context.Context) error {
+	names, err := s.repo.GetUnresolvedSenderBanks(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		bic := s.resolveSenderBankBIC(name, "")
+		if bic == "" {
+			continue
+		}
+		if err := s.repo.SetSenderBankBIC(ctx, name, bic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportReceipt создает транзакцию по QR-коду кассового чека. Покупка становится расходом,
+// возврат — доходом. Если ИНН продавца не передан, он берется из прежних чеков того же
+// фискального накопителя; категория подбирается по контрагенту или по прежним транзакциям продавца.
+func (s *service) ImportReceipt(ctx context.Context, scope domain.DataScope, req schemas.ReceiptImport) (schemas.ReceiptImportResponse, error) {
+	parsed, err := fiscal.ParseQR(req.QR)
+	if err != nil {
+		return schemas.ReceiptImportResponse{}, domain.ErrReceiptInvalid
+	}
+
+	existing, err := s.repo.GetFiscalReceipt(ctx, scope, parsed.FiscalDrive, parsed.FiscalDocument, parsed.FiscalSign)
+	if err != nil {
+		return schemas.ReceiptImportResponse{}, err
+	}
+	if existing != nil {
+		return schemas.ReceiptImportResponse{}, domain.ErrReceiptAlreadyImported
+	}
+
+	sellerINN := req.SellerINN
+	if sellerINN == "" {
+		if sellerINN, err = s.repo.GetSellerINNByFiscalDrive(ctx, scope, parsed.FiscalDrive); err != nil {
+			return schemas.ReceiptImportResponse{}, err
+		}
+	}
+
+	transType := transTypeCredit
+	if parsed.IsExpense() {
+		transType = transTypeDebit
+	}
+
+	comment := req.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Кассовый чек ФН %s ФД %s", parsed.FiscalDrive, parsed.FiscalDocument)
+	}
+
+	domainTransaction := &Transaction{
+		UserType:    req.UserType,
+		DateTime:    parsed.DateTime,
+		TransType:   transType,
+		Amount:      parsed.Amount,
+		CategoryID:  req.CategoryID,
+		StatusID:    statusCompleted,
+		ReceiverINN: sellerINN,
+		Comment:     comment,
+	}
+
+	cp, err := s.resolveCounterparty(ctx, scope, 0, sellerINN, "")
+	if err != nil {
+		return schemas.ReceiptImportResponse{}, err
+	}
+	if cp != nil {
+		domainTransaction.CounterpartyID = int(cp.ID)
+		if err := s.fillFromCounterparty(ctx, scope, cp, domainTransaction.TransType, &domainTransaction.ReceiverINN, &domainTransaction.ReceiverPhone, &domainTransaction.CategoryID); err != nil {
+			return schemas.ReceiptImportResponse{}, err
+		}
+	}
+
+	suggested := false
+	if domainTransaction.CategoryID == 0 && sellerINN != "" {
+		domainTransaction.CategoryID, err = s.repo.SuggestCategoryByINN(ctx, scope, sellerINN, transType)
+		if err != nil {
+			return schemas.ReceiptImportResponse{}, err
+		}
+		suggested = domainTransaction.CategoryID != 0
+	}
+	if domainTransaction.CategoryID == 0 {
+		return schemas.ReceiptImportResponse{}, domain.ErrReceiptCategoryRequired
+	}
+	if err := s.checkCategory(ctx, scope, domainTransaction.CategoryID, transType); err != nil {
+		return schemas.ReceiptImportResponse{}, err
+	}
+	domainTransaction.OrgID, domainTransaction.OwnerLogin = owner(scope)
+
+	receipt := &FiscalReceipt{
+		FiscalDrive:    parsed.FiscalDrive,
+		FiscalDocument: parsed.FiscalDocument,
+		FiscalSign:     parsed.FiscalSign,
+		Operation:      parsed.Operation,
+		Amount:         parsed.Amount,
+		ReceiptTime:    parsed.DateTime,
+		SellerINN:      sellerINN,
+	}
+	if err := s.repo.CreateReceiptTransaction(ctx, domainTransaction, receipt); err != nil {
+		return schemas.ReceiptImportResponse{}, err
+	}
+
+	response := schemas.ReceiptImportResponse{
+		Transaction: schemas.Transaction{
+			ID:             domainTransaction.ID,
+			UserType:       domainTransaction.UserType,
+			DateTime:       domainTransaction.DateTime,
+			TransType:      domainTransaction.TransType,
+			Amount:         domainTransaction.Amount,
+			CategoryID:     domainTransaction.CategoryID,
+			StatusID:       domainTransaction.StatusID,
+			CounterpartyID: domainTransaction.CounterpartyID,
+			ReceiverINN:    domainTransaction.ReceiverINN,
+			ReceiverPhone:  domainTransaction.ReceiverPhone,
+			Comment:        domainTransaction.Comment,
+		},
+		Receipt: schemas.FiscalReceipt{
+			FiscalDrive:    receipt.FiscalDrive,
+			FiscalDocument: receipt.FiscalDocument,
+			FiscalSign:     receipt.FiscalSign,
+			Operation:      receipt.Operation,
+			Amount:         receipt.Amount,
+			DateTime:       receipt.ReceiptTime,
+			SellerINN:      receipt.SellerINN,
+		},
+		CategorySuggested: suggested,
+	}
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionCreate,
+		EntityType: domain.AuditEntityTransaction,
+		EntityID:   strconv.Itoa(domainTransaction.ID),
+		OrgID:      scope.OrgID,
+		After:      response,
+	})
+	return response, nil
+}
+
+// GetPaymentQRPayload собирает реквизиты платежа из подготовленной транзакции и ее контрагента.
+// Название банка и корреспондентский счет берутся из справочника БИК.
+func (s *service) GetPaymentQRPayload(ctx context.Context, scope domain.DataScope, id int64) (string, error) {
+	t, err := s.repo.GetPreparedTransactionByID(ctx, scope, int(id))
+	if err != nil {
+		return "", err
+	}
+	if t.TransType != transTypeDebit {
+		return "", domain.ErrPaymentQRNotOutgoing
+	}
+
+	var cp *domain.Counterparty
+	if t.CounterpartyID != 0 {
+		cp, err = s.counterparties.GetByID(ctx, scope, int64(t.CounterpartyID))
+	} else if t.ReceiverINN != "" || t.ReceiverPhone != "" {
+		cp, err = s.counterparties.FindByRequisites(ctx, scope, t.ReceiverINN, t.ReceiverPhone)
+	}
+	if err != nil {
+		return "", err
+	}
+	if cp == nil || cp.Account == nil || cp.BankBIC == nil {
+		return "", domain.ErrPaymentRequisitesMissing
+	}
+
+	payment := paymentqr.Payment{
+		Name:        cp.Name,
+		PersonalAcc: *cp.Account,
+		BIC:         *cp.BankBIC,
+		PayeeINN:    t.ReceiverINN,
+		Sum:         int64(math.Round(t.Amount * 100)),
+		Purpose:     t.Comment,
+	}
+	if cp.INN != nil {
+		payment.PayeeINN = *cp.INN
+	}
+	if payment.PayeeINN == "" {
+		return "", domain.ErrPaymentRequisitesMissing
+	}
+	if bank, ok := s.banks.GetByBIC(payment.BIC); ok {
+		payment.BankName = bank.Name
+		payment.CorrespAcc = bank.CorrAccount
+	} else if cp.Bank != nil {
+		payment.BankName = *cp.Bank
+	}
+
+	payload, err := payment.Payload()
+	if errors.Is(err, paymentqr.ErrMissingField) {
+		return "", domain.ErrPaymentRequisitesMissing
+	}
+	if errors.Is(err, paymentqr.ErrInvalidAmount) {
+		return "", domain.ErrPaymentAmountInvalid
+	}
+	return payload, err
+}
+
+// resolveCounterparty возвращает контрагента области scope по явному ID либо находит его
+// по ИНН или телефону получателя, заводя новую запись при первом платеже.
+func (s *service) resolveCounterparty(ctx context.Context, scope domain.DataScope, id int, inn, phone string) (*domain.Counterparty, error) {
+	if id != 0 {
+		return s.counterparties.GetByID(ctx, scope, int64(id))
+	}
+	if inn == "" && phone == "" {
+		return nil, nil
+	}
+
+	cp, err := s.counterparties.FindByRequisites(ctx, scope, inn, phone)
+	if err != nil || cp != nil {
+		return cp, err
+	}
+
+	data := &domain.CounterpartyData{Name: inn}
+	if inn != "" {
+		data.INN = &inn
+	} else {
+		data.Name = phone
+	}
+	if phone != "" {
+		data.Phone = &phone
+	}
+
+	cp, err = s.counterparties.Create(ctx, scope, data)
+	if errors.Is(err, domain.ErrCounterpartyExists) {
+		return s.counterparties.FindByRequisites(ctx, scope, inn, phone)
+	}
+	return cp, err
+}
+
+// checkCategory проверяет, что категорию можно указать в операции типа transType: она видна
+// в области scope, не в архиве и того же типа (категории без типа из старого справочника
+// подходят любой операции). Категории другой организации или другого пользователя считаются
+// несуществующими.
+func (s *service) checkCategory(ctx context.Context, scope domain.DataScope, categoryID int, transType string) error {
+	if categoryID == 0 {
+		return nil
+	}
+	category, err := s.repo.GetCategory(ctx, scope, categoryID)
+	if err != nil {
+		return err
+	}
+	if category.IsArchived() {
+		return domain.ErrCategoryArchived
+	}
+	if category.Type != "" && category.Type != transType {
+		return domain.ErrCategoryTypeMismatch
+	}
+	return nil
+}
+
+// owner возвращает владельца новой записи области scope: организацию либо пользователя.
+func owner(scope domain.DataScope) (int64, string) {
+	if scope.IsOrganization() {
+		return scope.OrgID, ""
+	}
+	return 0, scope.Login
+}
+
+// fillFromCounterparty дополняет незаполненные реквизиты и категорию данными контрагента.
+// Категория по умолчанию подставляется, только если она подходит операции типа transType:
+// после выбора ее могли перенести в архив, а контрагент бывает и получателем, и плательщиком.
+func (s *service) fillFromCounterparty(ctx context.Context, scope domain.DataScope, cp *domain.Counterparty, transType string, inn, phone *string, categoryID *int) error {
+	if *inn == "" && cp.INN != nil {
+		*inn = *cp.INN
+	}
+	if *phone == "" && cp.Phone != nil {
+		*phone = *cp.Phone
+	}
+	if *categoryID != 0 || cp.DefaultCategoryID == nil {
+		return nil
+	}
+
+	err := s.checkCategory(ctx, scope, int(*cp.DefaultCategoryID), transType)
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	*categoryID = int(*cp.DefaultCategoryID)
+	return nil
+}
+
+// resolveSenderBankBIC возвращает БИК банка отправителя: явно переданный
+// или подобранный по справочнику из текстового названия.
+func (s *service) resolveSenderBankBIC(senderBank, bic string) string {
+	if bic != "" {
+		return bic
+	}
+	if bank, ok := s.banks.FindByName(senderBank); ok {
+		return bank.BIC
+	}
+	return ""
+}
+
+func (s *service) bankName(bic string) string {
+	if bic == "" {
+		return ""
+	}
+	if bank, ok := s.banks.GetByBIC(bic); ok {
+		return bank.Name
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"finance-backend/internal/delivery/http/schemas"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/gateways/bank_directory"
+)
+
+// categoryRepo — репозиторий с одной категорией; остальные методы не используются.
+type categoryRepo struct {
+	Repository
+	categories map[int]Category
+	created    []*Transaction
+}
+
+func (r *categoryRepo) GetCategory(_ context.Context, _ domain.DataScope, id int) (*Category, error) {
+	c, ok := r.categories[id]
+	if !ok {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return &c, nil
+}
+
+func (r *categoryRepo) CreateTransaction(_ context.Context, t *Transaction) error {
+	t.ID = len(r.created) + 1
+	r.created = append(r.created, t)
+	return nil
+}
+
+type noBanks struct {
+	bank_directory.IBankDirectory
+}
+
+func (noBanks) GetByBIC(string) (*domain.Bank, bool) { return nil, false }
+
+type noAudit struct{}
+
+func (noAudit) Record(context.Context, domain.AuditEvent) {}
+
+func TestCreateTransactionChecksCategory(t *testing.T) {
+	archived := time.Now()
+	repo := &categoryRepo{categories: map[int]Category{
+		1: {ID: 1, Name: "Продукты", Type: transTypeDebit},
+		2: {ID: 2, Name: "Зарплата", Type: transTypeCredit},
+		3: {ID: 3, Name: "Такси", Type: transTypeDebit, ArchivedAt: &archived},
+	}}
+	svc := NewService(repo, noBanks{}, nil, nil, noAudit{})
+	scope := domain.DataScope{Login: "user"}
+
+	tests := []struct {
+		name       string
+		categoryID int
+		want       error
+	}{
+		{"same type", 1, nil},
+		{"type mismatch", 2, domain.ErrCategoryTypeMismatch},
+		{"archived", 3, domain.ErrCategoryArchived},
+		{"not visible", 4, domain.ErrCategoryNotFound},
+		{"no category", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateTransaction(context.Background(), scope, schemas.Transaction{
+				TransType:     transTypeDebit,
+				Amount:        100,
+				CategoryID:    tt.categoryID,
+				SenderBankBIC: "044525225",
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CreateTransaction() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(repo.created) != 2 {
+		t.Fatalf("created %d transactions, want 2", len(repo.created))
+	}
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type UserType string
 
 const (
@@ -31,17 +33,81 @@ type UserCreationData struct {
 	Name     string
 	Password string
 	Bank     string
+	BIC      string
 	Account  string
 	INN      string
+	KPP      string
 	Phone    string
+	Email    string
 }
 
 type User struct {
 	Login   string
+	Role    Role
 	IsAdmin bool
+	// APIKeyID и KeyPermissions заполнены, если запрос подписан ключом API:
+	// права пользователя тогда ограничены правами ключа.
+	APIKeyID       int64
+	KeyPermissions []Permission
+	// OrgID и OrgRole заполнены, если в токене выбрана организация: запрос работает
+	// с ее данными, а права дополнительно ограничены ролью в ней.
+	OrgID   int64
+	OrgRole OrgRole
+}
+
+// NewUser заполняет IsAdmin по роли, чтобы оба поля не расходились.
+func NewUser(login string, role Role) User {
+	return User{Login: login, Role: role, IsAdmin: role == RoleAdmin}
+}
+
+// Can сообщает, есть ли у пользователя право p.
+func (u User) Can(p Permission) bool {
+	if !u.Role.Can(p) {
+		return false
+	}
+	if u.OrgID != 0 && !u.OrgRole.Can(p) {
+		return false
+	}
+	if !u.ViaAPIKey() {
+		return true
+	}
+	for _, granted := range u.KeyPermissions {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ViaAPIKey сообщает, что запрос выполнен с ключом API, а не в сессии пользователя.
+func (u User) ViaAPIKey() bool {
+	return u.APIKeyID != 0
 }
 
 type RawUser struct {
 	User
-	PasswordHash string
+	PasswordHash          string
+	Email                 string
+	Blocked               bool
+	PasswordResetRequired bool
+}
+
+// UserAccount — учетная запись пользователя в том виде, в каком ее видит администратор.
+type UserAccount struct {
+	Login                 string     `db:"login_name"`
+	Role                  Role       `db:"role"`
+	UserType              string     `db:"part_type"`
+	Name                  string     `db:"part_name"`
+	INN                   string     `db:"part_inn"`
+	Phone                 string     `db:"part_phone"`
+	Email                 string     `db:"email"`
+	BlockedAt             *time.Time `db:"blocked_at"`
+	PasswordResetRequired bool       `db:"password_reset_required"`
+}
+
+// UserSearchFilter — условия поиска пользователей. Пустые поля не ограничивают выборку.
+type UserSearchFilter struct {
+	Search  *string // подстрока логина или имени, начало ИНН
+	Role    *Role
+	Blocked *bool
 }
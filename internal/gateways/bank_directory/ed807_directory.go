@@ -0,0 +1,311 @@
+package bank_directory
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	participantStatusActive  = "PSAC"
+	participantTypeCreditOrg = "20"
+	accountTypeCorrespondent = "CRSA"
+)
+
+const (
+	scoreExact = 100 - iota*20
+	scorePrefix
+	scoreQueryPrefix
+	scoreContains
+)
+
+// legalForms — организационно-правовые формы, которые не участвуют в сравнении названий.
+var legalForms = map[string]struct{}{
+	"пао": {}, "ао": {}, "оао": {}, "зао": {}, "ооо": {}, "нао": {},
+	"кб": {}, "акб": {}, "ком": {}, "нко": {}, "рнко": {}, "бк": {},
+}
+
+type ed807Document struct {
+	XMLName xml.Name     `xml:"ED807"`
+	Entries []ed807Entry `xml:"BICDirectoryEntry"`
+}
+
+type ed807Entry struct {
+	BIC         string `xml:"BIC,attr"`
+	Participant struct {
+		Name       string `xml:"NameP,attr"`
+		EnglName   string `xml:"EnglName,attr"`
+		SettleType string `xml:"Tnp,attr"`
+		Settlement string `xml:"Nnp,attr"`
+		Address    string `xml:"Adr,attr"`
+		Type       string `xml:"PtType,attr"`
+		Status     string `xml:"ParticipantStatus,attr"`
+	} `xml:"ParticipantInfo"`
+	Accounts []struct {
+		Account string `xml:"Account,attr"`
+		Type    string `xml:"RegulationAccountType,attr"`
+	} `xml:"Accounts"`
+}
+
+type indexedBank struct {
+	bank           domain.Bank
+	normalizedName string
+}
+
+// ED807Directory — справочник БИК в памяти, загружаемый из локального файла ED807.
+type ED807Directory struct {
+	mu    sync.RWMutex
+	byBIC map[string]*indexedBank
+	banks []*indexedBank
+	log   *logger.Logger
+}
+
+func NewED807Directory(log *logger.Logger) *ED807Directory {
+	return &ED807Directory{
+		byBIC: map[string]*indexedBank{},
+		log:   log,
+	}
+}
+
+// LoadFile перечитывает справочник из файла. Ранее загруженные данные заменяются целиком.
+func (d *ED807Directory) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return d.Load(f)
+}
+
+func (d *ED807Directory) Load(r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(label) {
+		case "windows-1251", "cp1251":
+			return charmap.Windows1251.NewDecoder().Reader(input), nil
+		case "utf-8", "":
+			return input, nil
+		}
+		return nil, fmt.Errorf("unsupported ED807 charset: %s", label)
+	}
+
+	var doc ed807Document
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("parse ED807: %w", err)
+	}
+
+	byBIC := make(map[string]*indexedBank, len(doc.Entries))
+	banks := make([]*indexedBank, 0, len(doc.Entries))
+	for _, e := range doc.Entries {
+		bank := domain.Bank{
+			BIC:             e.BIC,
+			Name:            e.Participant.Name,
+			EnglishName:     e.Participant.EnglName,
+			Settlement:      strings.TrimSpace(e.Participant.SettleType + " " + e.Participant.Settlement),
+			Address:         e.Participant.Address,
+			ParticipantType: e.Participant.Type,
+			IsActive:        e.Participant.Status == "" || e.Participant.Status == participantStatusActive,
+		}
+		for _, acc := range e.Accounts {
+			if acc.Type == accountTypeCorrespondent {
+				bank.CorrAccount = acc.Account
+				break
+			}
+		}
+
+		item := &indexedBank{bank: bank, normalizedName: normalizeBankName(bank.Name)}
+		byBIC[bank.BIC] = item
+		banks = append(banks, item)
+	}
+
+	d.mu.Lock()
+	d.byBIC = byBIC
+	d.banks = banks
+	d.mu.Unlock()
+
+	d.log.Info(context.TODO(), "bank_directory_loaded", map[string]interface{}{"entries": len(banks)})
+
+	return nil
+}
+
+func (d *ED807Directory) GetByBIC(bic string) (*domain.Bank, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	item, ok := d.byBIC[strings.TrimSpace(bic)]
+	if !ok {
+		return nil, false
+	}
+	bank := item.bank
+	return &bank, true
+}
+
+// FindByName возвращает банк, только если название определяет его однозначно:
+// совпадает с названием из справочника или является началом названия единственной
+// кредитной организации. Вхождения в середину названия и опечатки не учитываются —
+// для них есть Search с выбором из списка.
+func (d *ED807Directory) FindByName(name string) (*domain.Bank, bool) {
+	query := normalizeBankName(name)
+	if query == "" {
+		return nil, false
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var candidates []*indexedBank
+	bestScore := 0
+	for _, item := range d.banks {
+		if !item.bank.IsActive {
+			continue
+		}
+		score := matchScore(item.normalizedName, query)
+		if score < scoreQueryPrefix {
+			continue
+		}
+		switch {
+		case score > bestScore:
+			candidates, bestScore = []*indexedBank{item}, score
+		case score == bestScore:
+			candidates = append(candidates, item)
+		}
+	}
+
+	best := uniqueCandidate(candidates)
+	if best == nil {
+		return nil, false
+	}
+	bank := best.bank
+	return &bank, true
+}
+
+// uniqueCandidate возвращает единственного кандидата. Из нескольких одинаково
+// подходящих выбирается головная кредитная организация, если среди них она одна
+// (ПАО Сбербанк среди его филиалов); иначе совпадение считается неоднозначным.
+func uniqueCandidate(candidates []*indexedBank) *indexedBank {
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	var head *indexedBank
+	for _, item := range candidates {
+		if item.bank.ParticipantType != participantTypeCreditOrg {
+			continue
+		}
+		if head != nil {
+			return nil
+		}
+		head = item
+	}
+	return head
+}
+
+func (d *ED807Directory) Search(query string, limit int) []domain.Bank {
+	query = strings.TrimSpace(query)
+	normalized := normalizeBankName(query)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	type scored struct {
+		item  *indexedBank
+		score int
+	}
+	var found []scored
+	for _, item := range d.banks {
+		if !item.bank.IsActive {
+			continue
+		}
+		score := 0
+		switch {
+		case query == "":
+			score = scoreContains
+		case strings.HasPrefix(item.bank.BIC, query):
+			score = scorePrefix
+		case normalized != "":
+			score = matchScore(item.normalizedName, normalized)
+		}
+		if score > 0 {
+			found = append(found, scored{item: item, score: score})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return preferBank(found[i].item, found[j].item)
+	})
+
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+
+	result := make([]domain.Bank, len(found))
+	for i, f := range found {
+		result[i] = f.item.bank
+	}
+	return result
+}
+
+func matchScore(name, query string) int {
+	switch {
+	case name == query:
+		return scoreExact
+	case strings.HasPrefix(name, query):
+		return scorePrefix
+	case strings.HasPrefix(query, name+" "):
+		return scoreQueryPrefix
+	case strings.Contains(name, query):
+		return scoreContains
+	}
+	return 0
+}
+
+// preferBank выбирает головную кредитную организацию и более короткое название
+// среди одинаково подходящих кандидатов (например, ПАО Сбербанк вместо его филиалов).
+func preferBank(candidate, current *indexedBank) bool {
+	if current == nil {
+		return true
+	}
+	candidateHead := candidate.bank.ParticipantType == participantTypeCreditOrg
+	currentHead := current.bank.ParticipantType == participantTypeCreditOrg
+	if candidateHead != currentHead {
+		return candidateHead
+	}
+	if len(candidate.normalizedName) != len(current.normalizedName) {
+		return len(candidate.normalizedName) < len(current.normalizedName)
+	}
+	return candidate.bank.BIC < current.bank.BIC
+}
+
+func normalizeBankName(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "ё", "е")
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := words[:0]
+	for _, w := range words {
+		if _, ok := legalForms[w]; ok {
+			continue
+		}
+		result = append(result, w)
+	}
+	return strings.Join(result, " ")
+}
+
+var _ IBankDirectory = (*ED807Directory)(nil)
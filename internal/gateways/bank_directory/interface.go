@@ -0,0 +1,13 @@
+package bank_directory
+
+import "finance-backend/internal/domain"
+
+type IBankDirectory interface {
+	GetByBIC(bic string) (*domain.Bank, bool)
+
+	// FindByName подбирает банк по написанию названия ("Сбербанк", "ПАО Сбербанк").
+	// Если название подходит нескольким банкам, банк не найден.
+	FindByName(name string) (*domain.Bank, bool)
+
+	Search(query string, limit int) []domain.Bank
+}
@@ -0,0 +1,351 @@
+package file_gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"finance-backend/pkg/logger"
+)
+
+// FSGateway хранит объекты в локальной директории: <root>/<bucket>/<key>.
+// Нужен для разработки и CI, где нет MinIO. Подписанные ссылки ведут на само
+// приложение, которое обслуживает их через ServeHTTP.
+type FSGateway struct {
+	root       string
+	publicURL  string
+	signingKey []byte
+	log        *logger.Logger
+}
+
+// NewFSGateway создает хранилище в root. publicURL — внешний адрес, по которому
+// смонтирован ServeHTTP, signingKey — секрет для подписи ссылок.
+func NewFSGateway(root, publicURL string, signingKey []byte, log *logger.Logger) (*FSGateway, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, err
+	}
+	return &FSGateway{
+		root:       abs,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		signingKey: signingKey,
+		log:        log,
+	}, nil
+}
+
+func (g *FSGateway) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, int64, error) {
+	path, err := g.objectPath(bucket, key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, ErrObjectNotFound
+		}
+		g.log.Error(ctx, "fs_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+		return nil, 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, 0, ErrObjectNotFound
+	}
+
+	return f, info.Size(), nil
+}
+
+func (g *FSGateway) UploadObject(ctx context.Context, bucket, key string, body io.ReadSeeker, size int64, contentType string) (*UploadResult, error) {
+	return g.writeObject(ctx, bucket, key, body, size)
+}
+
+func (g *FSGateway) DeleteObject(ctx context.Context, bucket, key string) error {
+	path, err := g.objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	// Как и S3, удаление отсутствующего объекта не считается ошибкой.
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		g.log.Error(ctx, "fs_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+		return err
+	}
+	return nil
+}
+
+func (g *FSGateway) StatObject(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	body, size, err := g.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{
+		Size: size,
+		ETag: `"` + hex.EncodeToString(hash.Sum(nil)) + `"`,
+	}, nil
+}
+
+func (g *FSGateway) PresignGetObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	return g.presign(http.MethodGet, bucket, key, "", 0, expires)
+}
+
+func (g *FSGateway) PresignPutObject(ctx context.Context, bucket, key, contentType string, size int64, expires time.Duration) (string, error) {
+	return g.presign(http.MethodPut, bucket, key, contentType, size, expires)
+}
+
+func (g *FSGateway) ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectSummary, error) {
+	bucketDir, err := g.bucketPath(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []ObjectSummary
+	err = filepath.WalkDir(bucketDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Бакета еще нет: в нем ничего не загружали.
+			if path == bucketDir && errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		// Незавершенные загрузки writeObject объектами не считаются.
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(bucketDir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectSummary{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		g.log.Error(ctx, "fs_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "prefix": prefix})
+		return nil, err
+	}
+	return objects, nil
+}
+
+// ServeHTTP обслуживает подписанные ссылки. Ожидает путь вида /<bucket>/<key>,
+// поэтому монтируется через http.StripPrefix.
+func (g *FSGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if _, err := g.objectPath(bucket, key); err != nil {
+		http.Error(w, "invalid object key", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		http.Error(w, "link expired", http.StatusForbidden)
+		return
+	}
+
+	contentType := ""
+	var size int64
+	if r.Method == http.MethodPut {
+		contentType = r.Header.Get("Content-Type")
+		if size, err = strconv.ParseInt(query.Get("size"), 10, 64); err != nil {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+	}
+	expected := g.signature(r.Method, bucket, key, contentType, size, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		body, size, err := g.GetObject(r.Context(), bucket, key)
+		if errors.Is(err, ErrObjectNotFound) {
+			http.Error(w, "object not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "storage error", http.StatusInternalServerError)
+			return
+		}
+		defer body.Close()
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		io.Copy(w, body)
+	case http.MethodPut:
+		if r.ContentLength < 0 {
+			http.Error(w, "content length required", http.StatusLengthRequired)
+			return
+		}
+		// Как и S3, принимаем только объект подписанного размера.
+		if r.ContentLength > size {
+			http.Error(w, "object too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if r.ContentLength != size {
+			http.Error(w, "content length does not match the signed size", http.StatusBadRequest)
+			return
+		}
+		result, err := g.writeObject(r.Context(), bucket, key, r.Body, r.ContentLength)
+		if err != nil {
+			http.Error(w, "storage error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", result.ETag)
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeObject пишет объект во временный файл рядом с целевым и переименовывает его,
+// поэтому читатели никогда не видят частично записанный объект.
+func (g *FSGateway) writeObject(ctx context.Context, bucket, key string, body io.Reader, size int64) (*UploadResult, error) {
+	path, err := g.objectPath(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		g.log.Error(ctx, "fs_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		g.log.Error(ctx, "fs_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+		return nil, err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hash), body)
+	if err == nil && written != size {
+		err = fmt.Errorf("size mismatch: expected %d bytes, got %d", size, written)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, path)
+	}
+	if err != nil {
+		g.log.Error(ctx, "fs_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+		return nil, err
+	}
+
+	return &UploadResult{
+		Bucket: bucket,
+		Key:    key,
+		Size:   written,
+		// Как и у S3 для обычной загрузки: MD5 содержимого в кавычках.
+		ETag: `"` + hex.EncodeToString(hash.Sum(nil)) + `"`,
+	}, nil
+}
+
+func (g *FSGateway) presign(method, bucket, key, contentType string, size int64, expires time.Duration) (string, error) {
+	if _, err := g.objectPath(bucket, key); err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(expires).Unix()
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	if method == http.MethodPut {
+		query.Set("size", strconv.FormatInt(size, 10))
+	}
+	query.Set("signature", g.signature(method, bucket, key, contentType, size, expiresAt))
+
+	return fmt.Sprintf("%s/%s/%s?%s", g.publicURL, url.PathEscape(bucket), strings.Join(segments, "/"), query.Encode()), nil
+}
+
+func (g *FSGateway) signature(method, bucket, key, contentType string, size, expiresAt int64) string {
+	mac := hmac.New(sha256.New, g.signingKey)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%d\n%d", method, bucket, key, contentType, size, expiresAt)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// objectPath строит путь к объекту и не позволяет ключу выйти за пределы бакета.
+func (g *FSGateway) objectPath(bucket, key string) (string, error) {
+	bucketDir, err := g.bucketPath(bucket)
+	if err != nil {
+		return "", err
+	}
+	if key == "" || strings.ContainsAny(key, `\`+"\x00") || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", ErrInvalidKey
+		}
+		// Префикс временных файлов зарезервирован за writeObject.
+		if strings.HasPrefix(segment, ".upload-") {
+			return "", ErrInvalidKey
+		}
+	}
+
+	path := filepath.Join(bucketDir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, bucketDir+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+	return path, nil
+}
+
+// bucketPath возвращает директорию бакета.
+func (g *FSGateway) bucketPath(bucket string) (string, error) {
+	if bucket == "" || bucket == "." || bucket == ".." || strings.ContainsAny(bucket, `/\`+"\x00") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(g.root, bucket), nil
+}
+
+var (
+	_ IFileGateway = (*FSGateway)(nil)
+	_ http.Handler = (*FSGateway)(nil)
+)
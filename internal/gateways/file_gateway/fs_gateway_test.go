@@ -0,0 +1,77 @@
+package file_gateway_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"finance-backend/internal/gateways/file_gateway"
+	"finance-backend/internal/gateways/file_gateway/gatewaytest"
+	"finance-backend/pkg/logger"
+)
+
+func TestFSGatewayContract(t *testing.T) {
+	gw, err := file_gateway.NewFSGateway(t.TempDir(), "http://localhost:8089/api/v1/files", []byte("contract-signing-key"), logger.NewLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gatewaytest.RunContractSuite(t, gw, "attachments")
+}
+
+func TestFSGatewayPresignedPutSize(t *testing.T) {
+	gw, err := file_gateway.NewFSGateway(t.TempDir(), "http://files.test", []byte("size-signing-key"), logger.NewLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	put := func(link, contentType string, body []byte) int {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodPut, parsed.RequestURI(), bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	link, err := gw.PresignPutObject(ctx, "attachments", "users/a/doc.pdf", "application/pdf", 5, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		link string
+		body []byte
+		want int
+	}{
+		{"larger than signed", link, []byte("0123456789"), http.StatusRequestEntityTooLarge},
+		{"smaller than signed", link, []byte("012"), http.StatusBadRequest},
+		{"size tampered", strings.Replace(link, "size=5", "size=10", 1), []byte("0123456789"), http.StatusForbidden},
+		{"signed size", link, []byte("01234"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := put(tt.link, "application/pdf", tt.body); got != tt.want {
+				t.Fatalf("PUT returned %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	info, err := gw.StatObject(ctx, "attachments", "users/a/doc.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != 5 {
+		t.Fatalf("stored %d bytes, want 5", info.Size)
+	}
+}
@@ -0,0 +1,280 @@
+// Package gatewaytest содержит общий контракт IFileGateway: набор проверок,
+// которые должна проходить любая реализация файлового хранилища.
+package gatewaytest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"finance-backend/internal/gateways/file_gateway"
+
+	"github.com/google/uuid"
+)
+
+// contractCase — одна проверка контракта. Бакет должен существовать заранее.
+type contractCase struct {
+	name string
+	run  func(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error
+}
+
+// cases — проверки контракта в порядке выполнения.
+var cases = []contractCase{
+	{name: "upload_and_get", run: checkUploadAndGet},
+	{name: "overwrite", run: checkOverwrite},
+	{name: "nested_key", run: checkNestedKey},
+	{name: "get_missing", run: checkGetMissing},
+	{name: "delete", run: checkDelete},
+	{name: "delete_missing", run: checkDeleteMissing},
+	{name: "stat", run: checkStat},
+	{name: "stat_missing", run: checkStatMissing},
+	{name: "presign", run: checkPresign},
+	{name: "list", run: checkList},
+}
+
+// RunContractSuite прогоняет все проверки как подтесты.
+func RunContractSuite(t *testing.T, gw file_gateway.IFileGateway, bucket string) {
+	t.Helper()
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.run(context.Background(), gw, bucket); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func checkUploadAndGet(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	key := newKey("upload")
+	content := []byte("contract payload " + key)
+	defer gw.DeleteObject(ctx, bucket, key)
+
+	result, err := upload(ctx, gw, bucket, key, content)
+	if err != nil {
+		return err
+	}
+	if result.Bucket != bucket || result.Key != key {
+		return fmt.Errorf("upload result points to %s/%s, want %s/%s", result.Bucket, result.Key, bucket, key)
+	}
+	if result.Size != int64(len(content)) {
+		return fmt.Errorf("upload result size %d, want %d", result.Size, len(content))
+	}
+	if want := etag(content); result.ETag != want {
+		return fmt.Errorf("etag %s, want %s", result.ETag, want)
+	}
+
+	return expectContent(ctx, gw, bucket, key, content)
+}
+
+func checkOverwrite(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	key := newKey("overwrite")
+	defer gw.DeleteObject(ctx, bucket, key)
+
+	if _, err := upload(ctx, gw, bucket, key, []byte("first version, longer than the second")); err != nil {
+		return err
+	}
+	second := []byte("second")
+	if _, err := upload(ctx, gw, bucket, key, second); err != nil {
+		return err
+	}
+
+	return expectContent(ctx, gw, bucket, key, second)
+}
+
+func checkNestedKey(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	key := newKey("users/contract/transactions/1") + ".pdf"
+	content := []byte("%PDF-1.4 nested")
+	defer gw.DeleteObject(ctx, bucket, key)
+
+	if _, err := upload(ctx, gw, bucket, key, content); err != nil {
+		return err
+	}
+	return expectContent(ctx, gw, bucket, key, content)
+}
+
+func checkGetMissing(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	body, _, err := gw.GetObject(ctx, bucket, newKey("missing"))
+	if err == nil {
+		body.Close()
+		return errors.New("get of missing object succeeded")
+	}
+	if !errors.Is(err, file_gateway.ErrObjectNotFound) {
+		return fmt.Errorf("get of missing object returned %v, want ErrObjectNotFound", err)
+	}
+	return nil
+}
+
+func checkDelete(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	key := newKey("delete")
+	if _, err := upload(ctx, gw, bucket, key, []byte("to be deleted")); err != nil {
+		return err
+	}
+	if err := gw.DeleteObject(ctx, bucket, key); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	body, _, err := gw.GetObject(ctx, bucket, key)
+	if err == nil {
+		body.Close()
+		return errors.New("object is still readable after delete")
+	}
+	if !errors.Is(err, file_gateway.ErrObjectNotFound) {
+		return fmt.Errorf("get after delete returned %v, want ErrObjectNotFound", err)
+	}
+	return nil
+}
+
+func checkDeleteMissing(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	if err := gw.DeleteObject(ctx, bucket, newKey("missing")); err != nil {
+		return fmt.Errorf("delete of missing object: %w", err)
+	}
+	return nil
+}
+
+func checkStat(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	key := newKey("stat")
+	content := []byte("stat payload")
+	defer gw.DeleteObject(ctx, bucket, key)
+
+	if _, err := upload(ctx, gw, bucket, key, content); err != nil {
+		return err
+	}
+
+	info, err := gw.StatObject(ctx, bucket, key)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", key, err)
+	}
+	if info.Size != int64(len(content)) {
+		return fmt.Errorf("stat size %d, want %d", info.Size, len(content))
+	}
+	if want := etag(content); info.ETag != want {
+		return fmt.Errorf("stat etag %s, want %s", info.ETag, want)
+	}
+	return nil
+}
+
+func checkStatMissing(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	_, err := gw.StatObject(ctx, bucket, newKey("missing"))
+	if !errors.Is(err, file_gateway.ErrObjectNotFound) {
+		return fmt.Errorf("stat of missing object returned %v, want ErrObjectNotFound", err)
+	}
+	return nil
+}
+
+func checkPresign(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	key := newKey("presign")
+
+	getURL, err := gw.PresignGetObject(ctx, bucket, key, time.Minute)
+	if err != nil {
+		return fmt.Errorf("presign get: %w", err)
+	}
+	putURL, err := gw.PresignPutObject(ctx, bucket, key, "application/pdf", 1024, time.Minute)
+	if err != nil {
+		return fmt.Errorf("presign put: %w", err)
+	}
+
+	for _, raw := range []string{getURL, putURL} {
+		parsed, err := url.Parse(raw)
+		if err != nil || !parsed.IsAbs() {
+			return fmt.Errorf("presigned url %q is not absolute", raw)
+		}
+		if !strings.Contains(parsed.Path, key) {
+			return fmt.Errorf("presigned url %q does not point to %s", raw, key)
+		}
+	}
+	if getURL == putURL {
+		return errors.New("get and put urls are identical")
+	}
+	return nil
+}
+
+func checkList(ctx context.Context, gw file_gateway.IFileGateway, bucket string) error {
+	prefix := newKey("list") + "/"
+	content := []byte("list payload")
+	keys := []string{prefix + "a.pdf", prefix + "nested/b.pdf"}
+	for _, key := range keys {
+		defer gw.DeleteObject(ctx, bucket, key)
+		if _, err := upload(ctx, gw, bucket, key, content); err != nil {
+			return err
+		}
+	}
+
+	started := time.Now().Add(-time.Minute)
+	objects, err := gw.ListObjects(ctx, bucket, prefix)
+	if err != nil {
+		return fmt.Errorf("list %s: %w", prefix, err)
+	}
+	if len(objects) != len(keys) {
+		return fmt.Errorf("list %s returned %d objects, want %d", prefix, len(objects), len(keys))
+	}
+	found := map[string]bool{}
+	for _, object := range objects {
+		if object.Size != int64(len(content)) {
+			return fmt.Errorf("list size of %s is %d, want %d", object.Key, object.Size, len(content))
+		}
+		if object.LastModified.Before(started) {
+			return fmt.Errorf("list last modified of %s is %s, want recent", object.Key, object.LastModified)
+		}
+		found[object.Key] = true
+	}
+	for _, key := range keys {
+		if !found[key] {
+			return fmt.Errorf("list %s did not return %s", prefix, key)
+		}
+	}
+
+	objects, err = gw.ListObjects(ctx, bucket, newKey("missing")+"/")
+	if err != nil {
+		return fmt.Errorf("list of empty prefix: %w", err)
+	}
+	if len(objects) != 0 {
+		return fmt.Errorf("list of empty prefix returned %d objects", len(objects))
+	}
+	return nil
+}
+
+func upload(ctx context.Context, gw file_gateway.IFileGateway, bucket, key string, content []byte) (*file_gateway.UploadResult, error) {
+	result, err := gw.UploadObject(ctx, bucket, key, bytes.NewReader(content), int64(len(content)), "application/octet-stream")
+	if err != nil {
+		return nil, fmt.Errorf("upload %s: %w", key, err)
+	}
+	return result, nil
+}
+
+func expectContent(ctx context.Context, gw file_gateway.IFileGateway, bucket, key string, want []byte) error {
+	body, size, err := gw.GetObject(ctx, bucket, key)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", key, err)
+	}
+	defer body.Close()
+
+	got, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", key, err)
+	}
+	if size != int64(len(want)) {
+		return fmt.Errorf("get %s reported size %d, want %d", key, size, len(want))
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("get %s returned %q, want %q", key, got, want)
+	}
+	return nil
+}
+
+func newKey(prefix string) string {
+	return "contract/" + prefix + "/" + uuid.New().String()
+}
+
+func etag(content []byte) string {
+	sum := md5.Sum(content)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
@@ -2,7 +2,17 @@ package file_gateway
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
+)
+
+var (
+	// ErrObjectNotFound возвращается GetObject, если объекта с таким ключом нет.
+	ErrObjectNotFound = errors.New("object not found")
+
+	// ErrInvalidKey возвращается для ключей и бакетов, которые нельзя безопасно сохранить.
+	ErrInvalidKey = errors.New("invalid object key")
 )
 
 type IFileGateway interface {
@@ -11,6 +21,34 @@ type IFileGateway interface {
 	UploadObject(ctx context.Context, bucket, key string, body io.ReadSeeker, size int64, contentType string) (*UploadResult, error)
 
 	DeleteObject(ctx context.Context, bucket, key string) error
+
+	// StatObject возвращает метаданные объекта или ErrObjectNotFound.
+	StatObject(ctx context.Context, bucket, key string) (*ObjectInfo, error)
+
+	// PresignGetObject выдает ссылку на скачивание объекта, действующую expires.
+	PresignGetObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error)
+
+	// PresignPutObject выдает ссылку на загрузку объекта напрямую в хранилище.
+	// Загрузка по ссылке принимается только с указанным Content-Type и размером ровно size байт:
+	// размер входит в подпись, поэтому загрузить по ссылке файл больше заявленного нельзя.
+	PresignPutObject(ctx context.Context, bucket, key, contentType string, size int64, expires time.Duration) (string, error)
+
+	// ListObjects возвращает объекты бакета, ключи которых начинаются с prefix.
+	ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectSummary, error)
+}
+
+type ObjectInfo struct {
+	Size int64
+	ETag string
+	// ContentType может быть пустым, если хранилище его не сохраняет.
+	ContentType string
+}
+
+// ObjectSummary — объект в списке ListObjects.
+type ObjectSummary struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
 }
 
 type UploadResult struct {
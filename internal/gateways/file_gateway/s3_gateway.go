@@ -3,10 +3,12 @@ package file_gateway
 import (
 	"context"
 	"io"
+	"time"
 
 	"finance-backend/pkg/logger"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
@@ -30,6 +32,9 @@ func (g *S3Gateway) GetObject(ctx context.Context, bucket, key string) (io.ReadC
 	}
 	result, err := g.s3Client.GetObjectWithContext(ctx, input)
 	if err != nil {
+		if isNotFound(err) {
+			return nil, 0, ErrObjectNotFound
+		}
 		g.log.Error(ctx, "s3_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
 		return nil, 0, err
 	}
@@ -63,5 +68,89 @@ func (g *S3Gateway) DeleteObject(ctx context.Context, bucket, key string) error
 		Key:    aws.String(key),
 	}
 	_, err := g.s3Client.DeleteObjectWithContext(ctx, input)
+	if err != nil {
+		g.log.Error(ctx, "s3_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+	}
 	return err
 }
+
+func (g *S3Gateway) StatObject(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	result, err := g.s3Client.HeadObjectWithContext(ctx, input)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrObjectNotFound
+		}
+		g.log.Error(ctx, "s3_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+		return nil, err
+	}
+	return &ObjectInfo{
+		Size:        aws.Int64Value(result.ContentLength),
+		ETag:        aws.StringValue(result.ETag),
+		ContentType: aws.StringValue(result.ContentType),
+	}, nil
+}
+
+func (g *S3Gateway) PresignGetObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	req, _ := g.s3Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	url, err := req.Presign(expires)
+	if err != nil {
+		g.log.Error(ctx, "s3_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+		return "", err
+	}
+	return url, nil
+}
+
+func (g *S3Gateway) PresignPutObject(ctx context.Context, bucket, key, contentType string, size int64, expires time.Duration) (string, error) {
+	// Content-Type и Content-Length входят в подпись, поэтому загрузить по ссылке объект
+	// другого типа или размера нельзя.
+	req, _ := g.s3Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	})
+	url, err := req.Presign(expires)
+	if err != nil {
+		g.log.Error(ctx, "s3_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "key": key})
+		return "", err
+	}
+	return url, nil
+}
+
+func (g *S3Gateway) ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectSummary, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	var objects []ObjectSummary
+	err := g.s3Client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			objects = append(objects, ObjectSummary{
+				Key:          aws.StringValue(object.Key),
+				Size:         aws.Int64Value(object.Size),
+				LastModified: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		g.log.Error(ctx, "s3_operation_error", map[string]interface{}{"error": err.Error(), "bucket": bucket, "prefix": prefix})
+		return nil, err
+	}
+	return objects, nil
+}
+
+// isNotFound распознает отсутствие объекта: GetObject отдает NoSuchKey, а HeadObject без тела — NotFound.
+func isNotFound(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && (aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == "NotFound")
+}
+
+var _ IFileGateway = (*S3Gateway)(nil)
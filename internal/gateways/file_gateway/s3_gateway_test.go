@@ -0,0 +1,37 @@
+package file_gateway_test
+
+import (
+	"os"
+	"testing"
+
+	"finance-backend/internal/gateways/file_gateway"
+	"finance-backend/internal/gateways/file_gateway/gatewaytest"
+	"finance-backend/pkg/logger"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// TestS3GatewayContract запускается только против настоящего S3/MinIO:
+// S3_CONTRACT_BUCKET — существующий бакет, адрес и ключи берутся из S3_URL,
+// S3_ROOT_USER и S3_ROOT_PASSWORD, как в конфигурации приложения.
+func TestS3GatewayContract(t *testing.T) {
+	bucket := os.Getenv("S3_CONTRACT_BUCKET")
+	if bucket == "" {
+		t.Skip("S3_CONTRACT_BUCKET is not set")
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(os.Getenv("S3_URL")),
+		Credentials:      credentials.NewStaticCredentials(os.Getenv("S3_ROOT_USER"), os.Getenv("S3_ROOT_PASSWORD"), ""),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+		Region:           aws.String("us-east-1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gatewaytest.RunContractSuite(t, file_gateway.NewS3Gateway(sess, logger.NewLogger()), bucket)
+}
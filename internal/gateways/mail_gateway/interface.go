@@ -0,0 +1,14 @@
+package mail_gateway
+
+import "context"
+
+// Message — письмо в виде обычного текста.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type IMailGateway interface {
+	Send(ctx context.Context, message Message) error
+}
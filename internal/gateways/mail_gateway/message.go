@@ -0,0 +1,48 @@
+package mail_gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net/mail"
+	"time"
+)
+
+// ErrInvalidAddress — адрес отправителя или получателя не разбирается.
+var ErrInvalidAddress = errors.New("invalid mail address")
+
+// buildMessage собирает письмо RFC 5322 в UTF-8. Тело кодируется base64,
+// чтобы кириллица доходила без искажений через любые серверы.
+func buildMessage(from string, message Message) ([]byte, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, ErrInvalidAddress
+	}
+	toAddr, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return nil, ErrInvalidAddress
+	}
+
+	var b bytes.Buffer
+	header := func(key, value string) {
+		b.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", fromAddr.String())
+	header("To", toAddr.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "base64")
+	b.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(message.Body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package mail_gateway
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"finance-backend/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// OutboxGateway не отправляет письма, а складывает их файлами .eml в каталог.
+// Нужен для локальной разработки и тестов: письмо можно открыть почтовым клиентом.
+type OutboxGateway struct {
+	dir  string
+	from string
+	log  *logger.Logger
+}
+
+func NewOutboxGateway(dir, from string, log *logger.Logger) (*OutboxGateway, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &OutboxGateway{dir: dir, from: from, log: log}, nil
+}
+
+func (g *OutboxGateway) Send(ctx context.Context, message Message) error {
+	body, err := buildMessage(g.from, message)
+	if err != nil {
+		return err
+	}
+
+	// Имя начинается со времени, чтобы письма в каталоге шли в порядке отправки.
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), uuid.NewString())
+	path := filepath.Join(g.dir, name)
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		return err
+	}
+
+	g.log.Info(ctx, "mail_saved_to_outbox", map[string]interface{}{"to": message.To, "subject": message.Subject, "path": path})
+	return nil
+}
+
+var _ IMailGateway = (*OutboxGateway)(nil)
@@ -0,0 +1,47 @@
+package mail_gateway
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPGateway отправляет письма через SMTP-сервер. Если сервер поддерживает STARTTLS,
+// соединение шифруется; авторизация выполняется, только если задан пользователь.
+type SMTPGateway struct {
+	addr     string
+	host     string
+	from     string
+	username string
+	password string
+}
+
+func NewSMTPGateway(host string, port int, username, password, from string) *SMTPGateway {
+	return &SMTPGateway{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+func (g *SMTPGateway) Send(ctx context.Context, message Message) error {
+	var auth smtp.Auth
+	if g.username != "" {
+		auth = smtp.PlainAuth("", g.username, g.password, g.host)
+	}
+
+	body, err := buildMessage(g.from, message)
+	if err != nil {
+		return err
+	}
+	// SendMail принимает только адреса без имени: "Финансы <noreply@example.com>" → noreply@example.com.
+	from, _ := mail.ParseAddress(g.from)
+	to, _ := mail.ParseAddress(message.To)
+	return smtp.SendMail(g.addr, auth, from.Address, []string{to.Address}, body)
+}
+
+var _ IMailGateway = (*SMTPGateway)(nil)
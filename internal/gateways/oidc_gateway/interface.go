@@ -0,0 +1,15 @@
+package oidc_gateway
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+)
+
+type IOIDCGateway interface {
+	// AuthCodeURL возвращает адрес страницы входа провайдера с PKCE (S256) и nonce.
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error)
+
+	// Exchange обменивает код авторизации на ID-токен и проверяет его подпись,
+	// издателя, аудиторию и nonce.
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.OIDCIdentity, error)
+}
@@ -3,8 +3,13 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
 	"finance-backend/internal/domain/transaction"
+	"strconv"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type transactionRepository struct {
@@ -17,7 +22,44 @@ func NewTransactionRepository(db *sql.DB) transaction.Repository {
 	}
 }
 
-func (r *transactionRepository) GetTransactions(ctx context.Context, filter *transaction.TransactionFilter) ([]transaction.Transaction, error) {
+// scopeFilter — условие отбора записей области данных: параметр orgArg — ID организации
+// (0 — личный учет), loginArg — логин пользователя.
+func scopeFilter(alias string, orgArg, loginArg int) string {
+	org := "$" + strconv.Itoa(orgArg) + "::bigint"
+	login := "$" + strconv.Itoa(loginArg)
+	return "((" + org + " <> 0 AND " + alias + ".org_id = " + org + ") OR (" + org + " = 0 AND " + alias + ".org_id IS NULL AND " + alias + ".owner_login = " + login + "))"
+}
+
+// categoryScopeFilter — как scopeFilter, но общий справочник категорий доступен в любой области.
+func categoryScopeFilter(alias string, orgArg, loginArg int) string {
+	return "(" + scopeFilter(alias, orgArg, loginArg) + " OR (" + alias + ".org_id IS NULL AND " + alias + ".owner_login IS NULL))"
+}
+
+// versionColumns — общие столбцы transactions и transaction_versions.
+const versionColumns = `id, user_type, date_time, trans_type, amount, category_id, status_id,
+	sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone, comment,
+	org_id, owner_login, deleted_at, deleted_by`
+
+// transactionsAsOf — транзакции в текущем состоянии, если параметр asOfArg равен NULL,
+// иначе версии, действовавшие в этот момент.
+func transactionsAsOf(asOfArg int) string {
+	asOf := "$" + strconv.Itoa(asOfArg) + "::timestamptz"
+	return "(SELECT " + versionColumns + " FROM transactions WHERE " + asOf + " IS NULL" +
+		" UNION ALL SELECT " + versionColumns + " FROM transaction_versions" +
+		" WHERE valid_from <= " + asOf + " AND (valid_to IS NULL OR valid_to > " + asOf + "))"
+}
+
+// periodError превращает отказ триггера закрытого учетного периода (код FP001)
+// в domain.ErrPeriodClosed.
+func periodError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "FP001" {
+		return domain.ErrPeriodClosed
+	}
+	return err
+}
+
+func (r *transactionRepository) GetTransactions(ctx context.Context, scope domain.DataScope, filter *transaction.TransactionFilter) ([]transaction.Transaction, error) {
 	query := `
 		SELECT 
 			t.id, 
@@ -33,18 +75,20 @@ func (r *transactionRepository) GetTransactions(ctx context.Context, filter *tra
 			t.comment,
 			c.name as category_name,
 			s.name as status_name
-		FROM transactions t
+		FROM ` + transactionsAsOf(12) + ` t
 		LEFT JOIN categories c ON t.category_id = c.id
 		LEFT JOIN transaction_statuses s ON t.status_id = s.id
-		WHERE ($1 = '' OR t.user_type = $1)
+		WHERE t.deleted_at IS NULL
+		AND ($1 = '' OR t.user_type = $1)
 		AND ($2 = '' OR t.trans_type = $2)
 		AND ($3 = '' OR t.sender_bank ILIKE '%' || $3 || '%')
 		AND ($4 = '' OR t.receiver_inn ILIKE '%' || $4 || '%')
-		AND ($5 = '' OR t.receiver_phone ILIKE '%' || $5 || '%')
+		AND ($5 = '' OR t.receiver_phone = $5)
 		AND ($6 = 0 OR t.category_id = $6)
 		AND ($7 = 0 OR t.status_id = $7)
 		AND ($8::timestamp IS NULL OR t.date_time >= $8)
 		AND ($9::timestamp IS NULL OR t.date_time <= $9)
+		AND ` + scopeFilter("t", 10, 11) + `
 		ORDER BY t.date_time DESC
 	`
 
@@ -58,6 +102,9 @@ func (r *transactionRepository) GetTransactions(ctx context.Context, filter *tra
 		filter.StatusID,
 		filter.DateFrom,
 		filter.DateTo,
+		scope.OrgID,
+		scope.Login,
+		filter.AsOf,
 	)
 	if err != nil {
 		return nil, err
@@ -91,15 +138,16 @@ func (r *transactionRepository) GetTransactions(ctx context.Context, filter *tra
 	return transactions, nil
 }
 
-func (r *transactionRepository) GetPreparedTransactions(ctx context.Context) ([]transaction.PreparedTransaction, error) {
+func (r *transactionRepository) GetPreparedTransactions(ctx context.Context, scope domain.DataScope) ([]transaction.PreparedTransaction, error) {
 	query := `
 		SELECT id, user_type, date_time, trans_type, amount, category_id, status_id,
 			   sender_bank, receiver_inn, receiver_phone, comment
-		FROM prepared_transactions
+		FROM prepared_transactions t
+		WHERE ` + scopeFilter("t", 1, 2) + `
 		ORDER BY date_time DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, scope.OrgID, scope.Login)
 	if err != nil {
 		return nil, err
 	}
@@ -130,10 +178,47 @@ func (r *transactionRepository) GetPreparedTransactions(ctx context.Context) ([]
 	return transactions, nil
 }
 
-func (r *transactionRepository) GetCategories(ctx context.Context) ([]transaction.Category, error) {
-	query := `SELECT id, name, type FROM categories ORDER BY name`
+func (r *transactionRepository) GetPreparedTransactionByID(ctx context.Context, scope domain.DataScope, id int) (*transaction.PreparedTransaction, error) {
+	query := `
+		SELECT id, user_type, date_time, trans_type, amount, category_id, status_id,
+			   sender_bank, COALESCE(sender_bank_bic, ''), COALESCE(counterparty_id, 0),
+			   receiver_inn, receiver_phone, comment
+		FROM prepared_transactions t
+		WHERE id = $1 AND ` + scopeFilter("t", 2, 3) + `
+	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	var t transaction.PreparedTransaction
+	err := r.db.QueryRowContext(ctx, query, id, scope.OrgID, scope.Login).Scan(
+		&t.ID,
+		&t.UserType,
+		&t.DateTime,
+		&t.TransType,
+		&t.Amount,
+		&t.CategoryID,
+		&t.StatusID,
+		&t.SenderBank,
+		&t.SenderBankBIC,
+		&t.CounterpartyID,
+		&t.ReceiverINN,
+		&t.ReceiverPhone,
+		&t.Comment,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, transaction.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *transactionRepository) GetCategories(ctx context.Context, scope domain.DataScope) ([]transaction.Category, error) {
+	query := `
+		SELECT id, name, COALESCE(type, ''), COALESCE(parent_id, 0), COALESCE(org_id, 0), COALESCE(owner_login, '')
+		FROM categories c WHERE c.archived_at IS NULL AND ` + categoryScopeFilter("c", 1, 2) + ` ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, scope.OrgID, scope.Login)
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +227,7 @@ func (r *transactionRepository) GetCategories(ctx context.Context) ([]transactio
 	var categories []transaction.Category
 	for rows.Next() {
 		var c transaction.Category
-		err := rows.Scan(&c.ID, &c.Name, &c.Type)
+		err := rows.Scan(&c.ID, &c.Name, &c.Type, &c.ParentID, &c.OrgID, &c.OwnerLogin)
 		if err != nil {
 			return nil, err
 		}
@@ -152,6 +237,33 @@ func (r *transactionRepository) GetCategories(ctx context.Context) ([]transactio
 	return categories, nil
 }
 
+func (r *transactionRepository) SetCategoryParent(ctx context.Context, id int, parentID int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE categories SET parent_id = NULLIF($2, 0), updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id, parentID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "FC001" {
+		return domain.ErrCategoryCycle
+	}
+	return err
+}
+
+func (r *transactionRepository) GetCategory(ctx context.Context, scope domain.DataScope, id int) (*transaction.Category, error) {
+	query := `
+		SELECT id, name, COALESCE(type, ''), COALESCE(parent_id, 0), COALESCE(org_id, 0), COALESCE(owner_login, ''), archived_at
+		FROM categories c WHERE c.id = $1 AND ` + categoryScopeFilter("c", 2, 3)
+
+	var c transaction.Category
+	err := r.db.QueryRowContext(ctx, query, id, scope.OrgID, scope.Login).Scan(
+		&c.ID, &c.Name, &c.Type, &c.ParentID, &c.OrgID, &c.OwnerLogin, &c.ArchivedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
 func (r *transactionRepository) GetTransactionStatuses(ctx context.Context) ([]transaction.TransactionStatus, error) {
 	query := `SELECT id, name, description FROM transaction_statuses ORDER BY id`
 
@@ -174,18 +286,156 @@ func (r *transactionRepository) GetTransactionStatuses(ctx context.Context) ([]t
 	return statuses, nil
 }
 
-func (r *transactionRepository) DeleteTransaction(ctx context.Context, id int) error {
-	query := `UPDATE transactions SET status_id = 6 WHERE id = $1` // 6 = "Платеж удален"
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+// trashColumns — столбцы транзакции t в порядке scanTrashed.
+const trashColumns = `
+	t.id, t.user_type, t.date_time, t.trans_type, t.amount, t.category_id, t.status_id,
+	t.sender_bank, t.receiver_inn, t.receiver_phone, t.comment,
+	COALESCE(t.org_id, 0), COALESCE(t.owner_login, ''), t.deleted_at, COALESCE(t.deleted_by, '')
+`
+
+// rowScanner — общее у *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTrashed(row rowScanner) (*transaction.Transaction, error) {
+	var t transaction.Transaction
+	err := row.Scan(
+		&t.ID,
+		&t.UserType,
+		&t.DateTime,
+		&t.TransType,
+		&t.Amount,
+		&t.CategoryID,
+		&t.StatusID,
+		&t.SenderBank,
+		&t.ReceiverINN,
+		&t.ReceiverPhone,
+		&t.Comment,
+		&t.OrgID,
+		&t.OwnerLogin,
+		&t.DeletedAt,
+		&t.DeletedBy,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, transaction.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, periodError(err)
+	}
+	return &t, nil
+}
+
+func (r *transactionRepository) DeleteTransaction(ctx context.Context, scope domain.DataScope, id int, deletedBy string) (*transaction.Transaction, error) {
+	query := `
+		UPDATE transactions t SET deleted_at = CURRENT_TIMESTAMP, deleted_by = NULLIF($4, '')
+		WHERE id = $1 AND t.deleted_at IS NULL AND ` + scopeFilter("t", 2, 3) + `
+		RETURNING ` + trashColumns
+	return scanTrashed(r.db.QueryRowContext(ctx, query, id, scope.OrgID, scope.Login, deletedBy))
+}
+
+func (r *transactionRepository) GetDeletedTransactions(ctx context.Context, scope domain.DataScope) ([]transaction.Transaction, error) {
+	query := `SELECT ` + trashColumns + ` FROM transactions t
+		WHERE t.deleted_at IS NOT NULL AND ` + scopeFilter("t", 1, 2) + `
+		ORDER BY t.deleted_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, scope.OrgID, scope.Login)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []transaction.Transaction
+	for rows.Next() {
+		t, err := scanTrashed(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *t)
+	}
+	return transactions, rows.Err()
+}
+
+func (r *transactionRepository) GetTransactionHistory(ctx context.Context, scope domain.DataScope, id int) ([]transaction.TransactionVersion, error) {
+	query := `SELECT t.version_id, t.valid_from, t.valid_to, ` + trashColumns + ` FROM transaction_versions t
+		WHERE t.id = $1 AND ` + scopeFilter("t", 2, 3) + `
+		ORDER BY t.valid_from, t.version_id`
+
+	rows, err := r.db.QueryContext(ctx, query, id, scope.OrgID, scope.Login)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []transaction.TransactionVersion
+	for rows.Next() {
+		var v transaction.TransactionVersion
+		err := rows.Scan(
+			&v.VersionID,
+			&v.ValidFrom,
+			&v.ValidTo,
+			&v.ID,
+			&v.UserType,
+			&v.DateTime,
+			&v.TransType,
+			&v.Amount,
+			&v.CategoryID,
+			&v.StatusID,
+			&v.SenderBank,
+			&v.ReceiverINN,
+			&v.ReceiverPhone,
+			&v.Comment,
+			&v.OrgID,
+			&v.OwnerLogin,
+			&v.DeletedAt,
+			&v.DeletedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *transactionRepository) RestoreTransaction(ctx context.Context, scope domain.DataScope, id int) (*transaction.Transaction, error) {
+	query := `
+		UPDATE transactions t SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND t.deleted_at IS NOT NULL AND ` + scopeFilter("t", 2, 3) + `
+		RETURNING ` + trashColumns
+	return scanTrashed(r.db.QueryRowContext(ctx, query, id, scope.OrgID, scope.Login))
+}
+
+func (r *transactionRepository) GetPurgeableTransactionIDs(ctx context.Context, before time.Time, limit int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM transactions WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *transactionRepository) PurgeTransaction(ctx context.Context, id int) (*transaction.Transaction, error) {
+	query := `DELETE FROM transactions t WHERE id = $1 AND t.deleted_at IS NOT NULL RETURNING ` + trashColumns
+	return scanTrashed(r.db.QueryRowContext(ctx, query, id))
 }
 
 func (r *transactionRepository) CreateTransaction(ctx context.Context, t *transaction.Transaction) error {
 	query := `
 		INSERT INTO transactions (
 			user_type, date_time, trans_type, amount, category_id, status_id,
-			sender_bank, receiver_inn, receiver_phone, comment
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone, comment,
+			org_id, owner_login
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, 0), $10, $11, $12, NULLIF($13, 0), NULLIF($14, ''))
 		RETURNING id
 	`
 
@@ -204,20 +454,25 @@ func (r *transactionRepository) CreateTransaction(ctx context.Context, t *transa
 		t.CategoryID,
 		t.StatusID,
 		t.SenderBank,
+		t.SenderBankBIC,
+		t.CounterpartyID,
 		t.ReceiverINN,
 		t.ReceiverPhone,
 		t.Comment,
+		t.OrgID,
+		t.OwnerLogin,
 	).Scan(&t.ID)
 
-	return err
+	return periodError(err)
 }
 
 func (r *transactionRepository) CreatePreparedTransaction(ctx context.Context, t *transaction.PreparedTransaction) error {
 	query := `
 		INSERT INTO prepared_transactions (
 			user_type, date_time, trans_type, amount, category_id, status_id,
-			sender_bank, receiver_inn, receiver_phone, comment
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone, comment,
+			org_id, owner_login
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, 0), $10, $11, $12, NULLIF($13, 0), NULLIF($14, ''))
 		RETURNING id
 	`
 
@@ -236,10 +491,188 @@ func (r *transactionRepository) CreatePreparedTransaction(ctx context.Context, t
 		t.CategoryID,
 		t.StatusID,
 		t.SenderBank,
+		t.SenderBankBIC,
+		t.CounterpartyID,
 		t.ReceiverINN,
 		t.ReceiverPhone,
 		t.Comment,
+		t.OrgID,
+		t.OwnerLogin,
 	).Scan(&t.ID)
 
+	return periodError(err)
+}
+
+func (r *transactionRepository) GetUnresolvedSenderBanks(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT sender_bank FROM transactions
+		WHERE sender_bank_bic IS NULL AND COALESCE(sender_bank, '') <> ''
+		UNION
+		SELECT sender_bank FROM prepared_transactions
+		WHERE sender_bank_bic IS NULL AND COALESCE(sender_bank, '') <> ''
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+func (r *transactionRepository) SetSenderBankBIC(ctx context.Context, senderBank string, bic string) error {
+	query := `UPDATE transactions SET sender_bank_bic = $1 WHERE sender_bank = $2 AND sender_bank_bic IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, bic, senderBank); err != nil {
+		return err
+	}
+
+	query = `UPDATE prepared_transactions SET sender_bank_bic = $1 WHERE sender_bank = $2 AND sender_bank_bic IS NULL`
+	_, err := r.db.ExecContext(ctx, query, bic, senderBank)
 	return err
 }
+
+func (r *transactionRepository) CreateReceiptTransaction(ctx context.Context, t *transaction.Transaction, receipt *transaction.FiscalReceipt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO transactions (
+			user_type, date_time, trans_type, amount, category_id, status_id,
+			sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone, comment,
+			org_id, owner_login
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, 0), $10, $11, $12, NULLIF($13, 0), NULLIF($14, ''))
+		RETURNING id
+	`
+
+	if t.StatusID == 0 {
+		t.StatusID = 1 // 1 = "Новая"
+	}
+
+	err = tx.QueryRowContext(ctx, query,
+		t.UserType,
+		t.DateTime,
+		t.TransType,
+		t.Amount,
+		t.CategoryID,
+		t.StatusID,
+		t.SenderBank,
+		t.SenderBankBIC,
+		t.CounterpartyID,
+		t.ReceiverINN,
+		t.ReceiverPhone,
+		t.Comment,
+		t.OrgID,
+		t.OwnerLogin,
+	).Scan(&t.ID)
+	if err != nil {
+		return periodError(err)
+	}
+
+	receipt.TransactionID = t.ID
+	query = `
+		INSERT INTO fiscal_receipts (
+			transaction_id, fiscal_drive, fiscal_document, fiscal_sign, operation, amount, receipt_time, seller_inn,
+			org_id, owner_login
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, 0), NULLIF($10, ''))
+		RETURNING id
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		receipt.TransactionID,
+		receipt.FiscalDrive,
+		receipt.FiscalDocument,
+		receipt.FiscalSign,
+		receipt.Operation,
+		receipt.Amount,
+		receipt.ReceiptTime,
+		receipt.SellerINN,
+		t.OrgID,
+		t.OwnerLogin,
+	).Scan(&receipt.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return domain.ErrReceiptAlreadyImported
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *transactionRepository) GetFiscalReceipt(ctx context.Context, scope domain.DataScope, fiscalDrive, fiscalDocument, fiscalSign string) (*transaction.FiscalReceipt, error) {
+	query := `
+		SELECT id, transaction_id, fiscal_drive, fiscal_document, fiscal_sign,
+			   operation, amount, receipt_time, COALESCE(seller_inn, '')
+		FROM fiscal_receipts r
+		WHERE fiscal_drive = $1 AND fiscal_document = $2 AND fiscal_sign = $3 AND ` + scopeFilter("r", 4, 5) + `
+	`
+
+	var receipt transaction.FiscalReceipt
+	err := r.db.QueryRowContext(ctx, query, fiscalDrive, fiscalDocument, fiscalSign, scope.OrgID, scope.Login).Scan(
+		&receipt.ID,
+		&receipt.TransactionID,
+		&receipt.FiscalDrive,
+		&receipt.FiscalDocument,
+		&receipt.FiscalSign,
+		&receipt.Operation,
+		&receipt.Amount,
+		&receipt.ReceiptTime,
+		&receipt.SellerINN,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+func (r *transactionRepository) GetSellerINNByFiscalDrive(ctx context.Context, scope domain.DataScope, fiscalDrive string) (string, error) {
+	query := `
+		SELECT seller_inn FROM fiscal_receipts r
+		WHERE fiscal_drive = $1 AND seller_inn IS NOT NULL AND ` + scopeFilter("r", 2, 3) + `
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var inn string
+	err := r.db.QueryRowContext(ctx, query, fiscalDrive, scope.OrgID, scope.Login).Scan(&inn)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return inn, err
+}
+
+func (r *transactionRepository) SuggestCategoryByINN(ctx context.Context, scope domain.DataScope, inn string, transType string) (int, error) {
+	query := `
+		SELECT category_id FROM transactions t
+		WHERE receiver_inn = $1 AND trans_type = $2 AND category_id IS NOT NULL
+			AND deleted_at IS NULL AND ` + scopeFilter("t", 3, 4) + `
+		GROUP BY category_id
+		ORDER BY COUNT(*) DESC, MAX(date_time) DESC
+		LIMIT 1
+	`
+
+	var categoryID int
+	err := r.db.QueryRowContext(ctx, query, inn, transType, scope.OrgID, scope.Login).Scan(&categoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return categoryID, err
+}
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- БИК банка отправителя по справочнику ED807; исходный текст остается в sender_bank
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS sender_bank_bic VARCHAR(9);
+ALTER TABLE prepared_transactions ADD COLUMN IF NOT EXISTS sender_bank_bic VARCHAR(9);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_sender_bank_bic ON transactions(sender_bank_bic);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_sender_bank_bic;
+ALTER TABLE prepared_transactions DROP COLUMN IF EXISTS sender_bank_bic;
+ALTER TABLE transactions DROP COLUMN IF EXISTS sender_bank_bic;
+-- +goose StatementEnd
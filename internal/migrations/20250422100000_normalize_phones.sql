@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Правила совпадают с validation.NormalizePhone: нераспознанные номера не меняются
+CREATE OR REPLACE FUNCTION normalize_ru_phone(phone TEXT) RETURNS TEXT AS $$
+DECLARE
+    has_plus BOOLEAN;
+    digits TEXT;
+BEGIN
+    IF phone IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    has_plus := left(btrim(phone), 1) = '+';
+    digits := regexp_replace(btrim(phone), '^\+', '');
+    IF digits !~ '^[0-9 ().-]+$' THEN
+        RETURN phone;
+    END IF;
+    digits := regexp_replace(digits, '[^0-9]', '', 'g');
+
+    IF length(digits) = 11 AND left(digits, 1) = '7' THEN
+        RETURN '+7' || substr(digits, 2);
+    ELSIF length(digits) = 11 AND left(digits, 1) = '8' AND NOT has_plus THEN
+        RETURN '+7' || substr(digits, 2);
+    ELSIF length(digits) = 10 AND NOT has_plus THEN
+        RETURN '+7' || digits;
+    END IF;
+
+    RETURN phone;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+UPDATE transactions SET receiver_phone = normalize_ru_phone(receiver_phone)
+WHERE receiver_phone IS DISTINCT FROM normalize_ru_phone(receiver_phone);
+
+UPDATE prepared_transactions SET receiver_phone = normalize_ru_phone(receiver_phone)
+WHERE receiver_phone IS DISTINCT FROM normalize_ru_phone(receiver_phone);
+
+UPDATE participants SET part_phone = normalize_ru_phone(part_phone)
+WHERE part_phone IS DISTINCT FROM normalize_ru_phone(part_phone);
+
+DROP FUNCTION normalize_ru_phone(TEXT);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_receiver_phone ON transactions(receiver_phone);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Исходное написание номеров не сохраняется, откатывается только индекс
+DROP INDEX IF EXISTS idx_transactions_receiver_phone;
+-- +goose StatementEnd
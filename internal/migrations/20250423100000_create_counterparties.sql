@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS counterparties (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    inn VARCHAR(12),
+    phone VARCHAR(20),
+    bank VARCHAR(255),
+    bank_bic VARCHAR(9),
+    account VARCHAR(20),
+    default_category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (inn IS NOT NULL OR phone IS NOT NULL)
+);
+
+-- Контрагент определяется по ИНН, а без ИНН — по телефону
+CREATE UNIQUE INDEX IF NOT EXISTS idx_counterparties_inn ON counterparties(inn) WHERE inn IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_counterparties_phone ON counterparties(phone) WHERE inn IS NULL;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS counterparty_id INTEGER REFERENCES counterparties(id) ON DELETE SET NULL;
+ALTER TABLE prepared_transactions ADD COLUMN IF NOT EXISTS counterparty_id INTEGER REFERENCES counterparties(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_counterparty_id ON transactions(counterparty_id);
+
+-- Заполняем справочник из уже проведенных платежей
+INSERT INTO counterparties (name, inn, phone, default_category_id)
+SELECT
+    receiver_inn,
+    receiver_inn,
+    (array_agg(receiver_phone ORDER BY date_time DESC) FILTER (WHERE COALESCE(receiver_phone, '') <> ''))[1],
+    mode() WITHIN GROUP (ORDER BY category_id)
+FROM (
+    SELECT receiver_inn, receiver_phone, date_time, category_id FROM transactions
+    UNION ALL
+    SELECT receiver_inn, receiver_phone, date_time, category_id FROM prepared_transactions
+) src
+WHERE COALESCE(receiver_inn, '') <> ''
+GROUP BY receiver_inn;
+
+INSERT INTO counterparties (name, phone, default_category_id)
+SELECT
+    receiver_phone,
+    receiver_phone,
+    mode() WITHIN GROUP (ORDER BY category_id)
+FROM (
+    SELECT receiver_inn, receiver_phone, category_id FROM transactions
+    UNION ALL
+    SELECT receiver_inn, receiver_phone, category_id FROM prepared_transactions
+) src
+WHERE COALESCE(receiver_inn, '') = '' AND COALESCE(receiver_phone, '') <> ''
+GROUP BY receiver_phone;
+
+UPDATE transactions t SET counterparty_id = c.id
+FROM counterparties c
+WHERE (COALESCE(t.receiver_inn, '') <> '' AND c.inn = t.receiver_inn)
+   OR (COALESCE(t.receiver_inn, '') = '' AND c.inn IS NULL AND c.phone = t.receiver_phone);
+
+UPDATE prepared_transactions t SET counterparty_id = c.id
+FROM counterparties c
+WHERE (COALESCE(t.receiver_inn, '') <> '' AND c.inn = t.receiver_inn)
+   OR (COALESCE(t.receiver_inn, '') = '' AND c.inn IS NULL AND c.phone = t.receiver_phone);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_counterparty_id;
+ALTER TABLE prepared_transactions DROP COLUMN IF EXISTS counterparty_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS counterparty_id;
+DROP TABLE IF EXISTS counterparties;
+-- +goose StatementEnd
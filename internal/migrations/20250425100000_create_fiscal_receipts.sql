@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS fiscal_receipts (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    fiscal_drive VARCHAR(16) NOT NULL,
+    fiscal_document VARCHAR(10) NOT NULL,
+    fiscal_sign VARCHAR(10) NOT NULL,
+    operation SMALLINT NOT NULL,
+    amount DECIMAL(15,5) NOT NULL,
+    receipt_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    seller_inn VARCHAR(12),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (fiscal_drive, fiscal_document, fiscal_sign)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fiscal_receipts_transaction_id ON fiscal_receipts(transaction_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS fiscal_receipts;
+-- +goose StatementEnd
@@ -0,0 +1,91 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Организации: юридические лица, учет которых ведут несколько пользователей.
+CREATE TABLE IF NOT EXISTS organizations (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    inn VARCHAR(12) UNIQUE,
+    created_by VARCHAR(255) REFERENCES users(login_name) ON DELETE SET NULL ON UPDATE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    org_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    login_name VARCHAR(255) NOT NULL REFERENCES users(login_name) ON DELETE CASCADE ON UPDATE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'accountant', 'member', 'viewer')),
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, login_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_login_name ON organization_members(login_name);
+
+-- Приглашения по email; хранится только SHA-256 токена из письма.
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    org_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'accountant', 'member', 'viewer')),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    invited_by VARCHAR(255) REFERENCES users(login_name) ON DELETE SET NULL ON UPDATE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    accepted_by VARCHAR(255) REFERENCES users(login_name) ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_org_id ON organization_invitations(org_id);
+
+-- Владелец данных: организация или пользователь. Записи, созданные до появления
+-- владельцев, остаются без владельца и видны в личном учете всех пользователей, как раньше.
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS org_id BIGINT REFERENCES organizations(id),
+    ADD COLUMN IF NOT EXISTS owner_login VARCHAR(255) REFERENCES users(login_name) ON UPDATE CASCADE,
+    ADD CONSTRAINT transactions_single_owner CHECK (org_id IS NULL OR owner_login IS NULL);
+
+ALTER TABLE prepared_transactions
+    ADD COLUMN IF NOT EXISTS org_id BIGINT REFERENCES organizations(id),
+    ADD COLUMN IF NOT EXISTS owner_login VARCHAR(255) REFERENCES users(login_name) ON UPDATE CASCADE,
+    ADD CONSTRAINT prepared_transactions_single_owner CHECK (org_id IS NULL OR owner_login IS NULL);
+
+-- Категории без владельца — общий справочник, доступный всем.
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS org_id BIGINT REFERENCES organizations(id),
+    ADD COLUMN IF NOT EXISTS owner_login VARCHAR(255) REFERENCES users(login_name) ON UPDATE CASCADE,
+    ADD CONSTRAINT categories_single_owner CHECK (org_id IS NULL OR owner_login IS NULL);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_org_id ON transactions(org_id, date_time);
+CREATE INDEX IF NOT EXISTS idx_transactions_owner_login ON transactions(owner_login, date_time);
+CREATE INDEX IF NOT EXISTS idx_prepared_transactions_org_id ON prepared_transactions(org_id);
+CREATE INDEX IF NOT EXISTS idx_prepared_transactions_owner_login ON prepared_transactions(owner_login);
+
+-- Сессия помнит выбранную организацию, чтобы обновление токенов ее сохраняло.
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS org_id BIGINT REFERENCES organizations(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS org_id;
+
+DROP INDEX IF EXISTS idx_prepared_transactions_owner_login;
+DROP INDEX IF EXISTS idx_prepared_transactions_org_id;
+DROP INDEX IF EXISTS idx_transactions_owner_login;
+DROP INDEX IF EXISTS idx_transactions_org_id;
+
+ALTER TABLE categories
+    DROP CONSTRAINT IF EXISTS categories_single_owner,
+    DROP COLUMN IF EXISTS owner_login,
+    DROP COLUMN IF EXISTS org_id;
+ALTER TABLE prepared_transactions
+    DROP CONSTRAINT IF EXISTS prepared_transactions_single_owner,
+    DROP COLUMN IF EXISTS owner_login,
+    DROP COLUMN IF EXISTS org_id;
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_single_owner,
+    DROP COLUMN IF EXISTS owner_login,
+    DROP COLUMN IF EXISTS org_id;
+
+DROP TABLE IF EXISTS organization_invitations;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
+-- +goose StatementEnd
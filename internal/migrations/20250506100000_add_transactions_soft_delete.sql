@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Удаленные транзакции остаются в корзине до окончательного удаления задачей очистки.
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS deleted_by VARCHAR(255);
+
+-- Выборки почти всегда идут по неудаленным строкам, корзина и очистка — по удаленным.
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions(deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_deleted_at;
+
+-- Без корзины удаленные транзакции снова стали бы видны.
+DELETE FROM transactions WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,104 @@
+-- +goose Up
+-- +goose StatementBegin
+-- История версий транзакций: каждая строка — состояние транзакции в интервале
+-- [valid_from, valid_to), valid_to IS NULL у действующей версии. Столбцы повторяют
+-- transactions, поэтому версии на момент времени подставляются в запросы вместо таблицы.
+-- Версии ведет триггер, внешних ключей нет: история переживает окончательное удаление
+-- транзакций, категорий и контрагентов.
+CREATE TABLE IF NOT EXISTS transaction_versions (
+    version_id BIGSERIAL PRIMARY KEY,
+    id INTEGER NOT NULL,
+    user_type VARCHAR(50) NOT NULL,
+    date_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    trans_type VARCHAR(50) NOT NULL,
+    amount DECIMAL(15,5) NOT NULL,
+    category_id INTEGER,
+    status_id INTEGER,
+    sender_bank VARCHAR(255),
+    sender_bank_bic VARCHAR(9),
+    counterparty_id INTEGER,
+    receiver_inn VARCHAR(12),
+    receiver_phone VARCHAR(20),
+    comment TEXT,
+    org_id BIGINT,
+    owner_login VARCHAR(255),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    deleted_by VARCHAR(255),
+    valid_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_to TIMESTAMP WITH TIME ZONE,
+    CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_versions_id ON transaction_versions(id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_transaction_versions_period ON transaction_versions(valid_from, valid_to);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transaction_versions_current ON transaction_versions(id) WHERE valid_to IS NULL;
+
+-- Закрывает действующую версию при изменении и удалении и открывает новую при создании
+-- и изменении. Время — начало транзакции БД, как у CURRENT_TIMESTAMP в запросах.
+CREATE OR REPLACE FUNCTION transactions_track_versions() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW IS NOT DISTINCT FROM OLD THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE transaction_versions SET valid_to = now()
+        WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        -- Несколько изменений в одной транзакции БД оставляют одну версию.
+        DELETE FROM transaction_versions
+        WHERE id = NEW.id AND valid_from = now() AND valid_to = now();
+
+        INSERT INTO transaction_versions (
+            id, user_type, date_time, trans_type, amount, category_id, status_id,
+            sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone,
+            comment, org_id, owner_login, deleted_at, deleted_by, valid_from
+        ) VALUES (
+            NEW.id, NEW.user_type, NEW.date_time, NEW.trans_type, NEW.amount, NEW.category_id, NEW.status_id,
+            NEW.sender_bank, NEW.sender_bank_bic, NEW.counterparty_id, NEW.receiver_inn, NEW.receiver_phone,
+            NEW.comment, NEW.org_id, NEW.owner_login, NEW.deleted_at, NEW.deleted_by, now()
+        );
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transactions_versions
+    AFTER INSERT OR UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION transactions_track_versions();
+
+-- Начальная история: с момента создания транзакции; у транзакций в корзине до переноса
+-- в корзину действует версия без отметки об удалении.
+INSERT INTO transaction_versions (
+    id, user_type, date_time, trans_type, amount, category_id, status_id,
+    sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone,
+    comment, org_id, owner_login, deleted_at, deleted_by, valid_from, valid_to
+)
+SELECT
+    id, user_type, date_time, trans_type, amount, category_id, status_id,
+    sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone,
+    comment, org_id, owner_login, NULL, NULL, LEAST(COALESCE(created_at, now()), deleted_at), deleted_at
+FROM transactions
+WHERE deleted_at IS NOT NULL;
+
+INSERT INTO transaction_versions (
+    id, user_type, date_time, trans_type, amount, category_id, status_id,
+    sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone,
+    comment, org_id, owner_login, deleted_at, deleted_by, valid_from
+)
+SELECT
+    id, user_type, date_time, trans_type, amount, category_id, status_id,
+    sender_bank, sender_bank_bic, counterparty_id, receiver_inn, receiver_phone,
+    comment, org_id, owner_login, deleted_at, deleted_by, COALESCE(deleted_at, created_at, now())
+FROM transactions;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS transactions_versions ON transactions;
+DROP FUNCTION IF EXISTS transactions_track_versions();
+DROP TABLE IF EXISTS transaction_versions;
+-- +goose StatementEnd
@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Учетные периоды (календарные месяцы по UTC) организации или личного учета. Запись
+-- появляется при первом закрытии периода; повторное открытие и закрытие обновляют ее.
+CREATE TABLE IF NOT EXISTS accounting_periods (
+    id BIGSERIAL PRIMARY KEY,
+    org_id BIGINT REFERENCES organizations(id) ON DELETE CASCADE,
+    owner_login VARCHAR(255) REFERENCES users(login_name) ON DELETE CASCADE ON UPDATE CASCADE,
+    month DATE NOT NULL CHECK (month = date_trunc('month', month)::date),
+    closed BOOLEAN NOT NULL DEFAULT TRUE,
+    closed_by VARCHAR(255) NOT NULL,
+    closed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reopened_by VARCHAR(255),
+    reopened_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT accounting_periods_single_owner CHECK ((org_id IS NULL) <> (owner_login IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounting_periods_org_month ON accounting_periods(org_id, month) WHERE org_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounting_periods_owner_month ON accounting_periods(owner_login, month) WHERE owner_login IS NOT NULL;
+
+-- Закрыт ли период, в который попадает транзакция владельца с датой date_time.
+CREATE OR REPLACE FUNCTION accounting_period_closed(p_org_id BIGINT, p_owner_login VARCHAR, p_date_time TIMESTAMPTZ)
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM accounting_periods p
+        WHERE p.closed
+            AND p.month = date_trunc('month', p_date_time AT TIME ZONE 'UTC')::date
+            AND ((p_org_id IS NOT NULL AND p.org_id = p_org_id)
+                OR (p_org_id IS NULL AND p.owner_login = p_owner_login))
+    );
+$$ LANGUAGE sql STABLE;
+
+-- Запрещает добавлять, изменять и удалять транзакции с датой в закрытом периоде их
+-- владельца. Разрешены изменения, которые не меняют отчеты за период: окончательное
+-- удаление из корзины, заполнение БИК банка отправителя по справочнику, переименование
+-- логина владельца и отвязка удаленного контрагента. Код ошибки FP001 приложение
+-- превращает в доменную ошибку PERIOD_CLOSED.
+CREATE OR REPLACE FUNCTION transactions_check_period() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.deleted_at IS NULL AND accounting_period_closed(OLD.org_id, OLD.owner_login, OLD.date_time) THEN
+            RAISE EXCEPTION 'accounting period % is closed', to_char(OLD.date_time AT TIME ZONE 'UTC', 'YYYY-MM')
+                USING ERRCODE = 'FP001';
+        END IF;
+        RETURN OLD;
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        IF to_jsonb(NEW) - ARRAY['sender_bank_bic', 'owner_login', 'counterparty_id']
+                = to_jsonb(OLD) - ARRAY['sender_bank_bic', 'owner_login', 'counterparty_id']
+            AND (NEW.counterparty_id IS NULL OR NEW.counterparty_id = OLD.counterparty_id) THEN
+            RETURN NEW;
+        END IF;
+        IF accounting_period_closed(OLD.org_id, OLD.owner_login, OLD.date_time) THEN
+            RAISE EXCEPTION 'accounting period % is closed', to_char(OLD.date_time AT TIME ZONE 'UTC', 'YYYY-MM')
+                USING ERRCODE = 'FP001';
+        END IF;
+    END IF;
+
+    IF accounting_period_closed(NEW.org_id, NEW.owner_login, NEW.date_time) THEN
+        RAISE EXCEPTION 'accounting period % is closed', to_char(NEW.date_time AT TIME ZONE 'UTC', 'YYYY-MM')
+            USING ERRCODE = 'FP001';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transactions_period_lock
+    BEFORE INSERT OR UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION transactions_check_period();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS transactions_period_lock ON transactions;
+DROP FUNCTION IF EXISTS transactions_check_period();
+DROP FUNCTION IF EXISTS accounting_period_closed(BIGINT, VARCHAR, TIMESTAMPTZ);
+DROP TABLE IF EXISTS accounting_periods;
+-- +goose StatementEnd
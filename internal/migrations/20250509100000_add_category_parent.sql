@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Подкатегории: parent_id ссылается на родительскую категорию того же типа. Категорию
+-- с подкатегориями удалить нельзя, пока подкатегории не перенесены.
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES categories(id),
+    ADD CONSTRAINT categories_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+-- Защита от циклов: новый родитель не может быть самой категорией или ее потомком.
+-- Код ошибки FC001 приложение превращает в доменную ошибку CATEGORY_CYCLE.
+CREATE OR REPLACE FUNCTION categories_check_cycle() RETURNS trigger AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF EXISTS (
+        WITH RECURSIVE ancestors AS (
+            SELECT c.id, c.parent_id FROM categories c WHERE c.id = NEW.parent_id
+            UNION
+            SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+        )
+        SELECT 1 FROM ancestors WHERE id = NEW.id
+    ) THEN
+        RAISE EXCEPTION 'category % cannot be a descendant of itself', NEW.id USING ERRCODE = 'FC001';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER categories_no_cycles
+    BEFORE INSERT OR UPDATE OF parent_id ON categories
+    FOR EACH ROW EXECUTE FUNCTION categories_check_cycle();
+
+-- Подкатегории общего справочника.
+INSERT INTO categories (name, type, parent_id)
+SELECT sub.name, parent.type, parent.id
+FROM categories parent
+CROSS JOIN (VALUES ('Такси'), ('Метро'), ('Топливо')) AS sub(name)
+WHERE parent.name = 'Транспорт' AND parent.org_id IS NULL AND parent.owner_login IS NULL
+    AND NOT EXISTS (SELECT 1 FROM categories c WHERE c.parent_id = parent.id AND c.name = sub.name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS categories_no_cycles ON categories;
+DROP FUNCTION IF EXISTS categories_check_cycle();
+DROP INDEX IF EXISTS idx_categories_parent_id;
+
+-- Подкатегории остаются обычными категориями.
+ALTER TABLE categories
+    DROP CONSTRAINT IF EXISTS categories_parent_not_self,
+    DROP COLUMN IF EXISTS parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Архивная категория не предлагается для новых операций, но остается в истории и отчетах.
+-- Категория, объединенная с другой, тоже архивируется: на нее ссылаются версии транзакций.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_categories_org_id ON categories(org_id) WHERE org_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_categories_owner_login ON categories(owner_login) WHERE owner_login IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_prepared_transactions_category_id ON prepared_transactions(category_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_versions_category_id ON transaction_versions(category_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transaction_versions_category_id;
+DROP INDEX IF EXISTS idx_prepared_transactions_category_id;
+DROP INDEX IF EXISTS idx_categories_owner_login;
+DROP INDEX IF EXISTS idx_categories_org_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS archived_at;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- КПП есть только у юридических лиц.
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS part_kpp VARCHAR(9);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE participants DROP COLUMN IF EXISTS part_kpp;
+-- +goose StatementEnd
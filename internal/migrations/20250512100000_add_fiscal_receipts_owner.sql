@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Чек принадлежит той же области, что и его транзакция: один и тот же чек могут
+-- импортировать разные организации и пользователи, каждый — один раз.
+ALTER TABLE fiscal_receipts
+    ADD COLUMN IF NOT EXISTS org_id BIGINT REFERENCES organizations(id),
+    ADD COLUMN IF NOT EXISTS owner_login VARCHAR(255) REFERENCES users(login_name) ON UPDATE CASCADE,
+    ADD CONSTRAINT fiscal_receipts_single_owner CHECK (org_id IS NULL OR owner_login IS NULL);
+
+UPDATE fiscal_receipts r
+SET org_id = t.org_id, owner_login = t.owner_login
+FROM transactions t
+WHERE t.id = r.transaction_id;
+
+ALTER TABLE fiscal_receipts DROP CONSTRAINT IF EXISTS fiscal_receipts_fiscal_drive_fiscal_document_fiscal_sign_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiscal_receipts_org_unique
+    ON fiscal_receipts(org_id, fiscal_drive, fiscal_document, fiscal_sign) WHERE org_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiscal_receipts_owner_unique
+    ON fiscal_receipts(COALESCE(owner_login, ''), fiscal_drive, fiscal_document, fiscal_sign) WHERE org_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_fiscal_receipts_fiscal_drive ON fiscal_receipts(fiscal_drive, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_fiscal_receipts_fiscal_drive;
+DROP INDEX IF EXISTS idx_fiscal_receipts_owner_unique;
+DROP INDEX IF EXISTS idx_fiscal_receipts_org_unique;
+
+ALTER TABLE fiscal_receipts
+    ADD CONSTRAINT fiscal_receipts_fiscal_drive_fiscal_document_fiscal_sign_key UNIQUE (fiscal_drive, fiscal_document, fiscal_sign);
+
+ALTER TABLE fiscal_receipts
+    DROP CONSTRAINT IF EXISTS fiscal_receipts_single_owner,
+    DROP COLUMN IF EXISTS owner_login,
+    DROP COLUMN IF EXISTS org_id;
+-- +goose StatementEnd
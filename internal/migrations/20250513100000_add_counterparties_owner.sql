@@ -0,0 +1,98 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Контрагенты принадлежат организации или пользователю, как транзакции. Контрагенты без
+-- владельца созданы раньше и, как прочие такие записи, остаются в личном учете.
+ALTER TABLE counterparties
+    ADD COLUMN IF NOT EXISTS org_id BIGINT REFERENCES organizations(id),
+    ADD COLUMN IF NOT EXISTS owner_login VARCHAR(255) REFERENCES users(login_name) ON UPDATE CASCADE,
+    ADD CONSTRAINT counterparties_single_owner CHECK (org_id IS NULL OR owner_login IS NULL);
+
+DROP INDEX IF EXISTS idx_counterparties_inn;
+DROP INDEX IF EXISTS idx_counterparties_phone;
+
+-- Контрагент переходит к владельцу транзакций, в которых он указан. Если его используют
+-- несколько организаций или пользователей, каждый следующий получает свою копию, а его
+-- транзакции, подготовленные платежи и история версий переводятся на нее. Перепривязка
+-- не меняет отчеты, поэтому блокировка закрытых периодов и запись версий на это время
+-- отключаются.
+ALTER TABLE transactions DISABLE TRIGGER transactions_period_lock;
+ALTER TABLE transactions DISABLE TRIGGER transactions_versions;
+
+DO $$
+DECLARE
+    used RECORD;
+    copy_id INTEGER;
+BEGIN
+    FOR used IN
+        SELECT counterparty_id, org_id, owner_login,
+            ROW_NUMBER() OVER (PARTITION BY counterparty_id ORDER BY org_id NULLS LAST, owner_login) AS n
+        FROM (
+            SELECT counterparty_id, org_id, owner_login FROM transactions
+            UNION
+            SELECT counterparty_id, org_id, owner_login FROM prepared_transactions
+        ) refs
+        WHERE counterparty_id IS NOT NULL AND (org_id IS NOT NULL OR owner_login IS NOT NULL)
+    LOOP
+        IF used.n = 1 THEN
+            UPDATE counterparties SET org_id = used.org_id, owner_login = used.owner_login
+            WHERE id = used.counterparty_id;
+            CONTINUE;
+        END IF;
+
+        INSERT INTO counterparties (name, inn, phone, bank, bank_bic, account, default_category_id,
+            org_id, owner_login, created_at, updated_at)
+        SELECT name, inn, phone, bank, bank_bic, account, default_category_id,
+            used.org_id, used.owner_login, created_at, updated_at
+        FROM counterparties WHERE id = used.counterparty_id
+        RETURNING id INTO copy_id;
+
+        UPDATE transactions SET counterparty_id = copy_id
+        WHERE counterparty_id = used.counterparty_id
+            AND org_id IS NOT DISTINCT FROM used.org_id AND owner_login IS NOT DISTINCT FROM used.owner_login;
+        UPDATE prepared_transactions SET counterparty_id = copy_id
+        WHERE counterparty_id = used.counterparty_id
+            AND org_id IS NOT DISTINCT FROM used.org_id AND owner_login IS NOT DISTINCT FROM used.owner_login;
+        UPDATE transaction_versions SET counterparty_id = copy_id
+        WHERE counterparty_id = used.counterparty_id
+            AND org_id IS NOT DISTINCT FROM used.org_id AND owner_login IS NOT DISTINCT FROM used.owner_login;
+    END LOOP;
+END $$;
+
+ALTER TABLE transactions ENABLE TRIGGER transactions_versions;
+ALTER TABLE transactions ENABLE TRIGGER transactions_period_lock;
+
+-- Категория по умолчанию должна быть из общего справочника или того же владельца.
+UPDATE counterparties cp SET default_category_id = NULL
+FROM categories c
+WHERE c.id = cp.default_category_id
+    AND (c.org_id IS NOT NULL OR c.owner_login IS NOT NULL)
+    AND (c.org_id IS DISTINCT FROM cp.org_id OR c.owner_login IS DISTINCT FROM cp.owner_login);
+
+-- Контрагент определяется по ИНН, а без ИНН — по телефону, в пределах своей области.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_counterparties_org_inn
+    ON counterparties(org_id, inn) WHERE org_id IS NOT NULL AND inn IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_counterparties_org_phone
+    ON counterparties(org_id, phone) WHERE org_id IS NOT NULL AND inn IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_counterparties_owner_inn
+    ON counterparties(COALESCE(owner_login, ''), inn) WHERE org_id IS NULL AND inn IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_counterparties_owner_phone
+    ON counterparties(COALESCE(owner_login, ''), phone) WHERE org_id IS NULL AND inn IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Копии контрагентов, созданные при разделении по владельцам, не объединяются обратно:
+-- откат невозможен, если одни и те же реквизиты есть у нескольких владельцев.
+DROP INDEX IF EXISTS idx_counterparties_owner_phone;
+DROP INDEX IF EXISTS idx_counterparties_owner_inn;
+DROP INDEX IF EXISTS idx_counterparties_org_phone;
+DROP INDEX IF EXISTS idx_counterparties_org_inn;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_counterparties_inn ON counterparties(inn) WHERE inn IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_counterparties_phone ON counterparties(phone) WHERE inn IS NULL;
+
+ALTER TABLE counterparties
+    DROP CONSTRAINT IF EXISTS counterparties_single_owner,
+    DROP COLUMN IF EXISTS owner_login,
+    DROP COLUMN IF EXISTS org_id;
+-- +goose StatementEnd
@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Транзакции, подготовленные платежи, чеки и контрагенты, созданные до появления владельцев,
+-- не связаны ни с одним пользователем. Они передаются одному владельцу — первому
+-- администратору, а если его нет, первому зарегистрированному пользователю, — и дальше
+-- видны только ему и подчиняются его закрытым периодам. Категории без владельца остаются
+-- общим справочником.
+DO $$
+DECLARE
+    legacy_owner VARCHAR(255);
+BEGIN
+    SELECT login_name INTO legacy_owner
+    FROM users
+    ORDER BY role = 'admin' DESC, user_id
+    LIMIT 1;
+
+    IF legacy_owner IS NULL THEN
+        RETURN;
+    END IF;
+
+    -- Смена владельца разрешена и в закрытом периоде; история версий переписывается
+    -- вместе с транзакциями, чтобы отчеты на прошлые даты видели того же владельца.
+    ALTER TABLE transactions DISABLE TRIGGER transactions_versions;
+    UPDATE transactions SET owner_login = legacy_owner WHERE org_id IS NULL AND owner_login IS NULL;
+    UPDATE transaction_versions SET owner_login = legacy_owner WHERE org_id IS NULL AND owner_login IS NULL;
+    ALTER TABLE transactions ENABLE TRIGGER transactions_versions;
+
+    UPDATE prepared_transactions SET owner_login = legacy_owner WHERE org_id IS NULL AND owner_login IS NULL;
+
+    -- Чек, уже импортированный владельцем, второй раз ему не передается: транзакция
+    -- остается, а повторная запись о чеке удаляется.
+    DELETE FROM fiscal_receipts r
+    USING fiscal_receipts own
+    WHERE r.org_id IS NULL AND r.owner_login IS NULL
+        AND own.org_id IS NULL AND own.owner_login = legacy_owner
+        AND own.fiscal_drive = r.fiscal_drive
+        AND own.fiscal_document = r.fiscal_document
+        AND own.fiscal_sign = r.fiscal_sign;
+    UPDATE fiscal_receipts SET owner_login = legacy_owner WHERE org_id IS NULL AND owner_login IS NULL;
+
+    -- Контрагент с теми же реквизитами, что у собственного контрагента владельца,
+    -- объединяется с ним.
+    CREATE TEMP TABLE legacy_counterparties ON COMMIT DROP AS
+    SELECT legacy.id AS legacy_id, own.id AS own_id
+    FROM counterparties legacy
+    JOIN counterparties own
+        ON own.org_id IS NULL AND own.owner_login = legacy_owner
+        AND ((legacy.inn IS NOT NULL AND own.inn = legacy.inn)
+            OR (legacy.inn IS NULL AND own.inn IS NULL AND own.phone = legacy.phone))
+    WHERE legacy.org_id IS NULL AND legacy.owner_login IS NULL;
+
+    ALTER TABLE transactions DISABLE TRIGGER transactions_period_lock;
+    ALTER TABLE transactions DISABLE TRIGGER transactions_versions;
+    UPDATE transactions t SET counterparty_id = m.own_id
+    FROM legacy_counterparties m WHERE t.counterparty_id = m.legacy_id;
+    UPDATE transaction_versions v SET counterparty_id = m.own_id
+    FROM legacy_counterparties m WHERE v.counterparty_id = m.legacy_id;
+    ALTER TABLE transactions ENABLE TRIGGER transactions_versions;
+    ALTER TABLE transactions ENABLE TRIGGER transactions_period_lock;
+
+    UPDATE prepared_transactions t SET counterparty_id = m.own_id
+    FROM legacy_counterparties m WHERE t.counterparty_id = m.legacy_id;
+    DELETE FROM counterparties cp USING legacy_counterparties m WHERE cp.id = m.legacy_id;
+
+    UPDATE counterparties SET owner_login = legacy_owner WHERE org_id IS NULL AND owner_login IS NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Владелец, назначенный записям без владельца, не отличим от собственных записей
+-- пользователя, поэтому откат данные не меняет.
+SELECT 1;
+-- +goose StatementEnd
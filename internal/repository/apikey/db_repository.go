@@ -0,0 +1,152 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `
+	k.id, k.login_name, k.name, k.key_prefix, k.scopes, k.created_at, k.expires_at, k.last_used_at
+`
+
+// apiKeyRow — строка api_keys; области хранятся массивом text[].
+type apiKeyRow struct {
+	ID         int64          `db:"id"`
+	Login      string         `db:"login_name"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"key_prefix"`
+	Scopes     pq.StringArray `db:"scopes"`
+	CreatedAt  time.Time      `db:"created_at"`
+	ExpiresAt  *time.Time     `db:"expires_at"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+}
+
+func (row apiKeyRow) toDomain() domain.APIKey {
+	scopes := make([]domain.APIKeyScope, len(row.Scopes))
+	for i, scope := range row.Scopes {
+		scopes[i] = domain.APIKeyScope(scope)
+	}
+	return domain.APIKey{
+		ID:         row.ID,
+		Login:      row.Login,
+		Name:       row.Name,
+		Prefix:     row.Prefix,
+		Scopes:     scopes,
+		CreatedAt:  row.CreatedAt,
+		ExpiresAt:  row.ExpiresAt,
+		LastUsedAt: row.LastUsedAt,
+	}
+}
+
+type APIKeyRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewAPIKeyRepository(logger *logger.Logger, db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey, keyHash string) error {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO api_keys (login_name, name, key_prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, key.Login, key.Name, key.Prefix, keyHash, pq.Array(scopes), key.ExpiresAt).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": key.Login})
+		return err
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, login string) ([]domain.APIKey, error) {
+	var rows []apiKeyRow
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys k
+		WHERE k.login_name = $1 AND k.revoked_at IS NULL
+		ORDER BY k.created_at DESC, k.id DESC
+	`, login)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return nil, err
+	}
+
+	keys := make([]domain.APIKey, len(rows))
+	for i, row := range rows {
+		keys[i] = row.toDomain()
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, login string, id int64, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = $3
+		WHERE id = $1 AND login_name = $2 AND revoked_at IS NULL
+	`, id, login, revokedAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "api_key_id": id})
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) GetActiveAPIKey(ctx context.Context, keyHash string, now time.Time) (*domain.APIKeyOwner, error) {
+	var row struct {
+		apiKeyRow
+		Role    domain.Role `db:"role"`
+		Blocked bool        `db:"blocked"`
+	}
+	err := r.db.GetContext(ctx, &row, `
+		SELECT `+apiKeyColumns+`, u.role, u.blocked_at IS NOT NULL AS blocked
+		FROM api_keys k
+		JOIN users u ON u.login_name = k.login_name
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > $2)
+	`, keyHash, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return nil, err
+	}
+
+	return &domain.APIKeyOwner{Key: row.toDomain(), Role: row.Role, Blocked: row.Blocked}, nil
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2 - INTERVAL '1 minute')
+	`, id, usedAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "api_key_id": id})
+		return err
+	}
+	return nil
+}
+
+var _ IAPIKeyRepository = (*APIKeyRepository)(nil)
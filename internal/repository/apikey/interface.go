@@ -0,0 +1,25 @@
+package apikey
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type IAPIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey, keyHash string) error
+
+	// ListAPIKeys возвращает неотозванные ключи пользователя, включая истекшие.
+	ListAPIKeys(ctx context.Context, login string) ([]domain.APIKey, error)
+
+	// RevokeAPIKey отзывает ключ пользователя; domain.ErrAPIKeyNotFound, если ключа нет или он уже отозван.
+	RevokeAPIKey(ctx context.Context, login string, id int64, revokedAt time.Time) error
+
+	// GetActiveAPIKey ищет неотозванный и не истекший на момент now ключ по хешу;
+	// nil, nil, если такого нет.
+	GetActiveAPIKey(ctx context.Context, keyHash string, now time.Time) (*domain.APIKeyOwner, error)
+
+	// TouchAPIKey обновляет время последнего использования, но не чаще раза в минуту,
+	// чтобы не писать в базу на каждый запрос.
+	TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error
+}
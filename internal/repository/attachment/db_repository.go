@@ -0,0 +1,121 @@
+package attachment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/repository/ownership"
+	"finance-backend/pkg/logger"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const attachmentColumns = `
+	id, transaction_id, bucket, object_key, file_name, content_type, size, etag, owner_login, created_at
+`
+
+type AttachmentRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewAttachmentRepository(logger *logger.Logger, db *sqlx.DB) *AttachmentRepository {
+	return &AttachmentRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *AttachmentRepository) TransactionExists(ctx context.Context, scope domain.DataScope, transactionID int64) (bool, error) {
+	condition, args := ownership.ScopeCondition("t", scope, 2)
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM transactions t WHERE t.id = $1 AND t.deleted_at IS NULL AND `+condition+`)`, append([]interface{}{transactionID}, args...)...)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "transaction_id": transactionID})
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *AttachmentRepository) GetByID(ctx context.Context, transactionID int64, id int64) (*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM transaction_attachments WHERE id = $1 AND transaction_id = $2`
+	var attachment domain.Attachment
+	err := r.db.GetContext(ctx, &attachment, query, id, transactionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAttachmentNotFound
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "attachment_id": id})
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) GetByObjectKey(ctx context.Context, objectKey string) (*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM transaction_attachments WHERE object_key = $1`
+	var attachment domain.Attachment
+	err := r.db.GetContext(ctx, &attachment, query, objectKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "object_key": objectKey})
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) FindObjectKeys(ctx context.Context, keys []string) ([]string, error) {
+	found := []string{}
+	err := r.db.SelectContext(ctx, &found, `SELECT object_key FROM transaction_attachments WHERE object_key = ANY($1::varchar[])`, pq.Array(keys))
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "keys": len(keys)})
+		return nil, err
+	}
+	return found, nil
+}
+
+func (r *AttachmentRepository) ListByTransaction(ctx context.Context, transactionID int64) ([]domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM transaction_attachments WHERE transaction_id = $1 ORDER BY created_at`
+	attachments := []domain.Attachment{}
+	err := r.db.SelectContext(ctx, &attachments, query, transactionID)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "transaction_id": transactionID})
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	query := `
+		INSERT INTO transaction_attachments (transaction_id, bucket, object_key, file_name, content_type, size, etag, owner_login)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowxContext(ctx, query,
+		attachment.TransactionID,
+		attachment.Bucket,
+		attachment.ObjectKey,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.ETag,
+		attachment.OwnerLogin,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "transaction_id": attachment.TransactionID})
+	}
+	return err
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM transaction_attachments WHERE id = $1`, id)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "attachment_id": id})
+	}
+	return err
+}
+
+var _ IAttachmentRepository = (*AttachmentRepository)(nil)
@@ -0,0 +1,25 @@
+package attachment
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+)
+
+type IAttachmentRepository interface {
+	// TransactionExists сообщает, есть ли транзакция в области данных scope.
+	TransactionExists(ctx context.Context, scope domain.DataScope, transactionID int64) (bool, error)
+
+	GetByID(ctx context.Context, transactionID int64, id int64) (*domain.Attachment, error)
+
+	// GetByObjectKey возвращает nil, nil, если вложения с таким ключом нет.
+	GetByObjectKey(ctx context.Context, objectKey string) (*domain.Attachment, error)
+
+	// FindObjectKeys возвращает те из keys, для которых есть вложения.
+	FindObjectKeys(ctx context.Context, keys []string) ([]string, error)
+
+	ListByTransaction(ctx context.Context, transactionID int64) ([]domain.Attachment, error)
+
+	Create(ctx context.Context, attachment *domain.Attachment) error
+
+	Delete(ctx context.Context, id int64) error
+}
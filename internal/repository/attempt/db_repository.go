@@ -0,0 +1,112 @@
+package attempt
+
+import (
+	"context"
+	"database/sql"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type AttemptRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewAttemptRepository(logger *logger.Logger, db *sqlx.DB) *AttemptRepository {
+	return &AttemptRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *AttemptRepository) GetLockedUntil(ctx context.Context, keys []domain.ThrottleKey, now time.Time) (*time.Time, error) {
+	scopes := make([]string, len(keys))
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		scopes[i], values[i] = string(key.Scope), key.Value
+	}
+
+	var lockedUntil sql.NullTime
+	err := r.db.GetContext(ctx, &lockedUntil, `
+		SELECT MAX(t.locked_until)
+		FROM login_throttles t
+		JOIN unnest($1::text[], $2::text[]) AS k(scope, key) ON k.scope = t.scope AND k.key = t.key
+		WHERE t.locked_until > $3
+	`, pq.Array(scopes), pq.Array(values), now)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return nil, err
+	}
+	if !lockedUntil.Valid {
+		return nil, nil
+	}
+	return &lockedUntil.Time, nil
+}
+
+func (r *AttemptRepository) RegisterFailure(ctx context.Context, key domain.ThrottleKey, now time.Time, resetBefore time.Time) (int, error) {
+	var failures int
+	err := r.db.GetContext(ctx, &failures, `
+		INSERT INTO login_throttles (scope, key, failures, last_failure_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < $4 THEN 1 ELSE login_throttles.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures
+	`, key.Scope, key.Value, now, resetBefore)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "scope": key.Scope})
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (r *AttemptRepository) LockUntil(ctx context.Context, key domain.ThrottleKey, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE login_throttles SET locked_until = $3 WHERE scope = $1 AND key = $2
+	`, key.Scope, key.Value, until)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "scope": key.Scope})
+		return err
+	}
+	return nil
+}
+
+func (r *AttemptRepository) Reset(ctx context.Context, key domain.ThrottleKey) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_throttles WHERE scope = $1 AND key = $2`, key.Scope, key.Value)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "scope": key.Scope})
+		return err
+	}
+	return nil
+}
+
+func (r *AttemptRepository) DeleteStale(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM login_throttles
+		WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)
+	`, before)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return err
+	}
+	return nil
+}
+
+func (r *AttemptRepository) CreateAuthEvent(ctx context.Context, event *domain.AuthEvent) error {
+	err := r.db.GetContext(ctx, &event.ID, `
+		INSERT INTO auth_events (event_type, login_name, ip, request_id, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, event.Type, event.Login, event.IP, event.RequestID, event.Details)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "event": event.Type})
+		return err
+	}
+	return nil
+}
+
+var _ IAttemptRepository = (*AttemptRepository)(nil)
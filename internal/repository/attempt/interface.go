@@ -0,0 +1,27 @@
+package attempt
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type IAttemptRepository interface {
+	// GetLockedUntil возвращает самую позднюю действующую на момент now блокировку
+	// среди ключей; nil, если ни один ключ не заблокирован.
+	GetLockedUntil(ctx context.Context, keys []domain.ThrottleKey, now time.Time) (*time.Time, error)
+
+	// RegisterFailure увеличивает счетчик ключа и возвращает его новое значение.
+	// Счетчик, не менявшийся с момента resetBefore, начинается заново.
+	RegisterFailure(ctx context.Context, key domain.ThrottleKey, now time.Time, resetBefore time.Time) (int, error)
+
+	LockUntil(ctx context.Context, key domain.ThrottleKey, until time.Time) error
+
+	// Reset сбрасывает счетчик и блокировку ключа.
+	Reset(ctx context.Context, key domain.ThrottleKey) error
+
+	// DeleteStale удаляет счетчики без неудач после before и без действующей блокировки.
+	DeleteStale(ctx context.Context, before time.Time) error
+
+	CreateAuthEvent(ctx context.Context, event *domain.AuthEvent) error
+}
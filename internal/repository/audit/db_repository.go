@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const auditEntryColumns = `
+	id, actor_login, api_key_id, action, entity_type, entity_id, org_id,
+	COALESCE(before_data::text, '') AS before_data,
+	COALESCE(after_data::text, '') AS after_data,
+	request_id, ip, created_at
+`
+
+type AuditRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewAuditRepository(logger *logger.Logger, db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO audit_log (actor_login, api_key_id, action, entity_type, entity_id, org_id, before_data, after_data, request_id, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::jsonb, NULLIF($8, '')::jsonb, $9, $10)
+		RETURNING id, created_at
+	`, entry.Actor, entry.APIKeyID, entry.Action, entry.EntityType, entry.EntityID, entry.OrgID,
+		entry.Before, entry.After, entry.RequestID, entry.IP).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "action": entry.Action, "entity": entry.EntityType})
+		return err
+	}
+	return nil
+}
+
+func (r *AuditRepository) SearchPaginated(ctx context.Context, limit int, offset int, filter domain.AuditFilter) (utils.PaginatedEntities[domain.AuditEntry], error) {
+	where := ` WHERE ($1::text IS NULL OR actor_login = $1)
+		AND ($2::text IS NULL OR action = $2)
+		AND ($3::text IS NULL OR entity_type = $3)
+		AND ($4::text IS NULL OR entity_id = $4)
+		AND ($5::bigint IS NULL OR org_id = $5)
+		AND ($6::text IS NULL OR request_id = $6)
+		AND ($7::timestamptz IS NULL OR created_at >= $7)
+		AND ($8::timestamptz IS NULL OR created_at < $8)`
+	args := []interface{}{filter.Actor, filter.Action, filter.EntityType, filter.EntityID,
+		filter.OrgID, filter.RequestID, filter.From, filter.To}
+
+	var entries []domain.AuditEntry
+	err := r.db.SelectContext(ctx, &entries, `SELECT `+auditEntryColumns+` FROM audit_log`+where+` ORDER BY id DESC LIMIT $9 OFFSET $10`,
+		append(args, limit, offset)...)
+	if err != nil {
+		r.log.Error(ctx, "error querying audit log", map[string]interface{}{"error": err})
+		return utils.PaginatedEntities[domain.AuditEntry]{}, domain.ErrDBConnection
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM audit_log`+where, args...); err != nil {
+		r.log.Error(ctx, "error counting audit log", map[string]interface{}{"error": err})
+		return utils.PaginatedEntities[domain.AuditEntry]{}, domain.ErrDBConnection
+	}
+
+	return utils.PaginatedEntities[domain.AuditEntry]{
+		Items:            entries,
+		Total:            total,
+		PageNumber:       offset/limit + 1,
+		ObjectsCount:     len(entries),
+		ObjectsCounTotal: total,
+		PageCount:        (total + limit - 1) / limit,
+	}, nil
+}
+
+var _ IAuditRepository = (*AuditRepository)(nil)
@@ -0,0 +1,15 @@
+package audit
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+)
+
+// IAuditRepository — журнал аудита. Записи только добавляются.
+type IAuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+
+	// SearchPaginated возвращает записи по фильтру, новые первыми.
+	SearchPaginated(ctx context.Context, limit int, offset int, filter domain.AuditFilter) (utils.PaginatedEntities[domain.AuditEntry], error)
+}
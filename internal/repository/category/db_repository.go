@@ -2,14 +2,30 @@ package category
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"finance-backend/internal/domain"
+	"finance-backend/internal/repository/ownership"
 	"finance-backend/pkg/logger"
 	"finance-backend/pkg/utils"
-	"time"
+	"strconv"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+const (
+	foreignKeyViolationCode = "23503"
+	// periodClosedCode — ошибка триггера transactions_period_lock: транзакция в закрытом периоде.
+	periodClosedCode = "FP001"
+)
+
+const categoryColumns = `
+	c.id, c.name, COALESCE(c.type, '') AS type, c.parent_id, c.org_id, c.owner_login, c.archived_at,
+	COALESCE(c.created_at, CURRENT_TIMESTAMP) AS created_at,
+	COALESCE(c.updated_at, CURRENT_TIMESTAMP) AS updated_at
+`
+
 type CategoryRepository struct {
 	db  *sqlx.DB
 	log *logger.Logger
@@ -22,49 +38,65 @@ func NewCategoryRepository(logger *logger.Logger, db *sqlx.DB) *CategoryReposito
 	}
 }
 
-func (r *CategoryRepository) GetByID(ctx context.Context, id int64) (*domain.Category, error) {
-	query := `SELECT id, name, created_at, updated_at FROM categories WHERE id = ?`
+func (r *CategoryRepository) GetByID(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error) {
+	condition, args := ownership.CategoryScopeCondition("c", scope, 2)
+	query := `SELECT ` + categoryColumns + ` FROM categories c WHERE c.id = $1 AND ` + condition
+
 	var category domain.Category
-	r.log.Info(ctx, "Getting category by id", nil)
-	err := r.db.GetContext(ctx, &category, query, id)
+	err := r.db.GetContext(ctx, &category, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrCategoryNotFound
+	}
 	if err != nil {
-		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "category_id": id})
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_id": id})
 		return nil, err
 	}
 	return &category, nil
 }
 
-func (r *CategoryRepository) GetByName(ctx context.Context, name string) (*domain.Category, error) {
-	query := `SELECT id, name, created_at, updated_at AS updated_at FROM categories WHERE name = ?`
-	var category domain.Category
-	r.log.Info(ctx, "Getting category by name", nil)
-	err := r.db.GetContext(ctx, &category, query, name)
+func (r *CategoryRepository) ExistsByName(ctx context.Context, scope domain.DataScope, name string, categoryType string, excludeID int64) (bool, error) {
+	condition, args := ownership.CategoryScopeCondition("c", scope, 4)
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM categories c
+			WHERE LOWER(c.name) = LOWER($1) AND c.type = $2 AND c.id <> $3
+				AND c.archived_at IS NULL AND ` + condition + `
+		)`
+
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, append([]interface{}{name, categoryType, excludeID}, args...)...)
 	if err != nil {
-		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "category_name": name})
-		return nil, err
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_name": name})
+		return false, err
 	}
-	return &category, nil
+	return exists, nil
 }
 
-func (r *CategoryRepository) SearchPaginated(ctx context.Context, limit int, offset int, search *string) (utils.PaginatedEntities[domain.Category], error) {
-	query := `SELECT id, name, created_at, updated_at FROM categories WHERE (? IS NULL OR LOWER(name) LIKE CONCAT('%', LOWER(?), '%')) LIMIT ? OFFSET ?`
-	var categories []domain.Category
-	r.log.Info(ctx, "Search category paginated", map[string]interface{}{"limit": limit, "offset": offset, "search": search})
-	err := r.db.SelectContext(ctx, &categories, query, search, search, limit, offset)
+func (r *CategoryRepository) SearchPaginated(ctx context.Context, scope domain.DataScope, limit int, offset int, search *string, withArchived bool) (utils.PaginatedEntities[domain.Category], error) {
+	condition, args := ownership.CategoryScopeCondition("c", scope, 3)
+	filter := `($1::text IS NULL OR c.name ILIKE '%' || $1 || '%') AND ($2 OR c.archived_at IS NULL) AND ` + condition
+	filterArgs := append([]interface{}{search, withArchived}, args...)
+
+	n := len(filterArgs)
+	query := `SELECT ` + categoryColumns + ` FROM categories c WHERE ` + filter +
+		` ORDER BY c.type, c.name LIMIT $` + strconv.Itoa(n+1) + ` OFFSET $` + strconv.Itoa(n+2)
+	categories := []domain.Category{}
+	r.log.Info(ctx, "Search categories paginated", map[string]interface{}{"limit": limit, "offset": offset, "search": search})
+	err := r.db.SelectContext(ctx, &categories, query, append(filterArgs, limit, offset)...)
 	if err != nil {
-		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error()})
 		return utils.PaginatedEntities[domain.Category]{}, err
 	}
 
-	countQuery := `SELECT COUNT(*) FROM categories WHERE (? IS NULL OR LOWER(name) LIKE CONCAT('%', LOWER(?), '%'))`
+	countQuery := `SELECT COUNT(*) FROM categories c WHERE ` + filter
 	var total int
-	err = r.db.GetContext(ctx, &total, countQuery, search, search)
+	err = r.db.GetContext(ctx, &total, countQuery, filterArgs...)
 	if err != nil {
-		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error()})
 		return utils.PaginatedEntities[domain.Category]{}, err
 	}
 
-	pageCount := (total + limit - 1) / limit // Вычисляем количество страниц
+	pageCount := (total + limit - 1) / limit
 
 	return utils.PaginatedEntities[domain.Category]{
 		Items:            categories,
@@ -76,51 +108,133 @@ func (r *CategoryRepository) SearchPaginated(ctx context.Context, limit int, off
 	}, nil
 }
 
-func (r *CategoryRepository) SearchFlat(ctx context.Context, search *string) ([]domain.Category, error) {
-	query := `SELECT id, name, created_at, updated_at FROM categories WHERE (? IS NULL OR LOWER(name) LIKE CONCAT('%', LOWER(?), '%'))`
-	var category []domain.Category
-	r.log.Info(ctx, "Search categories flat", nil)
-	err := r.db.SelectContext(ctx, &category, query, search, search)
-	if err != nil {
-		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
-		return []domain.Category{}, err
+func (r *CategoryRepository) Create(ctx context.Context, scope domain.DataScope, data *domain.CategoryData) (*domain.Category, error) {
+	orgID, ownerLogin := scope.OrgID, ""
+	if !scope.IsOrganization() {
+		ownerLogin = scope.Login
 	}
-	return category, nil
-}
+	query := `
+		INSERT INTO categories AS c (name, type, parent_id, org_id, owner_login)
+		VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, ''))
+		RETURNING ` + categoryColumns
 
-func (r *CategoryRepository) Create(ctx context.Context, name string) (*domain.Category, error) {
-	query := `INSERT INTO categories (name) VALUES (?)`
-	r.log.Info(ctx, "Create category", nil)
-	result, err := r.db.ExecContext(ctx, query, name)
+	var category domain.Category
+	err := r.db.GetContext(ctx, &category, query, data.Name, data.Type, data.ParentID, orgID, ownerLogin)
 	if err != nil {
-		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "category_name": name})
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_name": data.Name})
 		return nil, err
 	}
-	id, err := result.LastInsertId()
+	return &category, nil
+}
+
+func (r *CategoryRepository) UpdateName(ctx context.Context, id int64, name string) error {
+	query := `UPDATE categories SET name = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, name)
 	if err != nil {
-		r.log.Error(ctx, "error fetching last inserted id", map[string]interface{}{"error": err, "category_name": name})
-		return nil, err
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_id": id})
 	}
-	category, err := r.GetByID(ctx, id)
-	return category, err
+	return err
 }
 
-func (r *CategoryRepository) UpdateName(ctx context.Context, categoryID int64, categoryName string) error {
-	query := `UPDATE categories SET name = ?, updated_at = ? WHERE id = ?`
-	r.log.Info(ctx, "Update category by id", nil)
-	_, err := r.db.ExecContext(ctx, query, categoryName, time.Now(), categoryID)
+func (r *CategoryRepository) SetArchived(ctx context.Context, id int64, archived bool) error {
+	query := `
+		UPDATE categories
+		SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, CURRENT_TIMESTAMP) END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, archived)
 	if err != nil {
-		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "category_name": categoryName})
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_id": id})
 	}
 	return err
 }
 
+func (r *CategoryRepository) IsDescendant(ctx context.Context, id int64, ancestorID int64) (bool, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT c.id, c.parent_id FROM categories c WHERE c.id = $1
+			UNION
+			SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2 AND id <> $1)`
+
+	var descendant bool
+	if err := r.db.GetContext(ctx, &descendant, query, id, ancestorID); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_id": id})
+		return false, err
+	}
+	return descendant, nil
+}
+
+func (r *CategoryRepository) IsInUse(ctx context.Context, id int64) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM transactions WHERE category_id = $1)
+			OR EXISTS (SELECT 1 FROM transaction_versions WHERE category_id = $1)
+			OR EXISTS (SELECT 1 FROM prepared_transactions WHERE category_id = $1)
+			OR EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`
+
+	var used bool
+	if err := r.db.GetContext(ctx, &used, query, id); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_id": id})
+		return false, err
+	}
+	return used, nil
+}
+
+func (r *CategoryRepository) Merge(ctx context.Context, sourceID int64, targetID int64) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err.Error()})
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Транзакции в корзине тоже переносятся: после восстановления они окажутся в новой категории.
+	// Транзакцию в закрытом периоде изменить нельзя — объединение целиком откатывается.
+	result, err := tx.ExecContext(ctx, `UPDATE transactions SET category_id = $2 WHERE category_id = $1`, sourceID, targetID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == periodClosedCode {
+			return 0, domain.ErrPeriodClosed
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_id": sourceID})
+		return 0, err
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	statements := []string{
+		`UPDATE prepared_transactions SET category_id = $2 WHERE category_id = $1`,
+		`UPDATE counterparties SET default_category_id = $2, updated_at = CURRENT_TIMESTAMP WHERE default_category_id = $1`,
+		`UPDATE categories SET parent_id = $2, updated_at = CURRENT_TIMESTAMP WHERE parent_id = $1`,
+		// Версии транзакций по-прежнему ссылаются на исходную категорию, поэтому она архивируется.
+		`UPDATE categories SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP WHERE id = $1`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, sourceID, targetID); err != nil {
+			r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_id": sourceID})
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
 func (r *CategoryRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM categories WHERE id = ?`
-	r.log.Info(ctx, "Delete category by id", nil)
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
 	if err != nil {
-		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "category_id": id})
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolationCode {
+			return domain.ErrCategoryInUse
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "category_id": id})
 	}
 	return err
 }
+
+var _ ICategoryRepository = (*CategoryRepository)(nil)
@@ -6,18 +6,37 @@ import (
 	"finance-backend/pkg/utils"
 )
 
+// ICategoryRepository — категории, видимые в области данных: собственные категории
+// организации или пользователя и общий справочник.
 type ICategoryRepository interface {
-	GetByID(ctx context.Context, id int64) (*domain.Category, error)
+	// GetByID возвращает категорию области scope, в том числе архивную.
+	GetByID(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error)
 
-	GetByName(ctx context.Context, name string) (*domain.Category, error)
+	// ExistsByName сообщает, есть ли в области действующая категория типа categoryType
+	// с таким названием без учета регистра. Категория excludeID не учитывается.
+	ExistsByName(ctx context.Context, scope domain.DataScope, name string, categoryType string, excludeID int64) (bool, error)
 
-	SearchPaginated(ctx context.Context, limit int, offset int, search *string) (utils.PaginatedEntities[domain.Category], error)
+	SearchPaginated(ctx context.Context, scope domain.DataScope, limit int, offset int, search *string, withArchived bool) (utils.PaginatedEntities[domain.Category], error)
 
-	SearchFlat(ctx context.Context, search *string) ([]domain.Category, error)
+	// Create создает категорию, принадлежащую области scope.
+	Create(ctx context.Context, scope domain.DataScope, data *domain.CategoryData) (*domain.Category, error)
 
-	Create(ctx context.Context, name string) (*domain.Category, error)
+	UpdateName(ctx context.Context, id int64, name string) error
 
-	UpdateName(ctx context.Context, categoryID int64, categoryName string) error
+	SetArchived(ctx context.Context, id int64, archived bool) error
 
+	// IsDescendant сообщает, вложена ли категория id (на любую глубину) в ancestorID.
+	IsDescendant(ctx context.Context, id int64, ancestorID int64) (bool, error)
+
+	// IsInUse сообщает, есть ли у категории транзакции (включая корзину и историю версий),
+	// подготовленные платежи или подкатегории.
+	IsInUse(ctx context.Context, id int64) (bool, error)
+
+	// Merge переносит в targetID транзакции, подготовленные платежи, подкатегории и категорию
+	// контрагентов по умолчанию из sourceID и архивирует sourceID. Возвращает число
+	// перенесенных транзакций.
+	Merge(ctx context.Context, sourceID int64, targetID int64) (int64, error)
+
+	// Delete удаляет категорию; используемую категорию удалить нельзя (domain.ErrCategoryInUse).
 	Delete(ctx context.Context, id int64) error
 }
@@ -0,0 +1,163 @@
+package counterparty
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/repository/ownership"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const uniqueViolationCode = "23505"
+
+const counterpartyColumns = `
+	cp.id, cp.name, cp.inn, cp.phone, cp.bank, cp.bank_bic, cp.account, cp.default_category_id,
+	cp.org_id, cp.owner_login, cp.created_at, cp.updated_at
+`
+
+type CounterpartyRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewCounterpartyRepository(logger *logger.Logger, db *sqlx.DB) *CounterpartyRepository {
+	return &CounterpartyRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *CounterpartyRepository) GetByID(ctx context.Context, scope domain.DataScope, id int64) (*domain.Counterparty, error) {
+	condition, args := ownership.ScopeCondition("cp", scope, 2)
+	query := `SELECT ` + counterpartyColumns + ` FROM counterparties cp WHERE cp.id = $1 AND ` + condition
+	var counterparty domain.Counterparty
+	err := r.db.GetContext(ctx, &counterparty, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrCounterpartyNotFound
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "counterparty_id": id})
+		return nil, err
+	}
+	return &counterparty, nil
+}
+
+func (r *CounterpartyRepository) FindByRequisites(ctx context.Context, scope domain.DataScope, inn string, phone string) (*domain.Counterparty, error) {
+	condition, args := ownership.ScopeCondition("cp", scope, 3)
+	query := `
+		SELECT ` + counterpartyColumns + `
+		FROM counterparties cp
+		WHERE (($1 <> '' AND cp.inn = $1)
+			OR ($1 = '' AND $2 <> '' AND cp.inn IS NULL AND cp.phone = $2))
+			AND ` + condition + `
+		LIMIT 1
+	`
+	var counterparty domain.Counterparty
+	err := r.db.GetContext(ctx, &counterparty, query, append([]interface{}{inn, phone}, args...)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "inn": inn, "phone": phone})
+		return nil, err
+	}
+	return &counterparty, nil
+}
+
+func (r *CounterpartyRepository) SearchPaginated(ctx context.Context, scope domain.DataScope, limit int, offset int, search *string) (utils.PaginatedEntities[domain.Counterparty], error) {
+	condition, args := ownership.ScopeCondition("cp", scope, 2)
+	filter := `($1::text IS NULL OR cp.name ILIKE '%' || $1 || '%' OR cp.inn LIKE $1 || '%' OR cp.phone LIKE '%' || $1 || '%') AND ` + condition
+	filterArgs := append([]interface{}{search}, args...)
+
+	n := len(filterArgs)
+	query := `SELECT ` + counterpartyColumns + ` FROM counterparties cp WHERE ` + filter +
+		` ORDER BY cp.name LIMIT $` + strconv.Itoa(n+1) + ` OFFSET $` + strconv.Itoa(n+2)
+	var counterparties []domain.Counterparty
+	r.log.Info(ctx, "Search counterparties paginated", map[string]interface{}{"limit": limit, "offset": offset, "search": search})
+	err := r.db.SelectContext(ctx, &counterparties, query, append(filterArgs, limit, offset)...)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return utils.PaginatedEntities[domain.Counterparty]{}, err
+	}
+
+	countQuery := `SELECT COUNT(*) FROM counterparties cp WHERE ` + filter
+	var total int
+	err = r.db.GetContext(ctx, &total, countQuery, filterArgs...)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return utils.PaginatedEntities[domain.Counterparty]{}, err
+	}
+
+	return utils.PaginatedEntities[domain.Counterparty]{
+		Items:            counterparties,
+		Total:            total,
+		PageNumber:       offset/limit + 1,
+		ObjectsCount:     len(counterparties),
+		ObjectsCounTotal: total,
+		PageCount:        (total + limit - 1) / limit,
+	}, nil
+}
+
+func (r *CounterpartyRepository) Create(ctx context.Context, scope domain.DataScope, data *domain.CounterpartyData) (*domain.Counterparty, error) {
+	orgID, ownerLogin := scope.OrgID, ""
+	if !scope.IsOrganization() {
+		ownerLogin = scope.Login
+	}
+	query := `
+		INSERT INTO counterparties AS cp (name, inn, phone, bank, bank_bic, account, default_category_id, org_id, owner_login)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), NULLIF($9, ''))
+		RETURNING ` + counterpartyColumns
+	var counterparty domain.Counterparty
+	err := r.db.GetContext(ctx, &counterparty, query,
+		data.Name, data.INN, data.Phone, data.Bank, data.BankBIC, data.Account, data.DefaultCategoryID, orgID, ownerLogin,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrCounterpartyExists
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "counterparty_name": data.Name})
+		return nil, err
+	}
+	return &counterparty, nil
+}
+
+func (r *CounterpartyRepository) Update(ctx context.Context, id int64, data *domain.CounterpartyData) error {
+	query := `
+		UPDATE counterparties
+		SET name = $1, inn = $2, phone = $3, bank = $4, bank_bic = $5, account = $6,
+			default_category_id = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		data.Name, data.INN, data.Phone, data.Bank, data.BankBIC, data.Account, data.DefaultCategoryID, id,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrCounterpartyExists
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "counterparty_id": id})
+	}
+	return err
+}
+
+func (r *CounterpartyRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM counterparties WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "counterparty_id": id})
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode
+}
+
+var _ ICounterpartyRepository = (*CounterpartyRepository)(nil)
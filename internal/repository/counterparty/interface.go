@@ -0,0 +1,26 @@
+package counterparty
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+)
+
+// ICounterpartyRepository — контрагенты области данных: организации или личного учета.
+// Update и Delete не проверяют область: запись сначала получают через GetByID.
+type ICounterpartyRepository interface {
+	GetByID(ctx context.Context, scope domain.DataScope, id int64) (*domain.Counterparty, error)
+
+	// FindByRequisites ищет контрагента области по ИНН, а при его отсутствии — по телефону.
+	// Если контрагент не найден, возвращает nil без ошибки.
+	FindByRequisites(ctx context.Context, scope domain.DataScope, inn string, phone string) (*domain.Counterparty, error)
+
+	SearchPaginated(ctx context.Context, scope domain.DataScope, limit int, offset int, search *string) (utils.PaginatedEntities[domain.Counterparty], error)
+
+	// Create создает контрагента, принадлежащего области scope.
+	Create(ctx context.Context, scope domain.DataScope, data *domain.CounterpartyData) (*domain.Counterparty, error)
+
+	Update(ctx context.Context, id int64, data *domain.CounterpartyData) error
+
+	Delete(ctx context.Context, id int64) error
+}
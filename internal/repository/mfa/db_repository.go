@@ -0,0 +1,226 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type MFARepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewMFARepository(logger *logger.Logger, db *sqlx.DB) *MFARepository {
+	return &MFARepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *MFARepository) GetTOTPState(ctx context.Context, login string) (*domain.TOTPState, error) {
+	var state domain.TOTPState
+	err := r.db.GetContext(ctx, &state, `
+		SELECT u.login_name, COALESCE(u.totp_secret, '') AS totp_secret, u.totp_enabled_at,
+			(SELECT COUNT(*) FROM recovery_codes c WHERE c.login_name = u.login_name AND c.used_at IS NULL) AS recovery_codes_left
+		FROM users u
+		WHERE u.login_name = $1
+	`, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *MFARepository) SaveTOTPSecret(ctx context.Context, login string, secret string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET totp_secret = $2, totp_enabled_at = NULL, totp_last_step = 0 WHERE login_name = $1
+	`, login, secret)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+	return nil
+}
+
+func (r *MFARepository) EnableTOTP(ctx context.Context, login string, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET totp_enabled_at = CURRENT_TIMESTAMP WHERE login_name = $1`, login)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+	if err := replaceRecoveryCodes(ctx, tx, login, recoveryCodeHashes); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *MFARepository) DisableTOTP(ctx context.Context, login string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0 WHERE login_name = $1
+	`, login)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+	if err := replaceRecoveryCodes(ctx, tx, login, nil); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *MFARepository) UseTOTPStep(ctx context.Context, login string, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET totp_last_step = $2 WHERE login_name = $1 AND totp_last_step < $2
+	`, login, step)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, login string, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, login, codeHashes); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, login string, codeHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE login_name = $1 AND code_hash = $2 AND used_at IS NULL
+	`, login, codeHash)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *MFARepository) CreateChallenge(ctx context.Context, challenge *domain.MFAChallenge) error {
+	err := r.db.GetContext(ctx, &challenge.ID, `
+		INSERT INTO mfa_challenges (login_name, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, challenge.Login, challenge.TokenHash, challenge.ExpiresAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": challenge.Login})
+		return err
+	}
+	return nil
+}
+
+func (r *MFARepository) GetChallenge(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error) {
+	var challenge domain.MFAChallenge
+	err := r.db.GetContext(ctx, &challenge, `
+		SELECT id, login_name, token_hash, expires_at, attempts
+		FROM mfa_challenges
+		WHERE token_hash = $1 AND expires_at > now()
+	`, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *MFARepository) RegisterFailedAttempt(ctx context.Context, id int64, maxAttempts int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1`, id)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "id": id})
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `DELETE FROM mfa_challenges WHERE id = $1 AND attempts >= $2`, id, maxAttempts)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "id": id})
+		return err
+	}
+	return nil
+}
+
+func (r *MFARepository) DeleteChallenge(ctx context.Context, id int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM mfa_challenges WHERE id = $1`, id)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "id": id})
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *MFARepository) DeleteExpiredChallenges(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM mfa_challenges WHERE expires_at < $1`, before)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return err
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, login string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE login_name = $1`, login); err != nil {
+		return err
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO recovery_codes (login_name, code_hash)
+		SELECT $1, unnest($2::char(64)[])
+	`, login, pq.Array(codeHashes))
+	return err
+}
+
+var _ IMFARepository = (*MFARepository)(nil)
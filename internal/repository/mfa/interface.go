@@ -0,0 +1,45 @@
+package mfa
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type IMFARepository interface {
+	// GetTOTPState возвращает nil, nil, если пользователя нет.
+	GetTOTPState(ctx context.Context, login string) (*domain.TOTPState, error)
+
+	// SaveTOTPSecret сохраняет секрет неподтвержденного подключения, заменяя прежний.
+	SaveTOTPSecret(ctx context.Context, login string, secret string) error
+
+	// EnableTOTP подтверждает подключение и заменяет коды восстановления.
+	EnableTOTP(ctx context.Context, login string, recoveryCodeHashes []string) error
+
+	// DisableTOTP удаляет секрет и коды восстановления.
+	DisableTOTP(ctx context.Context, login string) error
+
+	// UseTOTPStep принимает интервал step, только если он новее последнего принятого.
+	// false означает, что код уже использовался.
+	UseTOTPStep(ctx context.Context, login string, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(ctx context.Context, login string, codeHashes []string) error
+
+	// UseRecoveryCode погашает код восстановления; false, если кода нет или он уже использован.
+	UseRecoveryCode(ctx context.Context, login string, codeHash string) (bool, error)
+
+	CreateChallenge(ctx context.Context, challenge *domain.MFAChallenge) error
+
+	// GetChallenge возвращает nil, nil для неизвестного или истекшего токена входа.
+	GetChallenge(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error)
+
+	// RegisterFailedAttempt увеличивает счетчик неверных кодов и удаляет вход,
+	// если попыток стало maxAttempts.
+	RegisterFailedAttempt(ctx context.Context, id int64, maxAttempts int) error
+
+	// DeleteChallenge удаляет вход; false, если его уже удалили (токен использован параллельно).
+	DeleteChallenge(ctx context.Context, id int64) (bool, error)
+
+	// DeleteExpiredChallenges удаляет входы, истекшие до before.
+	DeleteExpiredChallenges(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,102 @@
+package oidc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OIDCRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewOIDCRepository(logger *logger.Logger, db *sqlx.DB) *OIDCRepository {
+	return &OIDCRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *OIDCRepository) CreateLoginState(ctx context.Context, state *domain.OIDCLoginState) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO oidc_login_states (state_hash, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, state.StateHash, state.CodeVerifier, state.Nonce, state.ExpiresAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return err
+	}
+	return nil
+}
+
+func (r *OIDCRepository) ConsumeLoginState(ctx context.Context, stateHash string, now time.Time) (*domain.OIDCLoginState, error) {
+	var state domain.OIDCLoginState
+	err := r.db.GetContext(ctx, &state, `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND expires_at > $2
+		RETURNING state_hash, code_verifier, nonce, expires_at
+	`, stateHash, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *OIDCRepository) DeleteExpiredLoginStates(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < $1`, before)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return err
+	}
+	return nil
+}
+
+func (r *OIDCRepository) GetIdentityLogin(ctx context.Context, issuer string, subject string) (string, error) {
+	var login string
+	err := r.db.GetContext(ctx, &login, `
+		SELECT login_name FROM oidc_identities WHERE issuer = $1 AND subject = $2
+	`, issuer, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "issuer": issuer})
+		return "", err
+	}
+	return login, nil
+}
+
+func (r *OIDCRepository) LinkIdentity(ctx context.Context, identity *domain.OIDCIdentity, login string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO oidc_identities (issuer, subject, login_name, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+	`, identity.Issuer, identity.Subject, login, identity.Email)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "issuer": identity.Issuer, "login": login})
+		return err
+	}
+	return nil
+}
+
+func (r *OIDCRepository) TouchIdentity(ctx context.Context, issuer string, subject string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE oidc_identities SET last_login_at = $3 WHERE issuer = $1 AND subject = $2
+	`, issuer, subject, at)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "issuer": issuer})
+		return err
+	}
+	return nil
+}
+
+var _ IOIDCRepository = (*OIDCRepository)(nil)
@@ -0,0 +1,23 @@
+package oidc
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type IOIDCRepository interface {
+	CreateLoginState(ctx context.Context, state *domain.OIDCLoginState) error
+
+	// ConsumeLoginState удаляет и возвращает не истекший вход; nil, nil, если его нет.
+	ConsumeLoginState(ctx context.Context, stateHash string, now time.Time) (*domain.OIDCLoginState, error)
+
+	DeleteExpiredLoginStates(ctx context.Context, before time.Time) error
+
+	// GetIdentityLogin возвращает логин, привязанный к учетной записи провайдера, или "".
+	GetIdentityLogin(ctx context.Context, issuer string, subject string) (string, error)
+
+	LinkIdentity(ctx context.Context, identity *domain.OIDCIdentity, login string) error
+
+	TouchIdentity(ctx context.Context, issuer string, subject string, at time.Time) error
+}
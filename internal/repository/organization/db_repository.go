@@ -0,0 +1,310 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const uniqueViolationCode = "23505"
+
+const invitationColumns = `
+	i.id, i.org_id, o.name AS org_name, i.email, i.role, COALESCE(i.invited_by, '') AS invited_by,
+	i.created_at, i.expires_at, i.accepted_at
+`
+
+type OrganizationRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewOrganizationRepository(logger *logger.Logger, db *sqlx.DB) *OrganizationRepository {
+	return &OrganizationRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, data *domain.OrganizationData, ownerLogin string) (*domain.Organization, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	org := &domain.Organization{Name: data.Name, INN: data.INN, CreatedBy: ownerLogin, Role: domain.OrgRoleOwner}
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO organizations (name, inn, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, data.Name, data.INN, ownerLogin).Scan(&org.ID, &org.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrOrganizationINNExists
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": ownerLogin})
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organization_members (org_id, login_name, role) VALUES ($1, $2, $3)
+	`, org.ID, ownerLogin, domain.OrgRoleOwner)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": org.ID})
+		return nil, err
+	}
+
+	return org, tx.Commit()
+}
+
+func (r *OrganizationRepository) GetOrganization(ctx context.Context, id int64, login string) (*domain.Organization, error) {
+	var org domain.Organization
+	err := r.db.GetContext(ctx, &org, `
+		SELECT o.id, o.name, o.inn, COALESCE(o.created_by, '') AS created_by, o.created_at, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.org_id = o.id AND m.login_name = $2
+		WHERE o.id = $1
+	`, id, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": id})
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) ListUserOrganizations(ctx context.Context, login string) ([]domain.Organization, error) {
+	orgs := []domain.Organization{}
+	err := r.db.SelectContext(ctx, &orgs, `
+		SELECT o.id, o.name, o.inn, COALESCE(o.created_by, '') AS created_by, o.created_at, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.org_id = o.id
+		WHERE m.login_name = $1
+		ORDER BY o.name, o.id
+	`, login)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return nil, err
+	}
+	return orgs, nil
+}
+
+func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, id int64, data *domain.OrganizationData) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE organizations SET name = $2, inn = $3 WHERE id = $1`, id, data.Name, data.INN)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrOrganizationINNExists
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": id})
+		return err
+	}
+	return requireAffected(result, domain.ErrOrganizationNotFound)
+}
+
+func (r *OrganizationRepository) GetMemberRole(ctx context.Context, orgID int64, login string) (domain.OrgRole, error) {
+	var role domain.OrgRole
+	err := r.db.GetContext(ctx, &role, `
+		SELECT role FROM organization_members WHERE org_id = $1 AND login_name = $2
+	`, orgID, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": orgID, "login": login})
+		return "", err
+	}
+	return role, nil
+}
+
+func (r *OrganizationRepository) ListMembers(ctx context.Context, orgID int64) ([]domain.OrgMember, error) {
+	members := []domain.OrgMember{}
+	err := r.db.SelectContext(ctx, &members, `
+		SELECT m.org_id, m.login_name, COALESCE(p.part_name, '') AS name, COALESCE(u.email, '') AS email,
+			m.role, m.joined_at
+		FROM organization_members m
+		JOIN users u ON u.login_name = m.login_name
+		LEFT JOIN participants p ON p.part_id = u.part_id
+		WHERE m.org_id = $1
+		ORDER BY m.joined_at, m.login_name
+	`, orgID)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": orgID})
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *OrganizationRepository) UpdateMemberRole(ctx context.Context, orgID int64, login string, role domain.OrgRole) error {
+	return r.changeMember(ctx, orgID, login, func(tx *sqlx.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, `
+			UPDATE organization_members SET role = $3 WHERE org_id = $1 AND login_name = $2
+		`, orgID, login, role)
+	})
+}
+
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID int64, login string) error {
+	return r.changeMember(ctx, orgID, login, func(tx *sqlx.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, `
+			DELETE FROM organization_members WHERE org_id = $1 AND login_name = $2
+		`, orgID, login)
+	})
+}
+
+// changeMember выполняет change под блокировкой строки организации и откатывает его,
+// если в организации не осталось владельцев.
+func (r *OrganizationRepository) changeMember(ctx context.Context, orgID int64, login string, change func(tx *sqlx.Tx) (sql.Result, error)) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM organizations WHERE id = $1 FOR UPDATE`, orgID); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": orgID})
+		return err
+	}
+
+	result, err := change(tx)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": orgID, "login": login})
+		return err
+	}
+	if err := requireAffected(result, domain.ErrOrgMemberNotFound); err != nil {
+		return err
+	}
+
+	var owners int
+	err = tx.GetContext(ctx, &owners, `
+		SELECT COUNT(*) FROM organization_members WHERE org_id = $1 AND role = $2
+	`, orgID, domain.OrgRoleOwner)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": orgID})
+		return err
+	}
+	if owners == 0 {
+		return domain.ErrOrgLastOwner
+	}
+
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) CreateInvitation(ctx context.Context, invitation *domain.OrgInvitation, tokenHash string) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO organization_invitations (org_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, invitation.OrgID, invitation.Email, invitation.Role, tokenHash, invitation.InvitedBy, invitation.ExpiresAt,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": invitation.OrgID})
+		return err
+	}
+	return nil
+}
+
+func (r *OrganizationRepository) ListInvitations(ctx context.Context, orgID int64) ([]domain.OrgInvitation, error) {
+	invitations := []domain.OrgInvitation{}
+	err := r.db.SelectContext(ctx, &invitations, `
+		SELECT `+invitationColumns+`
+		FROM organization_invitations i
+		JOIN organizations o ON o.id = i.org_id
+		WHERE i.org_id = $1 AND i.accepted_at IS NULL
+		ORDER BY i.created_at DESC, i.id DESC
+	`, orgID)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": orgID})
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *OrganizationRepository) GetActiveInvitation(ctx context.Context, tokenHash string, now time.Time) (*domain.OrgInvitation, error) {
+	var invitation domain.OrgInvitation
+	err := r.db.GetContext(ctx, &invitation, `
+		SELECT `+invitationColumns+`
+		FROM organization_invitations i
+		JOIN organizations o ON o.id = i.org_id
+		WHERE i.token_hash = $1 AND i.accepted_at IS NULL AND i.expires_at > $2
+	`, tokenHash, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *OrganizationRepository) DeleteInvitation(ctx context.Context, orgID int64, id int64) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM organization_invitations WHERE id = $1 AND org_id = $2 AND accepted_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "invitation_id": id})
+		return err
+	}
+	return requireAffected(result, domain.ErrOrgInvitationNotFound)
+}
+
+func (r *OrganizationRepository) AcceptInvitation(ctx context.Context, invitation *domain.OrgInvitation, login string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE organization_invitations SET accepted_at = now(), accepted_by = $2
+		WHERE id = $1 AND accepted_at IS NULL
+	`, invitation.ID, login)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "invitation_id": invitation.ID})
+		return err
+	}
+	if err := requireAffected(result, domain.ErrOrgInvitationInvalid); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organization_members (org_id, login_name, role) VALUES ($1, $2, $3)
+	`, invitation.OrgID, login, invitation.Role)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrOrgAlreadyMember
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "org_id": invitation.OrgID, "login": login})
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode
+}
+
+var _ IOrganizationRepository = (*OrganizationRepository)(nil)
@@ -0,0 +1,46 @@
+package organization
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type IOrganizationRepository interface {
+	// CreateOrganization создает организацию и делает ownerLogin ее владельцем;
+	// domain.ErrOrganizationINNExists, если ИНН уже занят.
+	CreateOrganization(ctx context.Context, data *domain.OrganizationData, ownerLogin string) (*domain.Organization, error)
+
+	// GetOrganization возвращает организацию с ролью в ней пользователя login;
+	// nil, nil, если организации нет или пользователь в ней не состоит.
+	GetOrganization(ctx context.Context, id int64, login string) (*domain.Organization, error)
+
+	ListUserOrganizations(ctx context.Context, login string) ([]domain.Organization, error)
+
+	UpdateOrganization(ctx context.Context, id int64, data *domain.OrganizationData) error
+
+	// GetMemberRole возвращает роль пользователя в организации или "", если он в ней не состоит.
+	GetMemberRole(ctx context.Context, orgID int64, login string) (domain.OrgRole, error)
+
+	ListMembers(ctx context.Context, orgID int64) ([]domain.OrgMember, error)
+
+	// UpdateMemberRole и RemoveMember возвращают domain.ErrOrgLastOwner, если в организации
+	// не осталось бы владельцев: проверка и изменение выполняются в одной транзакции.
+	UpdateMemberRole(ctx context.Context, orgID int64, login string, role domain.OrgRole) error
+	RemoveMember(ctx context.Context, orgID int64, login string) error
+
+	CreateInvitation(ctx context.Context, invitation *domain.OrgInvitation, tokenHash string) error
+
+	// ListInvitations возвращает непринятые приглашения, включая истекшие.
+	ListInvitations(ctx context.Context, orgID int64) ([]domain.OrgInvitation, error)
+
+	// GetActiveInvitation ищет непринятое и не истекшее приглашение по хешу токена; nil, nil, если его нет.
+	GetActiveInvitation(ctx context.Context, tokenHash string, now time.Time) (*domain.OrgInvitation, error)
+
+	DeleteInvitation(ctx context.Context, orgID int64, id int64) error
+
+	// AcceptInvitation добавляет пользователя в организацию и помечает приглашение принятым;
+	// domain.ErrOrgInvitationInvalid, если его уже приняли, domain.ErrOrgAlreadyMember,
+	// если пользователь уже состоит в организации.
+	AcceptInvitation(ctx context.Context, invitation *domain.OrgInvitation, login string) error
+}
@@ -0,0 +1,27 @@
+// Package ownership строит условия SQL, ограничивающие выборку областью данных:
+// организацией или личным учетом пользователя.
+package ownership
+
+import (
+	"strconv"
+
+	"finance-backend/internal/domain"
+)
+
+// ScopeCondition возвращает условие отбора записей области scope из таблицы alias и его
+// аргументы; параметры нумеруются с firstArg. Запись видна только своей организации
+// или своему пользователю.
+func ScopeCondition(alias string, scope domain.DataScope, firstArg int) (string, []interface{}) {
+	n := strconv.Itoa(firstArg)
+	if scope.IsOrganization() {
+		return alias + ".org_id = $" + n, []interface{}{scope.OrgID}
+	}
+	return "(" + alias + ".org_id IS NULL AND " + alias + ".owner_login = $" + n + ")", []interface{}{scope.Login}
+}
+
+// CategoryScopeCondition — как ScopeCondition, но для категорий: кроме собственных
+// категорий области доступен общий справочник (категории без владельца).
+func CategoryScopeCondition(alias string, scope domain.DataScope, firstArg int) (string, []interface{}) {
+	condition, args := ScopeCondition(alias, scope, firstArg)
+	return "(" + condition + " OR (" + alias + ".org_id IS NULL AND " + alias + ".owner_login IS NULL))", args
+}
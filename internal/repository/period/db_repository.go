@@ -0,0 +1,121 @@
+package period
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/repository/ownership"
+	"finance-backend/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const uniqueViolationCode = "23505"
+
+const periodColumns = `
+	p.id, p.month, p.closed, p.closed_by, p.closed_at,
+	COALESCE(p.reopened_by, '') AS reopened_by, p.reopened_at
+`
+
+type PeriodRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewPeriodRepository(logger *logger.Logger, db *sqlx.DB) *PeriodRepository {
+	return &PeriodRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *PeriodRepository) List(ctx context.Context, scope domain.DataScope) ([]domain.AccountingPeriod, error) {
+	condition, args := ownership.ScopeCondition("p", scope, 1)
+	query := `SELECT ` + periodColumns + ` FROM accounting_periods p WHERE ` + condition + ` ORDER BY p.month DESC`
+
+	periods := []domain.AccountingPeriod{}
+	if err := r.db.SelectContext(ctx, &periods, query, args...); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return periods, nil
+}
+
+func (r *PeriodRepository) Get(ctx context.Context, scope domain.DataScope, month time.Time) (*domain.AccountingPeriod, error) {
+	condition, args := ownership.ScopeCondition("p", scope, 2)
+	query := `SELECT ` + periodColumns + ` FROM accounting_periods p WHERE p.month = $1 AND ` + condition
+
+	var period domain.AccountingPeriod
+	err := r.db.GetContext(ctx, &period, query, append([]interface{}{month}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "month": month})
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (r *PeriodRepository) Close(ctx context.Context, scope domain.DataScope, month time.Time, closedBy string) (*domain.AccountingPeriod, error) {
+	// Период, который уже закрывали, закрывается повторно, иначе запись создается.
+	condition, args := ownership.ScopeCondition("p", scope, 3)
+	query := `
+		UPDATE accounting_periods p SET closed = TRUE, closed_by = $2, closed_at = CURRENT_TIMESTAMP
+		WHERE p.month = $1 AND NOT p.closed AND ` + condition + `
+		RETURNING ` + periodColumns
+
+	var period domain.AccountingPeriod
+	err := r.db.GetContext(ctx, &period, query, append([]interface{}{month, closedBy}, args...)...)
+	if err == nil {
+		return &period, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "month": month})
+		return nil, err
+	}
+
+	orgID, ownerLogin := scope.OrgID, ""
+	if !scope.IsOrganization() {
+		ownerLogin = scope.Login
+	}
+	query = `
+		INSERT INTO accounting_periods AS p (org_id, owner_login, month, closed_by)
+		VALUES (NULLIF($1, 0), NULLIF($2, ''), $3, $4)
+		RETURNING ` + periodColumns
+
+	err = r.db.GetContext(ctx, &period, query, orgID, ownerLogin, month, closedBy)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode {
+			return nil, domain.ErrPeriodAlreadyClosed
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "month": month})
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (r *PeriodRepository) Reopen(ctx context.Context, scope domain.DataScope, month time.Time, reopenedBy string) (*domain.AccountingPeriod, error) {
+	condition, args := ownership.ScopeCondition("p", scope, 3)
+	query := `
+		UPDATE accounting_periods p SET closed = FALSE, reopened_by = $2, reopened_at = CURRENT_TIMESTAMP
+		WHERE p.month = $1 AND p.closed AND ` + condition + `
+		RETURNING ` + periodColumns
+
+	var period domain.AccountingPeriod
+	err := r.db.GetContext(ctx, &period, query, append([]interface{}{month, reopenedBy}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrPeriodNotClosed
+	}
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err.Error(), "month": month})
+		return nil, err
+	}
+	return &period, nil
+}
+
+var _ IPeriodRepository = (*PeriodRepository)(nil)
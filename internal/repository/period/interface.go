@@ -0,0 +1,22 @@
+package period
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
+
+// IPeriodRepository — учетные периоды области данных scope. month — первое число месяца по UTC.
+type IPeriodRepository interface {
+	// List возвращает периоды, которые хотя бы раз закрывались, от последнего к первому.
+	List(ctx context.Context, scope domain.DataScope) ([]domain.AccountingPeriod, error)
+
+	// Get возвращает период или nil, если он ни разу не закрывался.
+	Get(ctx context.Context, scope domain.DataScope, month time.Time) (*domain.AccountingPeriod, error)
+
+	// Close закрывает период от имени closedBy. Закрытый период — domain.ErrPeriodAlreadyClosed.
+	Close(ctx context.Context, scope domain.DataScope, month time.Time, closedBy string) (*domain.AccountingPeriod, error)
+
+	// Reopen открывает закрытый период от имени reopenedBy, иначе domain.ErrPeriodNotClosed.
+	Reopen(ctx context.Context, scope domain.DataScope, month time.Time, reopenedBy string) (*domain.AccountingPeriod, error)
+}
@@ -0,0 +1,220 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const refreshTokenColumns = `
+	id, login_name, COALESCE(org_id, 0) AS org_id, token_hash, family_id, access_token_id, expires_at, created_at, revoked_at
+`
+
+type TokenRepository struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewTokenRepository(logger *logger.Logger, db *sqlx.DB) *TokenRepository {
+	return &TokenRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	// Заодно убираем истекшие токены пользователя, чтобы таблица не росла бесконечно.
+	_, err := r.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE login_name = $1 AND expires_at < now()`, token.Login)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": token.Login})
+		return err
+	}
+
+	if err := insertRefreshToken(ctx, r.db, token); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": token.Login})
+		return err
+	}
+	return nil
+}
+
+func (r *TokenRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.GetContext(ctx, &token, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *TokenRepository) RotateRefreshToken(ctx context.Context, id int64, next *domain.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "id": id})
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrInvalidRefreshToken
+	}
+
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": next.Login})
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TokenRepository) RevokeRefreshToken(ctx context.Context, login string, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = now()
+		WHERE login_name = $1 AND token_hash = $2 AND revoked_at IS NULL
+	`, login, tokenHash)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+	return nil
+}
+
+func (r *TokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "family_id": familyID})
+		return err
+	}
+	return nil
+}
+
+func (r *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, login string, since time.Time) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE login_name = $1 AND revoked_at IS NULL`, login)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return nil, err
+	}
+
+	var ids []string
+	err = tx.SelectContext(ctx, &ids, `SELECT access_token_id FROM refresh_tokens WHERE login_name = $1 AND created_at >= $2`, login, since)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return nil, err
+	}
+
+	return ids, tx.Commit()
+}
+
+func (r *TokenRepository) RevokeAccessTokens(ctx context.Context, ids []string, expiresAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM revoked_access_tokens WHERE expires_at < now()`)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO revoked_access_tokens (jti, expires_at)
+		SELECT unnest($1::varchar[]), $2
+		ON CONFLICT (jti) DO UPDATE SET expires_at = GREATEST(revoked_access_tokens.expires_at, EXCLUDED.expires_at)
+	`, pq.Array(ids), expiresAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return err
+	}
+	return nil
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	var revoked bool
+	err := r.db.GetContext(ctx, &revoked, `SELECT EXISTS(SELECT 1 FROM revoked_access_tokens WHERE jti = $1)`, id)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return false, err
+	}
+	return revoked, nil
+}
+
+func insertRefreshToken(ctx context.Context, db sqlx.QueryerContext, token *domain.RefreshToken) error {
+	return sqlx.GetContext(ctx, db, token, `
+		INSERT INTO refresh_tokens (login_name, token_hash, family_id, access_token_id, expires_at, org_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0))
+		RETURNING `+refreshTokenColumns,
+		token.Login, token.TokenHash, token.FamilyID, token.AccessTokenID, token.ExpiresAt, token.OrgID,
+	)
+}
+
+var _ ITokenRepository = (*TokenRepository)(nil)
+
+func (r *TokenRepository) CreatePasswordResetToken(ctx context.Context, login string, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Error(ctx, "error starting transaction", map[string]interface{}{"error": err})
+		return err
+	}
+	defer tx.Rollback()
+
+	// Действует только последняя ссылка; использованные и истекшие записи больше не нужны.
+	_, err = tx.ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE login_name = $1 OR expires_at < now()`, login)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO password_reset_tokens (login_name, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, login, tokenHash, expiresAt)
+	if err != nil {
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err, "login": login})
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TokenRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (string, error) {
+	var login string
+	err := r.db.GetContext(ctx, &login, `
+		UPDATE password_reset_tokens
+		SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING login_name
+	`, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrInvalidPasswordResetToken
+		}
+		r.log.Error(ctx, "error performing db op", map[string]interface{}{"error": err})
+		return "", err
+	}
+	return login, nil
+}
@@ -0,0 +1,40 @@
+package token
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
+
+type ITokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+
+	// GetRefreshToken возвращает nil, nil, если токена с таким хешем нет.
+	GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+
+	// RotateRefreshToken отзывает токен id и сохраняет следующий токен цепочки.
+	// Если токен уже отозван (его успели использовать), возвращает domain.ErrInvalidRefreshToken.
+	RotateRefreshToken(ctx context.Context, id int64, next *domain.RefreshToken) error
+
+	// RevokeRefreshToken отзывает токен пользователя login; чужой или неизвестный токен игнорируется.
+	RevokeRefreshToken(ctx context.Context, login string, tokenHash string) error
+
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+
+	// RevokeUserRefreshTokens отзывает все refresh-токены пользователя и возвращает jti
+	// токенов доступа, выданных после since, — они еще могут быть действительны.
+	RevokeUserRefreshTokens(ctx context.Context, login string, since time.Time) ([]string, error)
+
+	// RevokeAccessTokens добавляет jti в список отозванных до момента expiresAt.
+	RevokeAccessTokens(ctx context.Context, ids []string, expiresAt time.Time) error
+
+	IsAccessTokenRevoked(ctx context.Context, id string) (bool, error)
+
+	// CreatePasswordResetToken сохраняет токен сброса пароля; прежние неиспользованные
+	// токены пользователя перестают действовать.
+	CreatePasswordResetToken(ctx context.Context, login string, tokenHash string, expiresAt time.Time) error
+
+	// ConsumePasswordResetToken отмечает токен использованным и возвращает логин его владельца.
+	// Для неизвестного, истекшего или уже использованного токена — domain.ErrInvalidPasswordResetToken.
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) (string, error)
+}
@@ -2,13 +2,72 @@ package transaction
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"finance-backend/internal/domain"
 	"finance-backend/internal/domain/transaction"
+	"finance-backend/internal/repository/ownership"
 	"finance-backend/pkg/logger"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+const uniqueViolationCode = "23505"
+
+// periodClosedCode — ошибка триггера transactions_period_lock: транзакция в закрытом периоде.
+const periodClosedCode = "FP001"
+
+// categoryCycleCode — ошибка триггера categories_no_cycles.
+const categoryCycleCode = "FC001"
+
+const createTransactionQuery = `
+	INSERT INTO transactions (
+		user_type,
+		date_time,
+		trans_type,
+		amount,
+		category_id,
+		status_id,
+		sender_bank,
+		sender_bank_bic,
+		counterparty_id,
+		receiver_inn,
+		receiver_phone,
+		comment,
+		org_id,
+		owner_login
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, 0), $10, $11, $12, NULLIF($13, 0), NULLIF($14, '')
+	) RETURNING id
+`
+
+// transactionReturning — столбцы транзакции t для RETURNING при переносе в корзину,
+// восстановлении и окончательном удалении.
+const transactionReturning = `
+	RETURNING
+		t.id,
+		t.user_type,
+		t.date_time,
+		t.trans_type,
+		t.amount,
+		t.category_id,
+		t.status_id,
+		t.sender_bank,
+		COALESCE(t.sender_bank_bic, '') as sender_bank_bic,
+		COALESCE(t.counterparty_id, 0) as counterparty_id,
+		t.receiver_inn,
+		t.receiver_phone,
+		t.comment,
+		COALESCE(t.org_id, 0) as org_id,
+		COALESCE(t.owner_login, '') as owner_login,
+		t.deleted_at,
+		COALESCE(t.deleted_by, '') as deleted_by
+`
+
 type TransactionRepository struct {
 	db     *sqlx.DB
 	logger *logger.Logger
@@ -21,7 +80,20 @@ func NewTransactionRepository(db *sqlx.DB, logger *logger.Logger) *TransactionRe
 	}
 }
 
-func (r *TransactionRepository) GetTransactions(ctx context.Context, filter *transaction.TransactionFilter) ([]transaction.Transaction, error) {
+// versionsAsOf — версии транзакций, действовавшие в момент $n; столбцы повторяют transactions.
+const versionsAsOf = `(SELECT * FROM transaction_versions v WHERE v.valid_from <= $n AND (v.valid_to IS NULL OR v.valid_to > $n))`
+
+// TransactionSource возвращает источник строк транзакций для FROM или JOIN и его аргументы:
+// таблицу transactions или, если задан asOf, состояние транзакций на этот момент из истории
+// версий. Параметр получает номер firstArg.
+func TransactionSource(asOf *time.Time, firstArg int) (string, []interface{}) {
+	if asOf == nil {
+		return "transactions", nil
+	}
+	return strings.ReplaceAll(versionsAsOf, "$n", "$"+strconv.Itoa(firstArg)), []interface{}{*asOf}
+}
+
+func (r *TransactionRepository) GetTransactions(ctx context.Context, scope domain.DataScope, filter *transaction.TransactionFilter) ([]transaction.Transaction, error) {
 	query := `
 		SELECT 
 			transactions.id,
@@ -32,6 +104,8 @@ func (r *TransactionRepository) GetTransactions(ctx context.Context, filter *tra
 			transactions.category_id,
 			transactions.status_id,
 			transactions.sender_bank,
+			COALESCE(transactions.sender_bank_bic, '') as sender_bank_bic,
+			COALESCE(transactions.counterparty_id, 0) as counterparty_id,
 			transactions.receiver_inn,
 			transactions.receiver_phone,
 			transactions.comment,
@@ -39,13 +113,19 @@ func (r *TransactionRepository) GetTransactions(ctx context.Context, filter *tra
 			c.type as category_type,
 			s.name as status_name,
 			s.description as status_description
-		FROM transactions
+		FROM `
+
+	condition, args := ownership.ScopeCondition("transactions", scope, 1)
+	var asOf *time.Time
+	if filter != nil {
+		asOf = filter.AsOf
+	}
+	source, sourceArgs := TransactionSource(asOf, len(args)+1)
+	args = append(args, sourceArgs...)
+	query += source + ` transactions
 		LEFT JOIN categories c ON transactions.category_id = c.id
 		LEFT JOIN transaction_statuses s ON transactions.status_id = s.id
-		WHERE 1=1
-	`
-
-	args := []interface{}{}
+		WHERE transactions.deleted_at IS NULL AND ` + condition
 	if filter != nil {
 		if filter.UserType != "" {
 			query += " AND transactions.user_type = $" + strconv.Itoa(len(args)+1)
@@ -59,6 +139,14 @@ func (r *TransactionRepository) GetTransactions(ctx context.Context, filter *tra
 			query += " AND transactions.sender_bank = $" + strconv.Itoa(len(args)+1)
 			args = append(args, filter.SenderBank)
 		}
+		if filter.SenderBankBIC != "" {
+			query += " AND transactions.sender_bank_bic = $" + strconv.Itoa(len(args)+1)
+			args = append(args, filter.SenderBankBIC)
+		}
+		if filter.CounterpartyID != 0 {
+			query += " AND transactions.counterparty_id = $" + strconv.Itoa(len(args)+1)
+			args = append(args, filter.CounterpartyID)
+		}
 		if filter.ReceiverINN != "" {
 			query += " AND transactions.receiver_inn = $" + strconv.Itoa(len(args)+1)
 			args = append(args, filter.ReceiverINN)
@@ -96,32 +184,41 @@ func (r *TransactionRepository) GetTransactions(ctx context.Context, filter *tra
 	return transactions, nil
 }
 
-func (r *TransactionRepository) GetPreparedTransactions(ctx context.Context) ([]transaction.PreparedTransaction, error) {
+// preparedTransactionColumns — столбцы подготовленной транзакции с названиями категории
+// и статуса; необязательные поля подготовленного платежа могут быть NULL.
+const preparedTransactionColumns = `
+	t.id,
+	t.user_type,
+	t.date_time,
+	t.trans_type,
+	t.amount,
+	COALESCE(t.category_id, 0) as category_id,
+	COALESCE(t.status_id, 0) as status_id,
+	COALESCE(t.sender_bank, '') as sender_bank,
+	COALESCE(t.sender_bank_bic, '') as sender_bank_bic,
+	COALESCE(t.counterparty_id, 0) as counterparty_id,
+	COALESCE(t.receiver_inn, '') as receiver_inn,
+	COALESCE(t.receiver_phone, '') as receiver_phone,
+	COALESCE(t.comment, '') as comment,
+	COALESCE(c.name, '') as category_name,
+	COALESCE(c.type, '') as category_type,
+	COALESCE(s.name, '') as status_name,
+	COALESCE(s.description, '') as status_description
+`
+
+func (r *TransactionRepository) GetPreparedTransactions(ctx context.Context, scope domain.DataScope) ([]transaction.PreparedTransaction, error) {
+	condition, args := ownership.ScopeCondition("t", scope, 1)
 	query := `
-		SELECT 
-			t.id,
-			t.user_type,
-			t.date_time,
-			t.trans_type,
-			t.amount,
-			t.category_id,
-			t.status_id,
-			t.sender_bank,
-			t.receiver_inn,
-			t.receiver_phone,
-			t.comment,
-			c.name as category_name,
-			c.type as category_type,
-			s.name as status_name,
-			s.description as status_description
+		SELECT ` + preparedTransactionColumns + `
 		FROM prepared_transactions t
 		LEFT JOIN categories c ON t.category_id = c.id
-		LEFT JOIN trans_statuses s ON t.status_id = s.id
+		LEFT JOIN transaction_statuses s ON t.status_id = s.id
+		WHERE ` + condition + `
 		ORDER BY t.date_time DESC
 	`
 
 	var transactions []transaction.PreparedTransaction
-	if err := r.db.SelectContext(ctx, &transactions, query); err != nil {
+	if err := r.db.SelectContext(ctx, &transactions, query, args...); err != nil {
 		r.logger.Error(ctx, "error getting prepared transactions", map[string]interface{}{"error": err.Error()})
 		return nil, err
 	}
@@ -129,18 +226,46 @@ func (r *TransactionRepository) GetPreparedTransactions(ctx context.Context) ([]
 	return transactions, nil
 }
 
-func (r *TransactionRepository) GetCategories(ctx context.Context) ([]transaction.Category, error) {
+func (r *TransactionRepository) GetPreparedTransactionByID(ctx context.Context, scope domain.DataScope, id int) (*transaction.PreparedTransaction, error) {
+	condition, args := ownership.ScopeCondition("t", scope, 2)
+	query := `
+		SELECT ` + preparedTransactionColumns + `
+		FROM prepared_transactions t
+		LEFT JOIN categories c ON t.category_id = c.id
+		LEFT JOIN transaction_statuses s ON t.status_id = s.id
+		WHERE t.id = $1 AND ` + condition + `
+	`
+
+	var t transaction.PreparedTransaction
+	err := r.db.GetContext(ctx, &t, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, transaction.ErrTransactionNotFound
+	}
+	if err != nil {
+		r.logger.Error(ctx, "error getting prepared transaction", map[string]interface{}{"error": err.Error(), "id": id})
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *TransactionRepository) GetCategories(ctx context.Context, scope domain.DataScope) ([]transaction.Category, error) {
+	condition, args := ownership.CategoryScopeCondition("c", scope, 1)
 	query := `
 		SELECT 
-			id,
-			name,
-			COALESCE(type, '') as type
-		FROM categories
-		ORDER BY name
+			c.id,
+			c.name,
+			COALESCE(c.type, '') as type,
+			COALESCE(c.parent_id, 0) as parent_id,
+			COALESCE(c.org_id, 0) as org_id,
+			COALESCE(c.owner_login, '') as owner_login
+		FROM categories c
+		WHERE c.archived_at IS NULL AND ` + condition + `
+		ORDER BY c.name
 	`
 
 	var categories []transaction.Category
-	if err := r.db.SelectContext(ctx, &categories, query); err != nil {
+	if err := r.db.SelectContext(ctx, &categories, query, args...); err != nil {
 		r.logger.Error(ctx, "error getting categories", map[string]interface{}{"error": err.Error()})
 		return nil, err
 	}
@@ -148,6 +273,44 @@ func (r *TransactionRepository) GetCategories(ctx context.Context) ([]transactio
 	return categories, nil
 }
 
+func (r *TransactionRepository) SetCategoryParent(ctx context.Context, id int, parentID int) error {
+	query := `UPDATE categories SET parent_id = NULLIF($2, 0), updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, parentID); err != nil {
+		if isCategoryCycle(err) {
+			return domain.ErrCategoryCycle
+		}
+		r.logger.Error(ctx, "error setting category parent", map[string]interface{}{"error": err.Error(), "id": id})
+		return err
+	}
+	return nil
+}
+
+func (r *TransactionRepository) GetCategory(ctx context.Context, scope domain.DataScope, id int) (*transaction.Category, error) {
+	condition, args := ownership.CategoryScopeCondition("c", scope, 2)
+	query := `
+		SELECT 
+			c.id,
+			c.name,
+			COALESCE(c.type, '') as type,
+			COALESCE(c.parent_id, 0) as parent_id,
+			COALESCE(c.org_id, 0) as org_id,
+			COALESCE(c.owner_login, '') as owner_login,
+			c.archived_at
+		FROM categories c
+		WHERE c.id = $1 AND ` + condition
+
+	var category transaction.Category
+	err := r.db.GetContext(ctx, &category, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrCategoryNotFound
+	}
+	if err != nil {
+		r.logger.Error(ctx, "error getting category", map[string]interface{}{"error": err.Error(), "id": id})
+		return nil, err
+	}
+	return &category, nil
+}
+
 func (r *TransactionRepository) GetTransactionStatuses(ctx context.Context) ([]transaction.TransactionStatus, error) {
 	query := `
 		SELECT 
@@ -167,47 +330,165 @@ func (r *TransactionRepository) GetTransactionStatuses(ctx context.Context) ([]t
 	return statuses, nil
 }
 
-func (r *TransactionRepository) DeleteTransaction(ctx context.Context, id int) error {
-	query := "DELETE FROM transactions WHERE id = $1"
-	result, err := r.db.ExecContext(ctx, query, id)
+func (r *TransactionRepository) DeleteTransaction(ctx context.Context, scope domain.DataScope, id int, deletedBy string) (*transaction.Transaction, error) {
+	condition, args := ownership.ScopeCondition("t", scope, 3)
+	query := `
+		UPDATE transactions t SET deleted_at = CURRENT_TIMESTAMP, deleted_by = NULLIF($2, '')
+		WHERE t.id = $1 AND t.deleted_at IS NULL AND ` + condition + transactionReturning
+
+	var deleted transaction.Transaction
+	err := r.db.GetContext(ctx, &deleted, query, append([]interface{}{id, deletedBy}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error(ctx, "transaction not found", map[string]interface{}{"id": id})
+		return nil, transaction.ErrTransactionNotFound
+	}
+	if isPeriodClosed(err) {
+		return nil, domain.ErrPeriodClosed
+	}
 	if err != nil {
 		r.logger.Error(ctx, "error deleting transaction", map[string]interface{}{"error": err.Error(), "id": id})
-		return err
+		return nil, err
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		r.logger.Error(ctx, "error getting rows affected", map[string]interface{}{"error": err.Error(), "id": id})
-		return err
-	}
+	return &deleted, nil
+}
 
-	if rows == 0 {
-		r.logger.Error(ctx, "transaction not found", map[string]interface{}{"id": id})
-		return transaction.ErrTransactionNotFound
+func (r *TransactionRepository) GetDeletedTransactions(ctx context.Context, scope domain.DataScope) ([]transaction.Transaction, error) {
+	condition, args := ownership.ScopeCondition("t", scope, 1)
+	query := `
+		SELECT 
+			t.id,
+			t.user_type,
+			t.date_time,
+			t.trans_type,
+			t.amount,
+			t.category_id,
+			t.status_id,
+			t.sender_bank,
+			COALESCE(t.sender_bank_bic, '') as sender_bank_bic,
+			COALESCE(t.counterparty_id, 0) as counterparty_id,
+			t.receiver_inn,
+			t.receiver_phone,
+			t.comment,
+			c.name as category_name,
+			c.type as category_type,
+			s.name as status_name,
+			s.description as status_description,
+			t.deleted_at,
+			COALESCE(t.deleted_by, '') as deleted_by
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id
+		LEFT JOIN transaction_statuses s ON t.status_id = s.id
+		WHERE t.deleted_at IS NOT NULL AND ` + condition + `
+		ORDER BY t.deleted_at DESC
+	`
+
+	var transactions []transaction.Transaction
+	if err := r.db.SelectContext(ctx, &transactions, query, args...); err != nil {
+		r.logger.Error(ctx, "error getting deleted transactions", map[string]interface{}{"error": err.Error()})
+		return nil, err
 	}
 
-	return nil
+	return transactions, nil
 }
 
-func (r *TransactionRepository) CreateTransaction(ctx context.Context, t *transaction.Transaction) error {
+func (r *TransactionRepository) GetTransactionHistory(ctx context.Context, scope domain.DataScope, id int) ([]transaction.TransactionVersion, error) {
+	condition, args := ownership.ScopeCondition("v", scope, 2)
 	query := `
-		INSERT INTO transactions (
-			user_type,
-			date_time,
-			trans_type,
-			amount,
-			category_id,
-			status_id,
-			sender_bank,
-			receiver_inn,
-			receiver_phone,
-			comment
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
-		) RETURNING id
+		SELECT 
+			v.version_id,
+			v.valid_from,
+			v.valid_to,
+			v.id,
+			v.user_type,
+			v.date_time,
+			v.trans_type,
+			v.amount,
+			COALESCE(v.category_id, 0) as category_id,
+			COALESCE(v.status_id, 0) as status_id,
+			COALESCE(v.sender_bank, '') as sender_bank,
+			COALESCE(v.sender_bank_bic, '') as sender_bank_bic,
+			COALESCE(v.counterparty_id, 0) as counterparty_id,
+			COALESCE(v.receiver_inn, '') as receiver_inn,
+			COALESCE(v.receiver_phone, '') as receiver_phone,
+			COALESCE(v.comment, '') as comment,
+			COALESCE(c.name, '') as category_name,
+			COALESCE(c.type, '') as category_type,
+			COALESCE(s.name, '') as status_name,
+			COALESCE(s.description, '') as status_description,
+			v.deleted_at,
+			COALESCE(v.deleted_by, '') as deleted_by
+		FROM transaction_versions v
+		LEFT JOIN categories c ON v.category_id = c.id
+		LEFT JOIN transaction_statuses s ON v.status_id = s.id
+		WHERE v.id = $1 AND ` + condition + `
+		ORDER BY v.valid_from, v.version_id
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	var versions []transaction.TransactionVersion
+	if err := r.db.SelectContext(ctx, &versions, query, append([]interface{}{id}, args...)...); err != nil {
+		r.logger.Error(ctx, "error getting transaction history", map[string]interface{}{"error": err.Error(), "id": id})
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (r *TransactionRepository) RestoreTransaction(ctx context.Context, scope domain.DataScope, id int) (*transaction.Transaction, error) {
+	condition, args := ownership.ScopeCondition("t", scope, 2)
+	query := `
+		UPDATE transactions t SET deleted_at = NULL, deleted_by = NULL
+		WHERE t.id = $1 AND t.deleted_at IS NOT NULL AND ` + condition + transactionReturning
+
+	var restored transaction.Transaction
+	err := r.db.GetContext(ctx, &restored, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, transaction.ErrTransactionNotFound
+	}
+	if isPeriodClosed(err) {
+		return nil, domain.ErrPeriodClosed
+	}
+	if err != nil {
+		r.logger.Error(ctx, "error restoring transaction", map[string]interface{}{"error": err.Error(), "id": id})
+		return nil, err
+	}
+
+	return &restored, nil
+}
+
+func (r *TransactionRepository) GetPurgeableTransactionIDs(ctx context.Context, before time.Time, limit int) ([]int, error) {
+	var ids []int
+	err := r.db.SelectContext(ctx, &ids, `
+		SELECT id FROM transactions
+		WHERE deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`, before, limit)
+	if err != nil {
+		r.logger.Error(ctx, "error getting purgeable transactions", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *TransactionRepository) PurgeTransaction(ctx context.Context, id int) (*transaction.Transaction, error) {
+	query := `DELETE FROM transactions t WHERE t.id = $1 AND t.deleted_at IS NOT NULL` + transactionReturning
+
+	var purged transaction.Transaction
+	err := r.db.GetContext(ctx, &purged, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, transaction.ErrTransactionNotFound
+	}
+	if err != nil {
+		r.logger.Error(ctx, "error purging transaction", map[string]interface{}{"error": err.Error(), "id": id})
+		return nil, err
+	}
+
+	return &purged, nil
+}
+
+func (r *TransactionRepository) CreateTransaction(ctx context.Context, t *transaction.Transaction) error {
+	err := r.db.QueryRowContext(ctx, createTransactionQuery,
 		t.UserType,
 		t.DateTime,
 		t.TransType,
@@ -215,11 +496,18 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, t *transa
 		t.CategoryID,
 		t.StatusID,
 		t.SenderBank,
+		t.SenderBankBIC,
+		t.CounterpartyID,
 		t.ReceiverINN,
 		t.ReceiverPhone,
 		t.Comment,
+		t.OrgID,
+		t.OwnerLogin,
 	).Scan(&t.ID)
 
+	if isPeriodClosed(err) {
+		return domain.ErrPeriodClosed
+	}
 	if err != nil {
 		r.logger.Error(ctx, "error creating transaction", map[string]interface{}{"error": err.Error()})
 		return err
@@ -238,11 +526,15 @@ func (r *TransactionRepository) CreatePreparedTransaction(ctx context.Context, t
 			category_id,
 			status_id,
 			sender_bank,
+			sender_bank_bic,
+			counterparty_id,
 			receiver_inn,
 			receiver_phone,
-			comment
+			comment,
+			org_id,
+			owner_login
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, 0), $10, $11, $12, NULLIF($13, 0), NULLIF($14, '')
 		) RETURNING id
 	`
 
@@ -254,9 +546,13 @@ func (r *TransactionRepository) CreatePreparedTransaction(ctx context.Context, t
 		t.CategoryID,
 		t.StatusID,
 		t.SenderBank,
+		t.SenderBankBIC,
+		t.CounterpartyID,
 		t.ReceiverINN,
 		t.ReceiverPhone,
 		t.Comment,
+		t.OrgID,
+		t.OwnerLogin,
 	).Scan(&t.ID)
 
 	if err != nil {
@@ -267,5 +563,205 @@ func (r *TransactionRepository) CreatePreparedTransaction(ctx context.Context, t
 	return nil
 }
 
+func (r *TransactionRepository) GetUnresolvedSenderBanks(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT sender_bank FROM transactions
+		WHERE sender_bank_bic IS NULL AND COALESCE(sender_bank, '') <> ''
+		UNION
+		SELECT sender_bank FROM prepared_transactions
+		WHERE sender_bank_bic IS NULL AND COALESCE(sender_bank, '') <> ''
+	`
+
+	var names []string
+	if err := r.db.SelectContext(ctx, &names, query); err != nil {
+		r.logger.Error(ctx, "error getting unresolved sender banks", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	return names, nil
+}
+
+func (r *TransactionRepository) SetSenderBankBIC(ctx context.Context, senderBank string, bic string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "error starting transaction", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"transactions", "prepared_transactions"} {
+		query := "UPDATE " + table + " SET sender_bank_bic = $1 WHERE sender_bank = $2 AND sender_bank_bic IS NULL"
+		if _, err := tx.ExecContext(ctx, query, bic, senderBank); err != nil {
+			r.logger.Error(ctx, "error setting sender bank bic", map[string]interface{}{"error": err.Error(), "table": table, "sender_bank": senderBank})
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TransactionRepository) CreateReceiptTransaction(ctx context.Context, t *transaction.Transaction, receipt *transaction.FiscalReceipt) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "error starting transaction", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, createTransactionQuery,
+		t.UserType,
+		t.DateTime,
+		t.TransType,
+		t.Amount,
+		t.CategoryID,
+		t.StatusID,
+		t.SenderBank,
+		t.SenderBankBIC,
+		t.CounterpartyID,
+		t.ReceiverINN,
+		t.ReceiverPhone,
+		t.Comment,
+		t.OrgID,
+		t.OwnerLogin,
+	).Scan(&t.ID)
+	if isPeriodClosed(err) {
+		return domain.ErrPeriodClosed
+	}
+	if err != nil {
+		r.logger.Error(ctx, "error creating transaction", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	receipt.TransactionID = t.ID
+	query := `
+		INSERT INTO fiscal_receipts (
+			transaction_id,
+			fiscal_drive,
+			fiscal_document,
+			fiscal_sign,
+			operation,
+			amount,
+			receipt_time,
+			seller_inn,
+			org_id,
+			owner_login
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, 0), NULLIF($10, '')
+		) RETURNING id
+	`
+	err = tx.QueryRowContext(ctx, query,
+		receipt.TransactionID,
+		receipt.FiscalDrive,
+		receipt.FiscalDocument,
+		receipt.FiscalSign,
+		receipt.Operation,
+		receipt.Amount,
+		receipt.ReceiptTime,
+		receipt.SellerINN,
+		t.OrgID,
+		t.OwnerLogin,
+	).Scan(&receipt.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrReceiptAlreadyImported
+		}
+		r.logger.Error(ctx, "error creating fiscal receipt", map[string]interface{}{"error": err.Error(), "fiscal_drive": receipt.FiscalDrive})
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TransactionRepository) GetFiscalReceipt(ctx context.Context, scope domain.DataScope, fiscalDrive, fiscalDocument, fiscalSign string) (*transaction.FiscalReceipt, error) {
+	condition, args := ownership.ScopeCondition("r", scope, 4)
+	query := `
+		SELECT
+			id,
+			transaction_id,
+			fiscal_drive,
+			fiscal_document,
+			fiscal_sign,
+			operation,
+			amount,
+			receipt_time,
+			COALESCE(seller_inn, '') as seller_inn
+		FROM fiscal_receipts r
+		WHERE fiscal_drive = $1 AND fiscal_document = $2 AND fiscal_sign = $3 AND ` + condition + `
+	`
+
+	var receipt transaction.FiscalReceipt
+	err := r.db.GetContext(ctx, &receipt, query, append([]interface{}{fiscalDrive, fiscalDocument, fiscalSign}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "error getting fiscal receipt", map[string]interface{}{"error": err.Error(), "fiscal_drive": fiscalDrive})
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+func (r *TransactionRepository) GetSellerINNByFiscalDrive(ctx context.Context, scope domain.DataScope, fiscalDrive string) (string, error) {
+	condition, args := ownership.ScopeCondition("r", scope, 2)
+	query := `
+		SELECT seller_inn FROM fiscal_receipts r
+		WHERE fiscal_drive = $1 AND seller_inn IS NOT NULL AND ` + condition + `
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var inn string
+	err := r.db.GetContext(ctx, &inn, query, append([]interface{}{fiscalDrive}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "error getting seller inn", map[string]interface{}{"error": err.Error(), "fiscal_drive": fiscalDrive})
+		return "", err
+	}
+
+	return inn, nil
+}
+
+func (r *TransactionRepository) SuggestCategoryByINN(ctx context.Context, scope domain.DataScope, inn string, transType string) (int, error) {
+	condition, args := ownership.ScopeCondition("t", scope, 3)
+	query := `
+		SELECT t.category_id FROM transactions t
+		WHERE t.receiver_inn = $1 AND t.trans_type = $2 AND t.category_id IS NOT NULL
+			AND t.deleted_at IS NULL AND ` + condition + `
+		GROUP BY t.category_id
+		ORDER BY COUNT(*) DESC, MAX(date_time) DESC
+		LIMIT 1
+	`
+
+	var categoryID int
+	err := r.db.GetContext(ctx, &categoryID, query, append([]interface{}{inn, transType}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "error suggesting category", map[string]interface{}{"error": err.Error(), "inn": inn})
+		return 0, err
+	}
+
+	return categoryID, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode
+}
+
+func isPeriodClosed(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == periodClosedCode
+}
+
+func isCategoryCycle(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == categoryCycleCode
+}
+
 // Проверка соответствия интерфейсу
 var _ transaction.Repository = (*TransactionRepository)(nil)
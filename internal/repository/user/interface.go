@@ -3,9 +3,23 @@ package user
 import (
 	"context"
 	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
 )
 
 type IUserRepository interface {
 	GetRawUserByLogin(ctx context.Context, login string) (*domain.RawUser, error)
+	// GetRawUserByEmail ищет пользователя по email без учета регистра; nil, nil, если не найден.
+	GetRawUserByEmail(ctx context.Context, email string) (*domain.RawUser, error)
 	CreateUser(ctx context.Context, creationData *domain.UserCreationData) error
+
+	SearchUsersPaginated(ctx context.Context, limit int, offset int, filter domain.UserSearchFilter) (utils.PaginatedEntities[domain.UserAccount], error)
+	// GetUserAccount возвращает nil, nil, если пользователя нет.
+	GetUserAccount(ctx context.Context, login string) (*domain.UserAccount, error)
+	IsUserBlocked(ctx context.Context, login string) (bool, error)
+
+	// Методы изменения возвращают domain.ErrUserNotFound, если пользователя нет.
+	UpdateUserRole(ctx context.Context, login string, role domain.Role) error
+	SetUserBlocked(ctx context.Context, login string, blocked bool) error
+	// SetUserPassword сохраняет хеш пароля и признак обязательной смены пароля при следующем входе.
+	SetUserPassword(ctx context.Context, login string, passwordHash string, resetRequired bool) error
 }
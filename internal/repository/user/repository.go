@@ -6,6 +6,7 @@ import (
 	"errors"
 	"finance-backend/internal/domain"
 	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -34,10 +35,10 @@ func (ur *UserRepository) CreateUser(ctx context.Context, data *domain.UserCreat
 
 	var participantID int
 	err = tx.GetContext(ctx, &participantID, `
-        INSERT INTO Participants (part_type, part_name, part_bank, part_account, part_inn, part_phone)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO Participants (part_type, part_name, part_bank, part_bic, part_account, part_inn, part_kpp, part_phone)
+        VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''))
         RETURNING part_id
-    `, data.UserType, data.Name, data.Bank, data.Account, data.INN, data.Phone)
+    `, data.UserType, data.Name, data.Bank, data.BIC, data.Account, data.INN, data.KPP, data.Phone)
 	if err != nil {
 		ur.log.Error(ctx, "error inserting participant", map[string]interface{}{
 			"error": err,
@@ -47,9 +48,9 @@ func (ur *UserRepository) CreateUser(ctx context.Context, data *domain.UserCreat
 	}
 
 	_, err = tx.ExecContext(ctx, `
-        INSERT INTO Users (login_name, password, role, part_id)
-        VALUES ($1, $2, 'user', $3)
-    `, data.Login, data.Password, participantID)
+        INSERT INTO Users (login_name, password, role, part_id, email)
+        VALUES ($1, $2, 'user', $3, NULLIF($4, ''))
+    `, data.Login, data.Password, participantID, data.Email)
 	if err != nil {
 		ur.log.Error(ctx, "error inserting user", map[string]interface{}{
 			"error": err,
@@ -70,17 +71,28 @@ func (ur *UserRepository) CreateUser(ctx context.Context, data *domain.UserCreat
 }
 
 func (ur *UserRepository) GetRawUserByLogin(ctx context.Context, login string) (*domain.RawUser, error) {
+	return ur.getRawUser(ctx, `login_name = $1`, login)
+}
+
+func (ur *UserRepository) GetRawUserByEmail(ctx context.Context, email string) (*domain.RawUser, error) {
+	return ur.getRawUser(ctx, `LOWER(email) = LOWER($1)`, email)
+}
+
+func (ur *UserRepository) getRawUser(ctx context.Context, condition string, arg string) (*domain.RawUser, error) {
 	var result struct {
-		Login        string `db:"login_name"`
-		PasswordHash string `db:"password"`
-		Role         string `db:"role"`
+		Login                 string `db:"login_name"`
+		PasswordHash          string `db:"password"`
+		Role                  string `db:"role"`
+		Email                 string `db:"email"`
+		Blocked               bool   `db:"blocked"`
+		PasswordResetRequired bool   `db:"password_reset_required"`
 	}
 
 	err := ur.db.GetContext(ctx, &result, `
-        SELECT login_name, password, role
+        SELECT login_name, password, role, COALESCE(email, '') AS email,
+            blocked_at IS NOT NULL AS blocked, password_reset_required
         FROM Users
-        WHERE login_name = $1
-    `, login)
+        WHERE `+condition, arg)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -88,18 +100,110 @@ func (ur *UserRepository) GetRawUserByLogin(ctx context.Context, login string) (
 		}
 		ur.log.Error(ctx, "error querying user", map[string]interface{}{
 			"error": err,
-			"login": login,
+			"user":  arg,
 		})
 		return nil, domain.ErrDBConnection
 	}
 
 	return &domain.RawUser{
-		User: domain.User{
-			Login:   result.Login,
-			IsAdmin: result.Role == "admin",
-		},
-		PasswordHash: result.PasswordHash,
+		User:                  domain.NewUser(result.Login, domain.Role(result.Role)),
+		PasswordHash:          result.PasswordHash,
+		Email:                 result.Email,
+		Blocked:               result.Blocked,
+		PasswordResetRequired: result.PasswordResetRequired,
+	}, nil
+}
+
+const userAccountQuery = `
+	SELECT u.login_name, u.role, COALESCE(p.part_type, '') AS part_type, COALESCE(p.part_name, '') AS part_name,
+		COALESCE(p.part_inn, '') AS part_inn, COALESCE(p.part_phone, '') AS part_phone,
+		COALESCE(u.email, '') AS email, u.blocked_at, u.password_reset_required
+	FROM users u
+	JOIN participants p ON p.part_id = u.part_id`
+
+func (ur *UserRepository) SearchUsersPaginated(ctx context.Context, limit int, offset int, filter domain.UserSearchFilter) (utils.PaginatedEntities[domain.UserAccount], error) {
+	where := ` WHERE ($1::text IS NULL OR u.login_name ILIKE '%' || $1 || '%' OR p.part_name ILIKE '%' || $1 || '%' OR p.part_inn LIKE $1 || '%' OR u.email ILIKE '%' || $1 || '%')
+		AND ($2::text IS NULL OR u.role = $2)
+		AND ($3::boolean IS NULL OR (u.blocked_at IS NOT NULL) = $3)`
+
+	var users []domain.UserAccount
+	err := ur.db.SelectContext(ctx, &users, userAccountQuery+where+` ORDER BY u.login_name LIMIT $4 OFFSET $5`,
+		filter.Search, filter.Role, filter.Blocked, limit, offset)
+	if err != nil {
+		ur.log.Error(ctx, "error querying users", map[string]interface{}{"error": err})
+		return utils.PaginatedEntities[domain.UserAccount]{}, domain.ErrDBConnection
+	}
+
+	var total int
+	err = ur.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM users u JOIN participants p ON p.part_id = u.part_id`+where,
+		filter.Search, filter.Role, filter.Blocked)
+	if err != nil {
+		ur.log.Error(ctx, "error counting users", map[string]interface{}{"error": err})
+		return utils.PaginatedEntities[domain.UserAccount]{}, domain.ErrDBConnection
+	}
+
+	return utils.PaginatedEntities[domain.UserAccount]{
+		Items:            users,
+		Total:            total,
+		PageNumber:       offset/limit + 1,
+		ObjectsCount:     len(users),
+		ObjectsCounTotal: total,
+		PageCount:        (total + limit - 1) / limit,
 	}, nil
 }
 
+func (ur *UserRepository) GetUserAccount(ctx context.Context, login string) (*domain.UserAccount, error) {
+	var user domain.UserAccount
+	err := ur.db.GetContext(ctx, &user, userAccountQuery+` WHERE u.login_name = $1`, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		ur.log.Error(ctx, "error querying user", map[string]interface{}{"error": err, "login": login})
+		return nil, domain.ErrDBConnection
+	}
+	return &user, nil
+}
+
+func (ur *UserRepository) IsUserBlocked(ctx context.Context, login string) (bool, error) {
+	var blocked bool
+	err := ur.db.GetContext(ctx, &blocked, `SELECT EXISTS(SELECT 1 FROM users WHERE login_name = $1 AND blocked_at IS NOT NULL)`, login)
+	if err != nil {
+		ur.log.Error(ctx, "error querying user", map[string]interface{}{"error": err, "login": login})
+		return false, domain.ErrDBConnection
+	}
+	return blocked, nil
+}
+
+func (ur *UserRepository) UpdateUserRole(ctx context.Context, login string, role domain.Role) error {
+	return ur.updateUser(ctx, login, `UPDATE users SET role = $2 WHERE login_name = $1`, role)
+}
+
+func (ur *UserRepository) SetUserBlocked(ctx context.Context, login string, blocked bool) error {
+	// Повторная блокировка сохраняет исходное время блокировки.
+	return ur.updateUser(ctx, login, `
+		UPDATE users
+		SET blocked_at = CASE WHEN $2 THEN COALESCE(blocked_at, CURRENT_TIMESTAMP) END
+		WHERE login_name = $1
+	`, blocked)
+}
+
+func (ur *UserRepository) SetUserPassword(ctx context.Context, login string, passwordHash string, resetRequired bool) error {
+	return ur.updateUser(ctx, login, `UPDATE users SET password = $2, password_reset_required = $3 WHERE login_name = $1`,
+		passwordHash, resetRequired)
+}
+
+// updateUser выполняет UPDATE по логину ($1) и сообщает ErrUserNotFound, если строка не найдена.
+func (ur *UserRepository) updateUser(ctx context.Context, login string, query string, args ...interface{}) error {
+	result, err := ur.db.ExecContext(ctx, query, append([]interface{}{login}, args...)...)
+	if err != nil {
+		ur.log.Error(ctx, "error updating user", map[string]interface{}{"error": err, "login": login})
+		return domain.ErrDBConnection
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
 var _ IUserRepository = (*UserRepository)(nil)
@@ -0,0 +1,19 @@
+package apikey
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+)
+
+type IAPIKeyUseCase interface {
+	// CreateAPIKey выпускает ключ с областями, не превышающими прав роли пользователя.
+	CreateAPIKey(ctx context.Context, user domain.User, data *domain.APIKeyCreationData) (*domain.CreatedAPIKey, error)
+
+	ListAPIKeys(ctx context.Context, login string) ([]domain.APIKey, error)
+
+	RevokeAPIKey(ctx context.Context, login string, id int64) error
+
+	// AuthenticateAPIKey возвращает пользователя с правами, ограниченными ключом;
+	// nil, nil для неизвестного, отозванного или истекшего ключа.
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.User, error)
+}
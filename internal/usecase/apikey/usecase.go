@@ -0,0 +1,133 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/repository/apikey"
+	"finance-backend/pkg/logger"
+	"strings"
+	"time"
+)
+
+const (
+	keySecretSize = 32
+	// keyPrefixLength — сколько первых символов ключа хранится открыто для списка ключей.
+	keyPrefixLength = len(domain.APIKeyPrefix) + 8
+)
+
+type APIKeyUseCase struct {
+	repo apikey.IAPIKeyRepository
+	log  *logger.Logger
+}
+
+func NewAPIKeyUseCase(logger *logger.Logger, repo apikey.IAPIKeyRepository) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		repo: repo,
+		log:  logger,
+	}
+}
+
+func (uc *APIKeyUseCase) CreateAPIKey(ctx context.Context, user domain.User, data *domain.APIKeyCreationData) (*domain.CreatedAPIKey, error) {
+	scopes, err := normalizeScopes(data.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		for _, perm := range scope.Permissions() {
+			if !user.Role.Can(perm) {
+				return nil, domain.ErrAPIKeyScopeNotAllowed
+			}
+		}
+	}
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrAPIKeyExpiresInPast
+	}
+
+	secret := make([]byte, keySecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := domain.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	created := &domain.CreatedAPIKey{
+		APIKey: domain.APIKey{
+			Login:     user.Login,
+			Name:      strings.TrimSpace(data.Name),
+			Prefix:    key[:keyPrefixLength],
+			Scopes:    scopes,
+			ExpiresAt: data.ExpiresAt,
+		},
+		Key: key,
+	}
+	if err := uc.repo.CreateAPIKey(ctx, &created.APIKey, hashKey(key)); err != nil {
+		return nil, err
+	}
+
+	uc.log.Info(ctx, "api key created", map[string]interface{}{"login": user.Login, "api_key_id": created.ID})
+	return created, nil
+}
+
+func (uc *APIKeyUseCase) ListAPIKeys(ctx context.Context, login string) ([]domain.APIKey, error) {
+	return uc.repo.ListAPIKeys(ctx, login)
+}
+
+func (uc *APIKeyUseCase) RevokeAPIKey(ctx context.Context, login string, id int64) error {
+	if err := uc.repo.RevokeAPIKey(ctx, login, id, time.Now()); err != nil {
+		return err
+	}
+	uc.log.Info(ctx, "api key revoked", map[string]interface{}{"login": login, "api_key_id": id})
+	return nil
+}
+
+func (uc *APIKeyUseCase) AuthenticateAPIKey(ctx context.Context, key string) (*domain.User, error) {
+	if !strings.HasPrefix(key, domain.APIKeyPrefix) {
+		return nil, nil
+	}
+
+	now := time.Now()
+	owner, err := uc.repo.GetActiveAPIKey(ctx, hashKey(key), now)
+	if err != nil || owner == nil {
+		return nil, err
+	}
+	if owner.Blocked {
+		return nil, domain.ErrUserBlocked
+	}
+	if err := uc.repo.TouchAPIKey(ctx, owner.Key.ID, now); err != nil {
+		return nil, err
+	}
+
+	user := domain.NewUser(owner.Key.Login, owner.Role)
+	user.APIKeyID = owner.Key.ID
+	user.KeyPermissions = domain.APIKeyPermissions(owner.Key.Scopes)
+	return &user, nil
+}
+
+// normalizeScopes проверяет области и убирает повторы.
+func normalizeScopes(scopes []domain.APIKeyScope) ([]domain.APIKeyScope, error) {
+	result := make([]domain.APIKeyScope, 0, len(scopes))
+	seen := make(map[domain.APIKeyScope]bool, len(scopes))
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return nil, domain.ErrAPIKeyScopeInvalid
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, domain.ErrAPIKeyScopeInvalid
+	}
+	return result, nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ IAPIKeyUseCase = (*APIKeyUseCase)(nil)
@@ -0,0 +1,31 @@
+package attachment
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"io"
+	"time"
+)
+
+type IAttachmentUseCase interface {
+	UploadAttachment(ctx context.Context, transactionID int64, owner domain.User, fileName string, body io.ReadSeeker, size int64) (*domain.Attachment, error)
+
+	CreateUploadURL(ctx context.Context, transactionID int64, owner domain.User, contentType string, size int64) (*domain.AttachmentUpload, error)
+
+	ConfirmUpload(ctx context.Context, transactionID int64, owner domain.User, key string, fileName string) (*domain.Attachment, error)
+
+	GetDownloadURL(ctx context.Context, transactionID int64, id int64, user domain.User) (*domain.PresignedURL, error)
+
+	ListAttachments(ctx context.Context, transactionID int64, user domain.User) ([]domain.Attachment, error)
+
+	DownloadAttachment(ctx context.Context, transactionID int64, id int64, user domain.User) (*domain.Attachment, io.ReadCloser, error)
+
+	DeleteAttachment(ctx context.Context, transactionID int64, id int64, user domain.User) error
+
+	ListTransactionAttachments(ctx context.Context, transactionID int64) ([]domain.Attachment, error)
+
+	DeleteAttachmentObjects(ctx context.Context, attachments []domain.Attachment)
+
+	// DeleteOrphanObjects удаляет объекты без записи о вложении, загруженные раньше before.
+	DeleteOrphanObjects(ctx context.Context, before time.Time) (int, error)
+}
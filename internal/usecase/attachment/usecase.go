@@ -0,0 +1,423 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"finance-backend/internal/domain"
+	"finance-backend/internal/gateways/file_gateway"
+	"finance-backend/internal/repository/attachment"
+	"finance-backend/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// sniffLength — сколько байт читает http.DetectContentType.
+	sniffLength = 512
+
+	// orphanBatchSize — сколько ключей DeleteOrphanObjects проверяет в БД за один запрос.
+	orphanBatchSize = 500
+)
+
+// allowedContentTypes — допустимые типы вложений и расширения, с которыми они сохраняются.
+var allowedContentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+type AttachmentUseCase struct {
+	repo        attachment.IAttachmentRepository
+	file_gw     file_gateway.IFileGateway
+	bucket_name string
+	max_size    int64
+	url_ttl     time.Duration
+	log         *logger.Logger
+}
+
+func NewAttachmentUseCase(
+	logger *logger.Logger,
+	repo attachment.IAttachmentRepository,
+	file_gw file_gateway.IFileGateway,
+	bucket_name string,
+	max_size int64,
+	url_ttl time.Duration,
+) *AttachmentUseCase {
+	return &AttachmentUseCase{
+		log:         logger,
+		repo:        repo,
+		file_gw:     file_gw,
+		bucket_name: bucket_name,
+		max_size:    max_size,
+		url_ttl:     url_ttl,
+	}
+}
+
+func (uc *AttachmentUseCase) UploadAttachment(
+	ctx context.Context,
+	transactionID int64,
+	owner domain.User,
+	fileName string,
+	body io.ReadSeeker,
+	size int64,
+) (*domain.Attachment, error) {
+	if size > uc.max_size {
+		return nil, domain.ErrAttachmentTooLarge
+	}
+
+	if err := uc.checkTransaction(ctx, owner, transactionID); err != nil {
+		return nil, err
+	}
+
+	// Тип определяем по содержимому: расширению и заголовку клиента не доверяем.
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := allowedContentTypes[contentType]
+	if !ok {
+		return nil, domain.ErrAttachmentTypeNotAllowed
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	key := objectKey(owner, transactionID, ext)
+
+	result, err := uc.file_gw.UploadObject(ctx, uc.bucket_name, key, body, size, contentType)
+	if err != nil {
+		return nil, domain.ErrS3Connection
+	}
+
+	item := &domain.Attachment{
+		TransactionID: transactionID,
+		Bucket:        uc.bucket_name,
+		ObjectKey:     key,
+		FileName:      cleanFileName(fileName, ext),
+		ContentType:   contentType,
+		Size:          result.Size,
+		ETag:          result.ETag,
+		OwnerLogin:    owner.Login,
+	}
+	if err := uc.repo.Create(ctx, item); err != nil {
+		// Без записи в БД объект никто не найдет, поэтому убираем его сразу.
+		uc.deleteObject(ctx, uc.bucket_name, key)
+		return nil, domain.ErrDBConnection
+	}
+
+	return item, nil
+}
+
+// CreateUploadURL выдает ссылку для загрузки файла напрямую в хранилище. Запись о вложении
+// не создается, пока клиент не подтвердит загрузку через ConfirmUpload.
+func (uc *AttachmentUseCase) CreateUploadURL(
+	ctx context.Context,
+	transactionID int64,
+	owner domain.User,
+	contentType string,
+	size int64,
+) (*domain.AttachmentUpload, error) {
+	if size > uc.max_size {
+		return nil, domain.ErrAttachmentTooLarge
+	}
+	ext, ok := allowedContentTypes[contentType]
+	if !ok {
+		return nil, domain.ErrAttachmentTypeNotAllowed
+	}
+
+	if err := uc.checkTransaction(ctx, owner, transactionID); err != nil {
+		return nil, err
+	}
+
+	key := objectKey(owner, transactionID, ext)
+	expiresAt := time.Now().Add(uc.url_ttl)
+	link, err := uc.file_gw.PresignPutObject(ctx, uc.bucket_name, key, contentType, size, uc.url_ttl)
+	if err != nil {
+		return nil, domain.ErrS3Connection
+	}
+
+	return &domain.AttachmentUpload{
+		ObjectKey: key,
+		PresignedURL: domain.PresignedURL{
+			URL:         link,
+			Method:      http.MethodPut,
+			ContentType: contentType,
+			ExpiresAt:   expiresAt,
+		},
+	}, nil
+}
+
+// ConfirmUpload проверяет загруженный по ссылке объект и только после этого создает вложение.
+// Объект неподходящего размера или типа удаляется из хранилища.
+func (uc *AttachmentUseCase) ConfirmUpload(
+	ctx context.Context,
+	transactionID int64,
+	owner domain.User,
+	key string,
+	fileName string,
+) (*domain.Attachment, error) {
+	prefix := objectKeyPrefix(owner, transactionID)
+	if !strings.HasPrefix(key, prefix) || strings.Contains(strings.TrimPrefix(key, prefix), "/") {
+		return nil, domain.ErrAttachmentUploadInvalid
+	}
+	ext := path.Ext(key)
+	expectedType := ""
+	for contentType, allowedExt := range allowedContentTypes {
+		if allowedExt == ext {
+			expectedType = contentType
+		}
+	}
+	if expectedType == "" {
+		return nil, domain.ErrAttachmentUploadInvalid
+	}
+
+	if err := uc.checkTransaction(ctx, owner, transactionID); err != nil {
+		return nil, err
+	}
+
+	// Повторное подтверждение той же загрузки возвращает уже созданное вложение.
+	existing, err := uc.repo.GetByObjectKey(ctx, key)
+	if err != nil {
+		return nil, domain.ErrDBConnection
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	info, err := uc.file_gw.StatObject(ctx, uc.bucket_name, key)
+	if errors.Is(err, file_gateway.ErrObjectNotFound) {
+		return nil, domain.ErrAttachmentUploadNotFound
+	}
+	if err != nil {
+		return nil, domain.ErrS3Connection
+	}
+	if info.Size > uc.max_size {
+		uc.deleteObject(ctx, uc.bucket_name, key)
+		return nil, domain.ErrAttachmentTooLarge
+	}
+
+	contentType, err := uc.sniffObject(ctx, key)
+	if err != nil {
+		return nil, domain.ErrS3Connection
+	}
+	if contentType != expectedType {
+		uc.deleteObject(ctx, uc.bucket_name, key)
+		return nil, domain.ErrAttachmentTypeNotAllowed
+	}
+
+	item := &domain.Attachment{
+		TransactionID: transactionID,
+		Bucket:        uc.bucket_name,
+		ObjectKey:     key,
+		FileName:      cleanFileName(fileName, ext),
+		ContentType:   contentType,
+		Size:          info.Size,
+		ETag:          info.ETag,
+		OwnerLogin:    owner.Login,
+	}
+	if err := uc.repo.Create(ctx, item); err != nil {
+		return nil, domain.ErrDBConnection
+	}
+
+	return item, nil
+}
+
+func (uc *AttachmentUseCase) GetDownloadURL(ctx context.Context, transactionID int64, id int64, user domain.User) (*domain.PresignedURL, error) {
+	if err := uc.checkTransaction(ctx, user, transactionID); err != nil {
+		return nil, err
+	}
+	item, err := uc.repo.GetByID(ctx, transactionID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(uc.url_ttl)
+	link, err := uc.file_gw.PresignGetObject(ctx, item.Bucket, item.ObjectKey, uc.url_ttl)
+	if err != nil {
+		return nil, domain.ErrS3Connection
+	}
+
+	return &domain.PresignedURL{
+		URL:         link,
+		Method:      http.MethodGet,
+		ContentType: item.ContentType,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (uc *AttachmentUseCase) ListAttachments(ctx context.Context, transactionID int64, user domain.User) ([]domain.Attachment, error) {
+	if err := uc.checkTransaction(ctx, user, transactionID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListByTransaction(ctx, transactionID)
+}
+
+func (uc *AttachmentUseCase) DownloadAttachment(ctx context.Context, transactionID int64, id int64, user domain.User) (*domain.Attachment, io.ReadCloser, error) {
+	if err := uc.checkTransaction(ctx, user, transactionID); err != nil {
+		return nil, nil, err
+	}
+	item, err := uc.repo.GetByID(ctx, transactionID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, _, err := uc.file_gw.GetObject(ctx, item.Bucket, item.ObjectKey)
+	if err != nil {
+		return nil, nil, domain.ErrS3Connection
+	}
+
+	return item, body, nil
+}
+
+func (uc *AttachmentUseCase) DeleteAttachment(ctx context.Context, transactionID int64, id int64, user domain.User) error {
+	if err := uc.checkTransaction(ctx, user, transactionID); err != nil {
+		return err
+	}
+	item, err := uc.repo.GetByID(ctx, transactionID, id)
+	if err != nil {
+		return err
+	}
+	// Чужие вложения удаляют администратор сервиса и владельцы или администраторы организации.
+	if item.OwnerLogin != user.Login && !user.IsAdmin && !(user.OrgID != 0 && user.OrgRole.CanManageMembers()) {
+		return domain.ErrForbidden
+	}
+
+	if err := uc.file_gw.DeleteObject(ctx, item.Bucket, item.ObjectKey); err != nil {
+		return domain.ErrS3Connection
+	}
+
+	return uc.repo.Delete(ctx, id)
+}
+
+// ListTransactionAttachments отдает вложения транзакции без проверки ее существования —
+// используется при удалении транзакции.
+func (uc *AttachmentUseCase) ListTransactionAttachments(ctx context.Context, transactionID int64) ([]domain.Attachment, error) {
+	return uc.repo.ListByTransaction(ctx, transactionID)
+}
+
+// DeleteAttachmentObjects удаляет файлы из хранилища. Записи в БД к этому моменту уже
+// удалены каскадом, поэтому ошибки только логируются.
+func (uc *AttachmentUseCase) DeleteAttachmentObjects(ctx context.Context, attachments []domain.Attachment) {
+	for _, item := range attachments {
+		uc.deleteObject(ctx, item.Bucket, item.ObjectKey)
+	}
+}
+
+// DeleteOrphanObjects удаляет объекты вложений, загруженные раньше before, для которых нет
+// записи о вложении: неподтвержденные прямые загрузки и файлы, которые не удалось удалить
+// вместе с транзакцией. Возвращает число удаленных объектов.
+func (uc *AttachmentUseCase) DeleteOrphanObjects(ctx context.Context, before time.Time) (int, error) {
+	objects, err := uc.file_gw.ListObjects(ctx, uc.bucket_name, objectKeysRoot)
+	if err != nil {
+		return 0, err
+	}
+
+	var stale []string
+	for _, object := range objects {
+		if object.LastModified.Before(before) {
+			stale = append(stale, object.Key)
+		}
+	}
+
+	deleted := 0
+	for start := 0; start < len(stale); start += orphanBatchSize {
+		batch := stale[start:min(start+orphanBatchSize, len(stale))]
+		known, err := uc.repo.FindObjectKeys(ctx, batch)
+		if err != nil {
+			return deleted, err
+		}
+		attached := make(map[string]bool, len(known))
+		for _, key := range known {
+			attached[key] = true
+		}
+
+		for _, key := range batch {
+			if attached[key] {
+				continue
+			}
+			if err := uc.file_gw.DeleteObject(ctx, uc.bucket_name, key); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// checkTransaction проверяет, что транзакция есть в области данных пользователя.
+func (uc *AttachmentUseCase) checkTransaction(ctx context.Context, user domain.User, transactionID int64) error {
+	exists, err := uc.repo.TransactionExists(ctx, domain.ScopeOf(user), transactionID)
+	if err != nil {
+		return domain.ErrDBConnection
+	}
+	if !exists {
+		return domain.ErrTransactionNotFound
+	}
+	return nil
+}
+
+// sniffObject определяет тип загруженного объекта по его первым байтам.
+func (uc *AttachmentUseCase) sniffObject(ctx context.Context, key string) (string, error) {
+	body, _, err := uc.file_gw.GetObject(ctx, uc.bucket_name, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+func (uc *AttachmentUseCase) deleteObject(ctx context.Context, bucket, key string) {
+	if err := uc.file_gw.DeleteObject(ctx, bucket, key); err != nil {
+		uc.log.Warn(ctx, "failed to delete attachment object", map[string]interface{}{
+			"error":  err.Error(),
+			"bucket": bucket,
+			"key":    key,
+		})
+	}
+}
+
+// objectKeysRoot — общий префикс ключей вложений.
+const objectKeysRoot = "users/"
+
+func objectKeyPrefix(owner domain.User, transactionID int64) string {
+	return fmt.Sprintf("%s%s/transactions/%d/", objectKeysRoot, url.PathEscape(owner.Login), transactionID)
+}
+
+func objectKey(owner domain.User, transactionID int64, ext string) string {
+	return objectKeyPrefix(owner, transactionID) + uuid.New().String() + ext
+}
+
+// cleanFileName оставляет от имени файла клиента только базовое имя, чтобы его можно
+// было безопасно подставить в Content-Disposition.
+func cleanFileName(fileName, ext string) string {
+	name := filepath.Base(strings.ReplaceAll(fileName, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		name = "attachment" + ext
+	}
+	return name
+}
+
+var _ IAttachmentUseCase = (*AttachmentUseCase)(nil)
@@ -0,0 +1,82 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"finance-backend/internal/gateways/file_gateway"
+	"finance-backend/internal/repository/attachment"
+	"finance-backend/pkg/logger"
+)
+
+// knownKeysRepo — репозиторий, в котором есть вложения только с ключами known.
+type knownKeysRepo struct {
+	attachment.IAttachmentRepository
+	known map[string]bool
+}
+
+func (r *knownKeysRepo) FindObjectKeys(_ context.Context, keys []string) ([]string, error) {
+	found := []string{}
+	for _, key := range keys {
+		if r.known[key] {
+			found = append(found, key)
+		}
+	}
+	return found, nil
+}
+
+func TestDeleteOrphanObjects(t *testing.T) {
+	root := t.TempDir()
+	gw, err := file_gateway.NewFSGateway(root, "http://files.test", []byte("key"), logger.NewLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	old := time.Now().Add(-48 * time.Hour)
+
+	objects := []struct {
+		key      string
+		attached bool
+		modified time.Time
+	}{
+		{"users/a/transactions/1/attached.pdf", true, old},
+		{"users/a/transactions/1/orphan.pdf", false, old},
+		{"users/a/transactions/2/fresh.pdf", false, time.Now()},
+	}
+	known := map[string]bool{}
+	for _, object := range objects {
+		content := []byte("%PDF-1.4")
+		if _, err := gw.UploadObject(ctx, "attachments", object.key, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filepath.Join(root, "attachments", filepath.FromSlash(object.key)), object.modified, object.modified); err != nil {
+			t.Fatal(err)
+		}
+		known[object.key] = object.attached
+	}
+
+	uc := NewAttachmentUseCase(logger.NewLogger(), &knownKeysRepo{known: known}, gw, "attachments", 1<<20, time.Minute)
+	deleted, err := uc.DeleteOrphanObjects(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("deleted %d objects, want 1", deleted)
+	}
+
+	for _, object := range objects {
+		_, err := gw.StatObject(ctx, "attachments", object.key)
+		orphan := !object.attached && object.modified.Equal(old)
+		if orphan && !errors.Is(err, file_gateway.ErrObjectNotFound) {
+			t.Errorf("orphan %s is still stored: %v", object.key, err)
+		}
+		if !orphan && err != nil {
+			t.Errorf("%s was deleted: %v", object.key, err)
+		}
+	}
+}
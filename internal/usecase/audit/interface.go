@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+)
+
+type IAuditUseCase interface {
+	domain.AuditRecorder
+
+	SearchEntries(ctx context.Context, limit int, offset int, filter domain.AuditFilter) (utils.PaginatedEntities[domain.AuditEntry], error)
+}
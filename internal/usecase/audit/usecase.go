@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/repository/audit"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+)
+
+type AuditUseCase struct {
+	repo audit.IAuditRepository
+	log  *logger.Logger
+}
+
+func NewAuditUseCase(logger *logger.Logger, repo audit.IAuditRepository) *AuditUseCase {
+	return &AuditUseCase{
+		repo: repo,
+		log:  logger,
+	}
+}
+
+// Record дополняет событие сведениями о запросе и сохраняет его. Изменение к этому моменту
+// уже выполнено, поэтому ошибка записи его не отменяет, а только пишется в лог.
+func (uc *AuditUseCase) Record(ctx context.Context, event domain.AuditEvent) {
+	entry := &domain.AuditEntry{
+		Actor:      event.Actor,
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+	}
+	orgID := event.OrgID
+	if user, ok := utils.GetUserFromContext(ctx); ok {
+		if entry.Actor == "" {
+			entry.Actor = user.Login
+		}
+		if orgID == 0 {
+			orgID = user.OrgID
+		}
+		if user.ViaAPIKey() {
+			entry.APIKeyID = &user.APIKeyID
+		}
+	}
+	if orgID != 0 {
+		entry.OrgID = &orgID
+	}
+	entry.RequestID, _ = utils.GetRequestIDFromContext(ctx)
+	entry.IP, _ = utils.GetClientIPFromContext(ctx)
+
+	var err error
+	if entry.Before, err = marshalState(event.Before); err == nil {
+		entry.After, err = marshalState(event.After)
+	}
+	if err == nil {
+		err = uc.repo.Create(ctx, entry)
+	}
+	if err != nil {
+		uc.log.Error(ctx, "failed to write audit log", map[string]interface{}{
+			"error":  err.Error(),
+			"action": event.Action,
+			"entity": event.EntityType,
+			"id":     event.EntityID,
+			"actor":  entry.Actor,
+		})
+	}
+}
+
+func (uc *AuditUseCase) SearchEntries(ctx context.Context, limit int, offset int, filter domain.AuditFilter) (utils.PaginatedEntities[domain.AuditEntry], error) {
+	return uc.repo.SearchPaginated(ctx, limit, offset, filter)
+}
+
+func marshalState(state interface{}) (string, error) {
+	if state == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+var _ IAuditUseCase = (*AuditUseCase)(nil)
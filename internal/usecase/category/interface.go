@@ -6,16 +6,29 @@ import (
 	"finance-backend/pkg/utils"
 )
 
+// ICategoryUseCase — собственные категории организации или пользователя. Категории общего
+// справочника видны всем, но изменять их нельзя (domain.ErrForbidden).
 type ICategoryUseCase interface {
-	GetCategoryByID(ctx context.Context, id int64) (*domain.Category, error)
+	GetCategoryByID(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error)
 
-	SearchCategoriesPaginated(ctx context.Context, limit int, offset int, search *string) (utils.PaginatedEntities[domain.Category], error)
+	SearchCategoriesPaginated(ctx context.Context, scope domain.DataScope, limit int, offset int, search *string, withArchived bool) (utils.PaginatedEntities[domain.Category], error)
 
-	SearchCategoriesFlat(ctx context.Context, search *string) ([]domain.Category, error)
+	// CreateCategory создает категорию области scope; родитель, если задан, должен быть
+	// действующей категорией того же типа.
+	CreateCategory(ctx context.Context, scope domain.DataScope, data domain.CategoryData) (*domain.Category, error)
 
-	CreateCategory(ctx context.Context, name string) (*domain.Category, error)
+	UpdateCategoryName(ctx context.Context, scope domain.DataScope, categoryID int64, categoryName string) (*domain.Category, error)
 
-	UpdateCategoryName(ctx context.Context, categoryID int64, categoryName string) (*domain.Category, error)
+	// ArchiveCategory скрывает категорию из выбора для новых операций; операции остаются в ней.
+	ArchiveCategory(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error)
 
-	DeleteCategory(ctx context.Context, id int64) error
+	UnarchiveCategory(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error)
+
+	// MergeCategory переносит операции категории sourceID в targetID и архивирует sourceID.
+	// Возвращает категорию targetID и число перенесенных транзакций.
+	MergeCategory(ctx context.Context, scope domain.DataScope, sourceID int64, targetID int64) (*domain.Category, int64, error)
+
+	// DeleteCategory удаляет категорию, которая ни разу не использовалась, иначе
+	// domain.ErrCategoryInUse.
+	DeleteCategory(ctx context.Context, scope domain.DataScope, id int64) error
 }
@@ -2,6 +2,9 @@ package category
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 
 	"finance-backend/internal/domain"
 	"finance-backend/internal/repository/category"
@@ -10,71 +13,237 @@ import (
 )
 
 type CategoryUseCase struct {
-	repo category.ICategoryRepository
-	log  *logger.Logger
+	repo  category.ICategoryRepository
+	audit domain.AuditRecorder
+	log   *logger.Logger
 }
 
-func NewCategoryUseCase(logger *logger.Logger, repo category.ICategoryRepository) *CategoryUseCase {
+func NewCategoryUseCase(logger *logger.Logger, repo category.ICategoryRepository, audit domain.AuditRecorder) *CategoryUseCase {
 	return &CategoryUseCase{
-		log:  logger,
-		repo: repo,
+		log:   logger,
+		repo:  repo,
+		audit: audit,
 	}
 }
 
-func (uc *CategoryUseCase) GetCategoryByID(ctx context.Context, id int64) (*domain.Category, error) {
-	return uc.repo.GetByID(ctx, id)
+func (uc *CategoryUseCase) GetCategoryByID(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error) {
+	return uc.repo.GetByID(ctx, scope, id)
 }
 
-func (uc *CategoryUseCase) SearchCategoriesPaginated(ctx context.Context, limit int, offset int, search *string) (utils.PaginatedEntities[domain.Category], error) {
-	return uc.repo.SearchPaginated(ctx, limit, offset, search)
+func (uc *CategoryUseCase) SearchCategoriesPaginated(ctx context.Context, scope domain.DataScope, limit int, offset int, search *string, withArchived bool) (utils.PaginatedEntities[domain.Category], error) {
+	return uc.repo.SearchPaginated(ctx, scope, limit, offset, search, withArchived)
 }
 
-func (uc *CategoryUseCase) SearchCategoriesFlat(ctx context.Context, search *string) ([]domain.Category, error) {
-	return uc.repo.SearchFlat(ctx, search)
+func (uc *CategoryUseCase) CreateCategory(ctx context.Context, scope domain.DataScope, data domain.CategoryData) (*domain.Category, error) {
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Type != domain.CategoryTypeCredit && data.Type != domain.CategoryTypeDebit {
+		return nil, domain.ErrCategoryTypeInvalid
+	}
+
+	if data.ParentID != nil {
+		parent, err := uc.repo.GetByID(ctx, scope, *data.ParentID)
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			return nil, domain.ErrCategoryParentInvalid
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parent.IsArchived() || parent.Type != data.Type {
+			return nil, domain.ErrCategoryParentInvalid
+		}
+	}
+
+	if err := uc.checkNameFree(ctx, scope, data.Name, data.Type, 0); err != nil {
+		return nil, err
+	}
+
+	category, err := uc.repo.Create(ctx, scope, &data)
+	if err != nil {
+		return nil, err
+	}
+	uc.record(ctx, scope, domain.AuditActionCreate, category.ID, nil, category)
+	return category, nil
 }
 
-func (uc *CategoryUseCase) CreateCategory(ctx context.Context, name string) (*domain.Category, error) {
-	existingCategory, _ := uc.repo.GetByName(ctx, name)
+func (uc *CategoryUseCase) UpdateCategoryName(ctx context.Context, scope domain.DataScope, categoryID int64, categoryName string) (*domain.Category, error) {
+	category, err := uc.editable(ctx, scope, categoryID)
+	if err != nil {
+		return nil, err
+	}
 
-	if existingCategory != nil {
-		return nil, domain.ErrCategoryExists
+	categoryName = strings.TrimSpace(categoryName)
+	if category.Name == categoryName {
+		return category, nil
 	}
 
-	return uc.repo.Create(ctx, name)
+	if err := uc.checkNameFree(ctx, scope, categoryName, category.Type, category.ID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.UpdateName(ctx, categoryID, categoryName); err != nil {
+		return nil, err
+	}
+	return uc.reload(ctx, scope, domain.AuditActionUpdate, category)
 }
 
-func (uc *CategoryUseCase) UpdateCategoryName(ctx context.Context, categoryID int64, categoryName string) (*domain.Category, error) {
-	category, err := uc.repo.GetByID(ctx, categoryID)
+func (uc *CategoryUseCase) ArchiveCategory(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error) {
+	category, err := uc.editable(ctx, scope, id)
 	if err != nil {
 		return nil, err
 	}
-	if category == nil {
-		return nil, domain.ErrCategoryNotFound
+	if category.IsArchived() {
+		return category, nil
 	}
 
-	if category.Name == categoryName {
+	if err := uc.repo.SetArchived(ctx, id, true); err != nil {
+		return nil, err
+	}
+	return uc.reload(ctx, scope, domain.AuditActionArchive, category)
+}
+
+func (uc *CategoryUseCase) UnarchiveCategory(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error) {
+	category, err := uc.editable(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if !category.IsArchived() {
 		return category, nil
 	}
 
-	existingCategory, _ := uc.repo.GetByName(ctx, categoryName)
+	// Пока категория была в архиве, ее название могли занять.
+	if err := uc.checkNameFree(ctx, scope, category.Name, category.Type, category.ID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.SetArchived(ctx, id, false); err != nil {
+		return nil, err
+	}
+	return uc.reload(ctx, scope, domain.AuditActionUnarchive, category)
+}
+
+func (uc *CategoryUseCase) MergeCategory(ctx context.Context, scope domain.DataScope, sourceID int64, targetID int64) (*domain.Category, int64, error) {
+	source, err := uc.editable(ctx, scope, sourceID)
+	if err != nil {
+		return nil, 0, err
+	}
+	target, err := uc.repo.GetByID(ctx, scope, targetID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if target.ID == source.ID || target.IsArchived() || target.Type != source.Type {
+		return nil, 0, domain.ErrCategoryMergeInvalid
+	}
+	// Подкатегории переходят к целевой категории, поэтому она не может быть среди них.
+	descendant, err := uc.repo.IsDescendant(ctx, target.ID, source.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if descendant {
+		return nil, 0, domain.ErrCategoryMergeInvalid
+	}
+
+	moved, err := uc.repo.Merge(ctx, source.ID, target.ID)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	if existingCategory != nil {
-		return nil, domain.ErrCategoryExists
+	after := categorySnapshot(source)
+	after["archived"] = true
+	after["merged_into"] = target.ID
+	after["transactions_moved"] = moved
+	uc.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionMerge,
+		EntityType: domain.AuditEntityCategory,
+		EntityID:   strconv.FormatInt(source.ID, 10),
+		OrgID:      scope.OrgID,
+		Before:     categorySnapshot(source),
+		After:      after,
+	})
+	return target, moved, nil
+}
+
+func (uc *CategoryUseCase) DeleteCategory(ctx context.Context, scope domain.DataScope, id int64) error {
+	category, err := uc.editable(ctx, scope, id)
+	if err != nil {
+		return err
 	}
 
-	uc.repo.UpdateName(ctx, categoryID, categoryName)
+	used, err := uc.repo.IsInUse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return domain.ErrCategoryInUse
+	}
+
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	uc.record(ctx, scope, domain.AuditActionDelete, id, category, nil)
+	return nil
+}
 
-	return uc.repo.GetByID(ctx, categoryID)
+// editable возвращает категорию области scope, которую можно менять: категории общего
+// справочника доступны только для чтения.
+func (uc *CategoryUseCase) editable(ctx context.Context, scope domain.DataScope, id int64) (*domain.Category, error) {
+	category, err := uc.repo.GetByID(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if category.IsShared() {
+		return nil, domain.ErrForbidden
+	}
+	return category, nil
 }
 
-func (uc *CategoryUseCase) DeleteCategory(ctx context.Context, id int64) error {
-	category, err := uc.repo.GetByID(ctx, id)
+// checkNameFree проверяет, что среди действующих категорий области нет категории того же
+// типа с таким названием.
+func (uc *CategoryUseCase) checkNameFree(ctx context.Context, scope domain.DataScope, name string, categoryType string, excludeID int64) error {
+	exists, err := uc.repo.ExistsByName(ctx, scope, name, categoryType, excludeID)
 	if err != nil {
 		return err
 	}
-	if category == nil {
-		return domain.ErrCategoryNotFound
+	if exists {
+		return domain.ErrCategoryExists
 	}
+	return nil
+}
 
-	return uc.repo.Delete(ctx, id)
+// reload перечитывает измененную категорию и пишет изменение в журнал аудита.
+func (uc *CategoryUseCase) reload(ctx context.Context, scope domain.DataScope, action domain.AuditAction, before *domain.Category) (*domain.Category, error) {
+	updated, err := uc.repo.GetByID(ctx, scope, before.ID)
+	if err != nil {
+		return nil, err
+	}
+	uc.record(ctx, scope, action, before.ID, before, updated)
+	return updated, nil
 }
+
+// record пишет изменение категории в журнал аудита; nil — состояния нет.
+func (uc *CategoryUseCase) record(ctx context.Context, scope domain.DataScope, action domain.AuditAction, id int64, before, after *domain.Category) {
+	event := domain.AuditEvent{
+		Action:     action,
+		EntityType: domain.AuditEntityCategory,
+		EntityID:   strconv.FormatInt(id, 10),
+		OrgID:      scope.OrgID,
+	}
+	if before != nil {
+		event.Before = categorySnapshot(before)
+	}
+	if after != nil {
+		event.After = categorySnapshot(after)
+	}
+	uc.audit.Record(ctx, event)
+}
+
+func categorySnapshot(c *domain.Category) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        c.ID,
+		"name":      c.Name,
+		"type":      c.Type,
+		"parent_id": c.ParentID,
+		"archived":  c.IsArchived(),
+	}
+}
+
+var _ ICategoryUseCase = (*CategoryUseCase)(nil)
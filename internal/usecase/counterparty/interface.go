@@ -0,0 +1,23 @@
+package counterparty
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+)
+
+// ICounterpartyUseCase — справочник контрагентов активной организации или личного учета.
+// Контрагенты другой области считаются несуществующими.
+type ICounterpartyUseCase interface {
+	GetCounterpartyByID(ctx context.Context, scope domain.DataScope, id int64) (*domain.Counterparty, error)
+
+	SearchCounterpartiesPaginated(ctx context.Context, scope domain.DataScope, limit int, offset int, search *string) (utils.PaginatedEntities[domain.Counterparty], error)
+
+	// CreateCounterparty создает контрагента области. Категория по умолчанию должна быть
+	// доступна в области и не в архиве.
+	CreateCounterparty(ctx context.Context, scope domain.DataScope, data *domain.CounterpartyData) (*domain.Counterparty, error)
+
+	UpdateCounterparty(ctx context.Context, scope domain.DataScope, id int64, data *domain.CounterpartyData) (*domain.Counterparty, error)
+
+	DeleteCounterparty(ctx context.Context, scope domain.DataScope, id int64) error
+}
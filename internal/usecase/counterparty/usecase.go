@@ -0,0 +1,126 @@
+package counterparty
+
+import (
+	"context"
+	"errors"
+
+	"finance-backend/internal/domain"
+	"finance-backend/internal/repository/category"
+	"finance-backend/internal/repository/counterparty"
+	"finance-backend/pkg/logger"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
+)
+
+type CounterpartyUseCase struct {
+	repo       counterparty.ICounterpartyRepository
+	categories category.ICategoryRepository
+	log        *logger.Logger
+}
+
+func NewCounterpartyUseCase(logger *logger.Logger, repo counterparty.ICounterpartyRepository, categories category.ICategoryRepository) *CounterpartyUseCase {
+	return &CounterpartyUseCase{
+		log:        logger,
+		repo:       repo,
+		categories: categories,
+	}
+}
+
+func (uc *CounterpartyUseCase) GetCounterpartyByID(ctx context.Context, scope domain.DataScope, id int64) (*domain.Counterparty, error) {
+	return uc.repo.GetByID(ctx, scope, id)
+}
+
+func (uc *CounterpartyUseCase) SearchCounterpartiesPaginated(ctx context.Context, scope domain.DataScope, limit int, offset int, search *string) (utils.PaginatedEntities[domain.Counterparty], error) {
+	return uc.repo.SearchPaginated(ctx, scope, limit, offset, search)
+}
+
+func (uc *CounterpartyUseCase) CreateCounterparty(ctx context.Context, scope domain.DataScope, data *domain.CounterpartyData) (*domain.Counterparty, error) {
+	normalizePhone(data)
+
+	if err := uc.checkDefaultCategory(ctx, scope, data.DefaultCategoryID); err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.repo.FindByRequisites(ctx, scope, valueOrEmpty(data.INN), valueOrEmpty(data.Phone))
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.ErrCounterpartyExists
+	}
+
+	return uc.repo.Create(ctx, scope, data)
+}
+
+func (uc *CounterpartyUseCase) UpdateCounterparty(ctx context.Context, scope domain.DataScope, id int64, data *domain.CounterpartyData) (*domain.Counterparty, error) {
+	current, err := uc.repo.GetByID(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizePhone(data)
+
+	// Уже выбранную категорию можно оставить, даже если ее перенесли в архив.
+	if !sameCategory(current.DefaultCategoryID, data.DefaultCategoryID) {
+		if err := uc.checkDefaultCategory(ctx, scope, data.DefaultCategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.repo.Update(ctx, id, data); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetByID(ctx, scope, id)
+}
+
+func (uc *CounterpartyUseCase) DeleteCounterparty(ctx context.Context, scope domain.DataScope, id int64) error {
+	if _, err := uc.repo.GetByID(ctx, scope, id); err != nil {
+		return err
+	}
+
+	return uc.repo.Delete(ctx, id)
+}
+
+// checkDefaultCategory проверяет, что категория по умолчанию видна в области scope
+// и не в архиве.
+func (uc *CounterpartyUseCase) checkDefaultCategory(ctx context.Context, scope domain.DataScope, categoryID *int64) error {
+	if categoryID == nil {
+		return nil
+	}
+
+	defaultCategory, err := uc.categories.GetByID(ctx, scope, *categoryID)
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		return domain.ErrCounterpartyCategoryInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if defaultCategory.IsArchived() {
+		return domain.ErrCounterpartyCategoryInvalid
+	}
+	return nil
+}
+
+func sameCategory(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func normalizePhone(data *domain.CounterpartyData) {
+	if data.Phone != nil {
+		phone := validation.NormalizePhoneOrKeep(*data.Phone)
+		data.Phone = &phone
+	}
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+var _ ICounterpartyUseCase = (*CounterpartyUseCase)(nil)
@@ -0,0 +1,31 @@
+package organization
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+)
+
+// IOrganizationUseCase — организации пользователя, их участники и приглашения.
+// Организация, в которой пользователь не состоит, для него не существует:
+// все операции с ней возвращают domain.ErrOrganizationNotFound.
+type IOrganizationUseCase interface {
+	// CreateOrganization создает организацию, создатель становится ее владельцем.
+	CreateOrganization(ctx context.Context, user domain.User, data *domain.OrganizationData) (*domain.Organization, error)
+	ListOrganizations(ctx context.Context, user domain.User) ([]domain.Organization, error)
+	GetOrganization(ctx context.Context, user domain.User, id int64) (*domain.Organization, error)
+	UpdateOrganization(ctx context.Context, user domain.User, id int64, data *domain.OrganizationData) (*domain.Organization, error)
+
+	ListMembers(ctx context.Context, user domain.User, orgID int64) ([]domain.OrgMember, error)
+	// ChangeMemberRole меняет роль участника. Назначать и снимать владельцев может только владелец.
+	ChangeMemberRole(ctx context.Context, user domain.User, orgID int64, login string, role domain.OrgRole) error
+	// RemoveMember исключает участника; любой участник может выйти из организации сам.
+	RemoveMember(ctx context.Context, user domain.User, orgID int64, login string) error
+
+	// InviteMember отправляет приглашение на email.
+	InviteMember(ctx context.Context, user domain.User, orgID int64, data *domain.OrgInvitationData) (*domain.OrgInvitation, error)
+	ListInvitations(ctx context.Context, user domain.User, orgID int64) ([]domain.OrgInvitation, error)
+	RevokeInvitation(ctx context.Context, user domain.User, orgID int64, id int64) error
+	// AcceptInvitation добавляет пользователя в организацию по токену из письма.
+	// Приглашение принимает только пользователь с тем email, на который оно отправлено.
+	AcceptInvitation(ctx context.Context, user domain.User, token string) (*domain.Organization, error)
+}
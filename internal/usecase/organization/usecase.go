@@ -0,0 +1,283 @@
+package organization
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"finance-backend/internal/domain"
+	"finance-backend/internal/gateways/mail_gateway"
+	"finance-backend/internal/repository/organization"
+	userRepo "finance-backend/internal/repository/user"
+	"finance-backend/pkg/logger"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Settings — параметры приглашений в организации.
+type Settings struct {
+	InvitationTTL time.Duration
+	InvitationURL string // страница фронтенда, к ней добавляется параметр token
+}
+
+type OrganizationUseCase struct {
+	repo     organization.IOrganizationRepository
+	users    userRepo.IUserRepository
+	mailer   mail_gateway.IMailGateway
+	settings Settings
+	log      *logger.Logger
+}
+
+func NewOrganizationUseCase(
+	logger *logger.Logger,
+	repo organization.IOrganizationRepository,
+	users userRepo.IUserRepository,
+	mailer mail_gateway.IMailGateway,
+	settings Settings,
+) *OrganizationUseCase {
+	return &OrganizationUseCase{
+		repo:     repo,
+		users:    users,
+		mailer:   mailer,
+		settings: settings,
+		log:      logger,
+	}
+}
+
+func (uc *OrganizationUseCase) CreateOrganization(ctx context.Context, user domain.User, data *domain.OrganizationData) (*domain.Organization, error) {
+	return uc.repo.CreateOrganization(ctx, normalizeData(data), user.Login)
+}
+
+func (uc *OrganizationUseCase) ListOrganizations(ctx context.Context, user domain.User) ([]domain.Organization, error) {
+	return uc.repo.ListUserOrganizations(ctx, user.Login)
+}
+
+func (uc *OrganizationUseCase) GetOrganization(ctx context.Context, user domain.User, id int64) (*domain.Organization, error) {
+	org, err := uc.repo.GetOrganization(ctx, id, user.Login)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+func (uc *OrganizationUseCase) UpdateOrganization(ctx context.Context, user domain.User, id int64, data *domain.OrganizationData) (*domain.Organization, error) {
+	if _, err := uc.requireManager(ctx, user, id); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateOrganization(ctx, id, normalizeData(data)); err != nil {
+		return nil, err
+	}
+	return uc.GetOrganization(ctx, user, id)
+}
+
+func (uc *OrganizationUseCase) ListMembers(ctx context.Context, user domain.User, orgID int64) ([]domain.OrgMember, error) {
+	if _, err := uc.GetOrganization(ctx, user, orgID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListMembers(ctx, orgID)
+}
+
+func (uc *OrganizationUseCase) ChangeMemberRole(ctx context.Context, user domain.User, orgID int64, login string, role domain.OrgRole) error {
+	if !role.IsValid() {
+		return domain.ErrOrgRoleInvalid
+	}
+	org, err := uc.requireManager(ctx, user, orgID)
+	if err != nil {
+		return err
+	}
+
+	current, err := uc.repo.GetMemberRole(ctx, orgID, login)
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		return domain.ErrOrgMemberNotFound
+	}
+	if (current == domain.OrgRoleOwner || role == domain.OrgRoleOwner) && org.Role != domain.OrgRoleOwner {
+		return domain.ErrForbidden
+	}
+
+	// Новая роль попадет в токен участника при следующем обновлении.
+	return uc.repo.UpdateMemberRole(ctx, orgID, login, role)
+}
+
+func (uc *OrganizationUseCase) RemoveMember(ctx context.Context, user domain.User, orgID int64, login string) error {
+	if login == user.Login {
+		if _, err := uc.GetOrganization(ctx, user, orgID); err != nil {
+			return err
+		}
+		return uc.repo.RemoveMember(ctx, orgID, login)
+	}
+
+	org, err := uc.requireManager(ctx, user, orgID)
+	if err != nil {
+		return err
+	}
+	current, err := uc.repo.GetMemberRole(ctx, orgID, login)
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		return domain.ErrOrgMemberNotFound
+	}
+	if current == domain.OrgRoleOwner && org.Role != domain.OrgRoleOwner {
+		return domain.ErrForbidden
+	}
+	return uc.repo.RemoveMember(ctx, orgID, login)
+}
+
+func (uc *OrganizationUseCase) InviteMember(ctx context.Context, user domain.User, orgID int64, data *domain.OrgInvitationData) (*domain.OrgInvitation, error) {
+	if !data.Role.IsValid() {
+		return nil, domain.ErrOrgRoleInvalid
+	}
+	org, err := uc.requireManager(ctx, user, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if data.Role == domain.OrgRoleOwner && org.Role != domain.OrgRoleOwner {
+		return nil, domain.ErrForbidden
+	}
+
+	email := domain.NormalizeEmail(data.Email)
+	invitee, err := uc.users.GetRawUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if invitee != nil {
+		role, err := uc.repo.GetMemberRole(ctx, orgID, invitee.Login)
+		if err != nil {
+			return nil, err
+		}
+		if role != "" {
+			return nil, domain.ErrOrgAlreadyMember
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &domain.OrgInvitation{
+		OrgID:     orgID,
+		OrgName:   org.Name,
+		Email:     email,
+		Role:      data.Role,
+		InvitedBy: user.Login,
+		ExpiresAt: time.Now().Add(uc.settings.InvitationTTL),
+	}
+	if err := uc.repo.CreateInvitation(ctx, invitation, hashToken(token)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.sendInvitation(ctx, invitation, token); err != nil {
+		// Неотправленное приглашение никто не примет — удаляем его, чтобы не висело в списке.
+		if deleteErr := uc.repo.DeleteInvitation(ctx, orgID, invitation.ID); deleteErr != nil {
+			uc.log.Error(ctx, "failed to delete unsent invitation", map[string]interface{}{"error": deleteErr.Error(), "invitation_id": invitation.ID})
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (uc *OrganizationUseCase) ListInvitations(ctx context.Context, user domain.User, orgID int64) ([]domain.OrgInvitation, error) {
+	if _, err := uc.requireManager(ctx, user, orgID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListInvitations(ctx, orgID)
+}
+
+func (uc *OrganizationUseCase) RevokeInvitation(ctx context.Context, user domain.User, orgID int64, id int64) error {
+	if _, err := uc.requireManager(ctx, user, orgID); err != nil {
+		return err
+	}
+	return uc.repo.DeleteInvitation(ctx, orgID, id)
+}
+
+func (uc *OrganizationUseCase) AcceptInvitation(ctx context.Context, user domain.User, token string) (*domain.Organization, error) {
+	invitation, err := uc.repo.GetActiveInvitation(ctx, hashToken(token), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, domain.ErrOrgInvitationInvalid
+	}
+
+	rawUser, err := uc.users.GetRawUserByLogin(ctx, user.Login)
+	if err != nil {
+		return nil, err
+	}
+	// Ссылку могли переслать: принять приглашение может только владелец адреса.
+	if rawUser == nil || domain.NormalizeEmail(rawUser.Email) != invitation.Email {
+		return nil, domain.ErrOrgInvitationEmailMismatch
+	}
+
+	if err := uc.repo.AcceptInvitation(ctx, invitation, user.Login); err != nil {
+		return nil, err
+	}
+	return uc.GetOrganization(ctx, user, invitation.OrgID)
+}
+
+// requireManager возвращает организацию, если пользователь может управлять ее участниками.
+func (uc *OrganizationUseCase) requireManager(ctx context.Context, user domain.User, orgID int64) (*domain.Organization, error) {
+	org, err := uc.GetOrganization(ctx, user, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.Role.CanManageMembers() {
+		return nil, domain.ErrForbidden
+	}
+	return org, nil
+}
+
+func (uc *OrganizationUseCase) sendInvitation(ctx context.Context, invitation *domain.OrgInvitation, token string) error {
+	link, err := url.Parse(uc.settings.InvitationURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return uc.mailer.Send(ctx, mail_gateway.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("Приглашение в организацию «%s»", invitation.OrgName),
+		Body: fmt.Sprintf(
+			"Здравствуйте!\n\n"+
+				"%s приглашает вас вести учет организации «%s».\n"+
+				"Чтобы принять приглашение, войдите в сервис с этим адресом почты и перейдите по ссылке:\n%s\n\n"+
+				"Ссылка действует до %s и может быть использована один раз.\n"+
+				"Если вы не ждали приглашения, просто проигнорируйте это письмо.\n",
+			invitation.InvitedBy, invitation.OrgName, link.String(), invitation.ExpiresAt.Format("02.01.2006 15:04 MST"),
+		),
+	})
+}
+
+func normalizeData(data *domain.OrganizationData) *domain.OrganizationData {
+	normalized := &domain.OrganizationData{Name: strings.TrimSpace(data.Name)}
+	if data.INN != nil && strings.TrimSpace(*data.INN) != "" {
+		inn := strings.TrimSpace(*data.INN)
+		normalized.INN = &inn
+	}
+	return normalized
+}
+
+func generateToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ IOrganizationUseCase = (*OrganizationUseCase)(nil)
@@ -0,0 +1,21 @@
+package period
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"time"
+)
+
+// IPeriodUseCase — закрытие учетных периодов. Пока период закрыт, транзакции с датой в нем
+// нельзя добавлять, изменять и удалять (domain.ErrPeriodClosed).
+type IPeriodUseCase interface {
+	ListPeriods(ctx context.Context, scope domain.DataScope) ([]domain.AccountingPeriod, error)
+
+	// GetPeriod возвращает период месяца month; период, который ни разу не закрывали, открыт.
+	GetPeriod(ctx context.Context, scope domain.DataScope, month time.Time) (*domain.AccountingPeriod, error)
+
+	// ClosePeriod закрывает период в активной области данных пользователя.
+	ClosePeriod(ctx context.Context, user domain.User, month time.Time) (*domain.AccountingPeriod, error)
+
+	ReopenPeriod(ctx context.Context, user domain.User, month time.Time) (*domain.AccountingPeriod, error)
+}
@@ -0,0 +1,79 @@
+package period
+
+import (
+	"context"
+	"time"
+
+	"finance-backend/internal/domain"
+	"finance-backend/internal/repository/period"
+	"finance-backend/pkg/logger"
+)
+
+type PeriodUseCase struct {
+	repo  period.IPeriodRepository
+	audit domain.AuditRecorder
+	log   *logger.Logger
+}
+
+func NewPeriodUseCase(logger *logger.Logger, repo period.IPeriodRepository, audit domain.AuditRecorder) *PeriodUseCase {
+	return &PeriodUseCase{
+		log:   logger,
+		repo:  repo,
+		audit: audit,
+	}
+}
+
+func (uc *PeriodUseCase) ListPeriods(ctx context.Context, scope domain.DataScope) ([]domain.AccountingPeriod, error) {
+	return uc.repo.List(ctx, scope)
+}
+
+func (uc *PeriodUseCase) GetPeriod(ctx context.Context, scope domain.DataScope, month time.Time) (*domain.AccountingPeriod, error) {
+	month = domain.PeriodMonth(month)
+	period, err := uc.repo.Get(ctx, scope, month)
+	if err != nil {
+		return nil, err
+	}
+	if period == nil {
+		return &domain.AccountingPeriod{Month: month}, nil
+	}
+	return period, nil
+}
+
+func (uc *PeriodUseCase) ClosePeriod(ctx context.Context, user domain.User, month time.Time) (*domain.AccountingPeriod, error) {
+	scope := domain.ScopeOf(user)
+	month = domain.PeriodMonth(month)
+
+	period, err := uc.repo.Close(ctx, scope, month, user.Login)
+	if err != nil {
+		return nil, err
+	}
+	uc.record(ctx, scope, domain.AuditActionClose, period)
+	return period, nil
+}
+
+func (uc *PeriodUseCase) ReopenPeriod(ctx context.Context, user domain.User, month time.Time) (*domain.AccountingPeriod, error) {
+	scope := domain.ScopeOf(user)
+	month = domain.PeriodMonth(month)
+
+	period, err := uc.repo.Reopen(ctx, scope, month, user.Login)
+	if err != nil {
+		return nil, err
+	}
+	uc.record(ctx, scope, domain.AuditActionReopen, period)
+	return period, nil
+}
+
+// record пишет закрытие или открытие периода в журнал аудита.
+func (uc *PeriodUseCase) record(ctx context.Context, scope domain.DataScope, action domain.AuditAction, period *domain.AccountingPeriod) {
+	month := period.Month.Format(domain.PeriodMonthLayout)
+	uc.audit.Record(ctx, domain.AuditEvent{
+		Action:     action,
+		EntityType: domain.AuditEntityPeriod,
+		EntityID:   month,
+		OrgID:      scope.OrgID,
+		Before:     map[string]interface{}{"month": month, "closed": !period.Closed},
+		After:      map[string]interface{}{"month": month, "closed": period.Closed},
+	})
+}
+
+var _ IPeriodUseCase = (*PeriodUseCase)(nil)
@@ -3,10 +3,65 @@ package user
 import (
 	"context"
 	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
 )
 
 type IUserUseCase interface {
-	RegisterUser(ctx context.Context, userCreationData *domain.UserCreationData) (*domain.AccessToken, error)
-	GetAccessToken(ctx context.Context, login, password string) (*domain.AccessToken, error)
+	RegisterUser(ctx context.Context, userCreationData *domain.UserCreationData) (*domain.TokenPair, error)
+	// GetAccessToken проверяет пароль. Если подключена 2FA, вместо токенов возвращает
+	// токен второго шага, который обменивается на токены через CompleteMFALogin.
+	GetAccessToken(ctx context.Context, login, password string) (*domain.LoginResult, error)
 	GetSubjectTypes() ([]map[string]string, error)
+
+	// RefreshTokens обменивает refresh-токен на новую пару токенов; предъявленный токен отзывается.
+	RefreshTokens(ctx context.Context, refreshToken string) (*domain.TokenPair, error)
+	// Logout отзывает текущий токен доступа и, если передан, refresh-токен этой сессии.
+	Logout(ctx context.Context, claims domain.TokenClaims, refreshToken string) error
+	// SwitchOrganization закрывает текущую сессию и открывает новую в организации orgID
+	// (0 — личный учет). В новых токенах указаны организация и роль пользователя в ней.
+	SwitchOrganization(ctx context.Context, claims domain.TokenClaims, refreshToken string, orgID int64) (*domain.TokenPair, error)
+	// LogoutAll завершает все сессии пользователя.
+	LogoutAll(ctx context.Context, claims domain.TokenClaims) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	IsUserBlocked(ctx context.Context, login string) (bool, error)
+
+	// RequestPasswordReset отправляет на email ссылку для сброса пароля. Неизвестный email
+	// не считается ошибкой, чтобы по ответу нельзя было узнать, зарегистрирован ли адрес.
+	RequestPasswordReset(ctx context.Context, email string) error
+	// ResetPassword задает новый пароль по токену из письма и завершает все сессии пользователя.
+	ResetPassword(ctx context.Context, token string, password string) error
+	// ChangePassword меняет пароль после проверки текущего, завершает остальные сессии
+	// и выдает новую пару токенов.
+	ChangePassword(ctx context.Context, claims domain.TokenClaims, currentPassword, newPassword string) (*domain.TokenPair, error)
+
+	// CompleteMFALogin завершает вход кодом из приложения или кодом восстановления.
+	CompleteMFALogin(ctx context.Context, mfaToken string, code string) (*domain.TokenPair, error)
+	GetMFAStatus(ctx context.Context, login string) (*domain.TOTPState, error)
+	// EnrollTOTP выдает секрет для приложения-аутентификатора; 2FA включается после ConfirmTOTP.
+	EnrollTOTP(ctx context.Context, login string) (*domain.TOTPEnrollment, error)
+	// ConfirmTOTP включает 2FA по первому коду из приложения и возвращает коды восстановления.
+	ConfirmTOTP(ctx context.Context, login string, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, login string, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, login string, password string, code string) error
+
+	// StartOIDCLogin начинает вход через внешнего провайдера (authorization code + PKCE).
+	StartOIDCLogin(ctx context.Context) (*domain.OIDCAuthorization, error)
+	// CompleteOIDCLogin обменивает код авторизации на токены локального пользователя.
+	CompleteOIDCLogin(ctx context.Context, code string, state string) (*domain.TokenPair, error)
+}
+
+// IUserAdminUseCase — управление учетными записями пользователей администратором.
+// admin — пользователь, выполняющий операцию: изменить роль или заблокировать себя нельзя.
+type IUserAdminUseCase interface {
+	SearchUsers(ctx context.Context, limit int, offset int, filter domain.UserSearchFilter) (utils.PaginatedEntities[domain.UserAccount], error)
+	GetUser(ctx context.Context, login string) (*domain.UserAccount, error)
+	ChangeUserRole(ctx context.Context, admin domain.User, login string, role domain.Role) (*domain.UserAccount, error)
+
+	// BlockUser блокирует вход и завершает все сессии пользователя.
+	BlockUser(ctx context.Context, admin domain.User, login string) (*domain.UserAccount, error)
+	UnblockUser(ctx context.Context, login string) (*domain.UserAccount, error)
+
+	// ResetUserPassword заменяет пароль временным, завершает все сессии пользователя
+	// и возвращает временный пароль. При входе с ним пользователь должен сменить пароль.
+	ResetUserPassword(ctx context.Context, login string) (string, error)
 }
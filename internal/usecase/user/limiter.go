@@ -0,0 +1,177 @@
+package user
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+	"time"
+)
+
+// LoginLimits — ограничения неудачных входов и регистраций. После LoginFreeAttempts
+// неудач подряд логин блокируется на LockoutBase, и каждая следующая неудача удваивает
+// блокировку вплоть до LockoutMax. Так же, с собственным порогом, считаются неудачи с
+// одного IP-адреса и регистрации с него. Счетчики забываются через Window без неудач.
+// Нулевое Window отключает ограничения.
+type LoginLimits struct {
+	LoginFreeAttempts  int
+	IPFreeAttempts     int
+	RegistrationsPerIP int
+	LockoutBase        time.Duration
+	LockoutMax         time.Duration
+	Window             time.Duration
+}
+
+func (l LoginLimits) enabled() bool {
+	return l.Window > 0
+}
+
+func (l LoginLimits) freeAttempts(scope domain.ThrottleScope) int {
+	switch scope {
+	case domain.ThrottleScopeLogin:
+		return l.LoginFreeAttempts
+	case domain.ThrottleScopeIP:
+		return l.IPFreeAttempts
+	default:
+		return l.RegistrationsPerIP
+	}
+}
+
+// lockout возвращает срок блокировки после failures неудач, 0 — блокировать рано.
+func (l LoginLimits) lockout(scope domain.ThrottleScope, failures int) time.Duration {
+	over := failures - l.freeAttempts(scope)
+	if over <= 0 {
+		return 0
+	}
+	lock := l.LockoutBase
+	for i := 1; i < over && lock < l.LockoutMax; i++ {
+		lock *= 2
+	}
+	if lock > l.LockoutMax {
+		lock = l.LockoutMax
+	}
+	return lock
+}
+
+// checkLoginAllowed отклоняет вход до проверки пароля, если логин или адрес заблокирован:
+// bcrypt не запускается на каждую попытку перебора.
+func (u *UserUseCase) checkLoginAllowed(ctx context.Context, login string) error {
+	if !u.limits.enabled() {
+		return nil
+	}
+	return u.checkThrottled(ctx, loginKeys(ctx, login), domain.AuthEventLoginThrottled, login)
+}
+
+// registerLoginFailure учитывает неудачный вход по логину и адресу и пишет событие в журнал.
+func (u *UserUseCase) registerLoginFailure(ctx context.Context, login string, reason string) error {
+	if err := u.recordAuthEvent(ctx, domain.AuthEventLoginFailed, login, reason); err != nil {
+		return err
+	}
+	if !u.limits.enabled() {
+		return nil
+	}
+
+	now := time.Now()
+	if err := u.attempts.DeleteStale(ctx, now.Add(-u.limits.Window)); err != nil {
+		return err
+	}
+	for _, key := range loginKeys(ctx, login) {
+		if _, err := u.registerFailure(ctx, key, login, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetLoginFailures сбрасывает счетчик логина после успешного входа. Счетчик адреса
+// не сбрасывается: иначе перебор чужих паролей можно чередовать со входом в свою учетную запись.
+func (u *UserUseCase) resetLoginFailures(ctx context.Context, login string) error {
+	if !u.limits.enabled() {
+		return nil
+	}
+	return u.attempts.Reset(ctx, domain.ThrottleKey{Scope: domain.ThrottleScopeLogin, Value: login})
+}
+
+// checkRegistrationAllowed учитывает каждую регистрацию с адреса, а не только неудачные:
+// регистрация тоже запускает bcrypt.
+func (u *UserUseCase) checkRegistrationAllowed(ctx context.Context, login string) error {
+	ip, ok := utils.GetClientIPFromContext(ctx)
+	if !u.limits.enabled() || !ok || ip == "" {
+		return nil
+	}
+
+	key := domain.ThrottleKey{Scope: domain.ThrottleScopeRegistration, Value: ip}
+	if err := u.checkThrottled(ctx, []domain.ThrottleKey{key}, domain.AuthEventRegistrationThrottled, login); err != nil {
+		return err
+	}
+
+	lock, err := u.registerFailure(ctx, key, login, time.Now())
+	if err != nil {
+		return err
+	}
+	if lock > 0 {
+		return &domain.TooManyAttemptsError{RetryAfter: lock}
+	}
+	return nil
+}
+
+func (u *UserUseCase) checkThrottled(ctx context.Context, keys []domain.ThrottleKey, event domain.AuthEventType, login string) error {
+	now := time.Now()
+	lockedUntil, err := u.attempts.GetLockedUntil(ctx, keys, now)
+	if err != nil {
+		return err
+	}
+	if lockedUntil == nil {
+		return nil
+	}
+
+	if err := u.recordAuthEvent(ctx, event, login, ""); err != nil {
+		return err
+	}
+	return &domain.TooManyAttemptsError{RetryAfter: lockedUntil.Sub(now)}
+}
+
+// registerFailure увеличивает счетчик ключа и при превышении порога блокирует ключ.
+// Возвращает срок блокировки, 0 — ключ не заблокирован.
+func (u *UserUseCase) registerFailure(ctx context.Context, key domain.ThrottleKey, login string, now time.Time) (time.Duration, error) {
+	failures, err := u.attempts.RegisterFailure(ctx, key, now, now.Add(-u.limits.Window))
+	if err != nil {
+		return 0, err
+	}
+
+	lock := u.limits.lockout(key.Scope, failures)
+	if lock == 0 {
+		return 0, nil
+	}
+	if err := u.attempts.LockUntil(ctx, key, now.Add(lock)); err != nil {
+		return 0, err
+	}
+	details := string(key.Scope) + " locked for " + lock.String()
+	if err := u.recordAuthEvent(ctx, domain.AuthEventLockout, login, details); err != nil {
+		return 0, err
+	}
+	return lock, nil
+}
+
+func (u *UserUseCase) recordAuthEvent(ctx context.Context, eventType domain.AuthEventType, login string, details string) error {
+	event := &domain.AuthEvent{Type: eventType, Login: login, Details: details}
+	event.IP, _ = utils.GetClientIPFromContext(ctx)
+	event.RequestID, _ = utils.GetRequestIDFromContext(ctx)
+	if err := u.attempts.CreateAuthEvent(ctx, event); err != nil {
+		return err
+	}
+
+	var after interface{}
+	if details != "" {
+		after = map[string]string{"details": details}
+	}
+	u.recordUserEvent(ctx, "", domain.AuditAction(eventType), login, nil, after)
+	return nil
+}
+
+func loginKeys(ctx context.Context, login string) []domain.ThrottleKey {
+	keys := []domain.ThrottleKey{{Scope: domain.ThrottleScopeLogin, Value: login}}
+	if ip, ok := utils.GetClientIPFromContext(ctx); ok && ip != "" {
+		keys = append(keys, domain.ThrottleKey{Scope: domain.ThrottleScopeIP, Value: ip})
+	}
+	return keys
+}
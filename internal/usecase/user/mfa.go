@@ -0,0 +1,320 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"finance-backend/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// mfaChallengeTTL — время на ввод кода после проверки пароля.
+	mfaChallengeTTL = 5 * time.Minute
+	// mfaMaxAttempts — после стольких неверных кодов вход нужно начинать заново.
+	mfaMaxAttempts = 5
+
+	recoveryCodesCount = 10
+
+	totpPeriod = 30
+	// totpSkew — сколько соседних интервалов принимается, чтобы пережить расхождение часов.
+	totpSkew = 1
+)
+
+// Параметры по умолчанию из RFC 6238: их понимают все приложения-аутентификаторы.
+var totpOptions = totp.ValidateOpts{
+	Period:    totpPeriod,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+func (u *UserUseCase) CompleteMFALogin(ctx context.Context, mfaToken string, code string) (*domain.TokenPair, error) {
+	challenge, err := u.mfa.GetChallenge(ctx, hashToken(mfaToken))
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+	if err := u.checkLoginAllowed(ctx, challenge.Login); err != nil {
+		return nil, err
+	}
+
+	rawUser, err := u.repo.GetRawUserByLogin(ctx, challenge.Login)
+	if err != nil {
+		return nil, err
+	}
+	if rawUser == nil {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+	if rawUser.Blocked {
+		return nil, domain.ErrUserBlocked
+	}
+
+	state, err := u.mfa.GetTOTPState(ctx, challenge.Login)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Enabled() {
+		// 2FA отключили, пока шел вход: пароль уже проверен, второй фактор не нужен.
+		return u.finishChallenge(ctx, challenge, rawUser)
+	}
+
+	ok, err := u.verifySecondFactor(ctx, state, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := u.mfa.RegisterFailedAttempt(ctx, challenge.ID, mfaMaxAttempts); err != nil {
+			return nil, err
+		}
+		// Неверные коды считаются вместе с неверными паролями: иначе код можно
+		// перебирать, заново входя с известным паролем.
+		if err := u.registerLoginFailure(ctx, challenge.Login, "wrong 2fa code"); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrMFACodeInvalid
+	}
+
+	return u.finishChallenge(ctx, challenge, rawUser)
+}
+
+func (u *UserUseCase) GetMFAStatus(ctx context.Context, login string) (*domain.TOTPState, error) {
+	state, err := u.mfa.GetTOTPState(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return state, nil
+}
+
+func (u *UserUseCase) EnrollTOTP(ctx context.Context, login string) (*domain.TOTPEnrollment, error) {
+	state, err := u.GetMFAStatus(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      u.totpIssuer,
+		AccountName: login,
+		Period:      totpOptions.Period,
+		Digits:      totpOptions.Digits,
+		Algorithm:   totpOptions.Algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Повторный вызов до подтверждения выдает новый секрет: прежний QR-код перестает подходить.
+	if err := u.mfa.SaveTOTPSecret(ctx, login, key.Secret()); err != nil {
+		return nil, err
+	}
+
+	return &domain.TOTPEnrollment{Secret: key.Secret(), OTPAuthURI: key.URL()}, nil
+}
+
+func (u *UserUseCase) ConfirmTOTP(ctx context.Context, login string, code string) ([]string, error) {
+	state, err := u.GetMFAStatus(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	if state.Secret == "" {
+		return nil, domain.ErrMFAEnrollmentNotStarted
+	}
+
+	ok, err := u.verifyTOTP(ctx, login, state.Secret, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrMFACodeInvalid
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfa.EnableTOTP(ctx, login, hashes); err != nil {
+		return nil, err
+	}
+	u.recordUserEvent(ctx, login, domain.AuditActionMFAEnable, login, nil, nil)
+	return codes, nil
+}
+
+func (u *UserUseCase) RegenerateRecoveryCodes(ctx context.Context, login string, code string) ([]string, error) {
+	state, err := u.GetMFAStatus(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Enabled() {
+		return nil, domain.ErrMFANotEnabled
+	}
+
+	ok, err := u.verifyTOTP(ctx, login, state.Secret, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrMFACodeInvalid
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfa.ReplaceRecoveryCodes(ctx, login, hashes); err != nil {
+		return nil, err
+	}
+	u.recordUserEvent(ctx, login, domain.AuditActionRecoveryCodes, login, nil, nil)
+	return codes, nil
+}
+
+func (u *UserUseCase) DisableTOTP(ctx context.Context, login string, password string, code string) error {
+	rawUser, err := u.repo.GetRawUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	if rawUser == nil {
+		return domain.ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(rawUser.PasswordHash), []byte(password)); err != nil {
+		return domain.ErrWrongPassword
+	}
+
+	state, err := u.GetMFAStatus(ctx, login)
+	if err != nil {
+		return err
+	}
+	if !state.Enabled() {
+		return domain.ErrMFANotEnabled
+	}
+
+	ok, err := u.verifySecondFactor(ctx, state, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrMFACodeInvalid
+	}
+
+	if err := u.mfa.DisableTOTP(ctx, login); err != nil {
+		return err
+	}
+	u.recordUserEvent(ctx, login, domain.AuditActionMFADisable, login, nil, nil)
+	return nil
+}
+
+// startMFAChallenge выдает токен второго шага входа вместо пары токенов.
+func (u *UserUseCase) startMFAChallenge(ctx context.Context, login string) (*domain.LoginResult, error) {
+	// Заодно убираем брошенные входы, чтобы таблица не росла.
+	if err := u.mfa.DeleteExpiredChallenges(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+
+	token, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &domain.MFAChallenge{
+		Login:     login,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(mfaChallengeTTL),
+	}
+	if err := u.mfa.CreateChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginResult{MFAToken: token, MFATokenExpires: challenge.ExpiresAt}, nil
+}
+
+// finishChallenge гасит токен второго шага и открывает сессию. Токен, уже
+// использованный параллельным запросом, повторно сессию не открывает.
+func (u *UserUseCase) finishChallenge(ctx context.Context, challenge *domain.MFAChallenge, rawUser *domain.RawUser) (*domain.TokenPair, error) {
+	deleted, err := u.mfa.DeleteChallenge(ctx, challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+	return u.completeLogin(ctx, rawUser)
+}
+
+// verifySecondFactor принимает код из приложения (6 цифр) или код восстановления.
+func (u *UserUseCase) verifySecondFactor(ctx context.Context, state *domain.TOTPState, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		return u.verifyTOTP(ctx, state.Login, state.Secret, code)
+	}
+	return u.mfa.UseRecoveryCode(ctx, state.Login, hashToken(normalizeRecoveryCode(code)))
+}
+
+// verifyTOTP проверяет код в текущем и соседних интервалах и запоминает принятый интервал,
+// чтобы перехваченный код нельзя было использовать повторно.
+func (u *UserUseCase) verifyTOTP(ctx context.Context, login string, secret string, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if !isTOTPCode(code) {
+		return false, nil
+	}
+
+	now := time.Now()
+	for offset := -totpSkew; offset <= totpSkew; offset++ {
+		at := now.Add(time.Duration(offset*totpPeriod) * time.Second)
+		expected, err := totp.GenerateCodeCustom(secret, at, totpOptions)
+		if err != nil {
+			return false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return u.mfa.UseTOTPStep(ctx, login, at.Unix()/totpPeriod)
+		}
+	}
+	return false, nil
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totpOptions.Digits.Length() {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRecoveryCodes возвращает коды вида abcd-efgh и их хеши для хранения.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		secret := make([]byte, 5)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(secret))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashToken(code)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode допускает ввод без дефиса и в любом регистре.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
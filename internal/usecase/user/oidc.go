@@ -0,0 +1,256 @@
+package user
+
+import (
+	"context"
+	"finance-backend/internal/domain"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// oidcLoginTTL — время на вход на странице провайдера.
+	oidcLoginTTL = 10 * time.Minute
+
+	// unusablePasswordHash не совпадает ни с одним паролем: у созданных через SSO
+	// пользователей нет локального пароля, пока они не зададут его через сброс пароля.
+	unusablePasswordHash = "!"
+
+	maxLoginLength = 50
+)
+
+// OIDCSettings — правила сопоставления учетных записей провайдера с пользователями.
+type OIDCSettings struct {
+	// AutoProvision создает пользователя при первом входе с подтвержденным email.
+	AutoProvision bool
+	// AllowedDomains ограничивает автосоздание доменами email; пусто — любой домен.
+	AllowedDomains []string
+	DefaultRole    domain.Role
+	// LinkByEmail привязывает учетную запись провайдера к существующему пользователю
+	// с тем же подтвержденным email.
+	LinkByEmail bool
+}
+
+// StartOIDCLogin сохраняет state, nonce и верификатор PKCE и возвращает адрес
+// страницы входа провайдера.
+func (u *UserUseCase) StartOIDCLogin(ctx context.Context) (*domain.OIDCAuthorization, error) {
+	if u.sso == nil {
+		return nil, domain.ErrOIDCDisabled
+	}
+
+	now := time.Now()
+	if err := u.identities.DeleteExpiredLoginStates(ctx, now); err != nil {
+		return nil, err
+	}
+
+	state, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+	// 32 случайных байта в base64url — 43 символа, минимальная длина верификатора PKCE.
+	codeVerifier, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := u.sso.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	loginState := &domain.OIDCLoginState{
+		StateHash:    hashToken(state),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    now.Add(oidcLoginTTL),
+	}
+	if err := u.identities.CreateLoginState(ctx, loginState); err != nil {
+		return nil, err
+	}
+
+	return &domain.OIDCAuthorization{URL: authURL, State: state, ExpiresAt: loginState.ExpiresAt}, nil
+}
+
+// CompleteOIDCLogin проверяет код авторизации и открывает сессию пользователя,
+// сопоставленного учетной записи провайдера. Второй фактор проверяет провайдер.
+func (u *UserUseCase) CompleteOIDCLogin(ctx context.Context, code string, state string) (*domain.TokenPair, error) {
+	if u.sso == nil {
+		return nil, domain.ErrOIDCDisabled
+	}
+
+	loginState, err := u.identities.ConsumeLoginState(ctx, hashToken(state), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if loginState == nil {
+		return nil, domain.ErrOIDCStateInvalid
+	}
+
+	identity, err := u.sso.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrOIDCLoginFailed, err)
+	}
+
+	login, err := u.resolveOIDCUser(ctx, identity)
+	if err != nil {
+		if err == domain.ErrOIDCUserNotProvisioned {
+			details := fmt.Sprintf("oidc user not provisioned: %s %s", identity.Subject, identity.Email)
+			if eventErr := u.recordAuthEvent(ctx, domain.AuthEventLoginFailed, "", details); eventErr != nil {
+				return nil, eventErr
+			}
+		}
+		return nil, err
+	}
+
+	rawUser, err := u.repo.GetRawUserByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if rawUser == nil {
+		return nil, domain.ErrOIDCUserNotProvisioned
+	}
+	if rawUser.Blocked {
+		return nil, domain.ErrUserBlocked
+	}
+	if err := u.identities.TouchIdentity(ctx, identity.Issuer, identity.Subject, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return u.completeLogin(ctx, rawUser)
+}
+
+// resolveOIDCUser возвращает логин пользователя для учетной записи провайдера:
+// уже привязанного, найденного по email или созданного по правилам автосоздания.
+func (u *UserUseCase) resolveOIDCUser(ctx context.Context, identity *domain.OIDCIdentity) (string, error) {
+	login, err := u.identities.GetIdentityLogin(ctx, identity.Issuer, identity.Subject)
+	if err != nil || login != "" {
+		return login, err
+	}
+
+	// Непроверенному email доверять нельзя: так можно войти в чужую учетную запись.
+	if identity.Email == "" || !identity.EmailVerified {
+		return "", domain.ErrOIDCUserNotProvisioned
+	}
+
+	existing, err := u.repo.GetRawUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		if !u.oidcSettings.LinkByEmail {
+			return "", domain.ErrOIDCUserNotProvisioned
+		}
+		if err := u.identities.LinkIdentity(ctx, identity, existing.Login); err != nil {
+			return "", err
+		}
+		return existing.Login, nil
+	}
+
+	if !u.oidcSettings.AutoProvision || !u.oidcDomainAllowed(identity.Email) {
+		return "", domain.ErrOIDCUserNotProvisioned
+	}
+	return u.provisionOIDCUser(ctx, identity)
+}
+
+func (u *UserUseCase) provisionOIDCUser(ctx context.Context, identity *domain.OIDCIdentity) (string, error) {
+	login, err := u.freeLogin(ctx, oidcLoginCandidate(identity))
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = identity.Email
+	}
+	data := &domain.UserCreationData{
+		UserType: domain.UserTypeFL,
+		Login:    login,
+		Name:     name,
+		Password: unusablePasswordHash,
+		Email:    identity.Email,
+	}
+	if err := u.repo.CreateUser(ctx, data); err != nil {
+		return "", err
+	}
+
+	role := u.oidcSettings.DefaultRole
+	if role != "" && role != domain.RoleUser {
+		if err := u.repo.UpdateUserRole(ctx, login, role); err != nil {
+			return "", err
+		}
+	}
+
+	if err := u.identities.LinkIdentity(ctx, identity, login); err != nil {
+		return "", err
+	}
+	if role == "" {
+		role = domain.RoleUser
+	}
+	u.recordUserEvent(ctx, login, domain.AuditActionCreate, login, nil, newUserState(data, role))
+	return login, nil
+}
+
+func (u *UserUseCase) oidcDomainAllowed(email string) bool {
+	if len(u.oidcSettings.AllowedDomains) == 0 {
+		return true
+	}
+	_, emailDomain, _ := strings.Cut(strings.ToLower(email), "@")
+	for _, allowed := range u.oidcSettings.AllowedDomains {
+		if emailDomain == strings.ToLower(strings.TrimSpace(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// freeLogin подбирает незанятый логин: base, base2, base3...
+func (u *UserUseCase) freeLogin(ctx context.Context, base string) (string, error) {
+	for i := 1; i <= 100; i++ {
+		candidate := base
+		if i > 1 {
+			suffix := strconv.Itoa(i)
+			candidate = truncate(base, maxLoginLength-len(suffix)) + suffix
+		}
+		existing, err := u.repo.GetRawUserByLogin(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+	}
+	return "", domain.ErrUserAlreadyExists
+}
+
+// oidcLoginCandidate строит логин из preferred_username или начала email:
+// латинские буквы, цифры, точка, дефис и подчеркивание.
+func oidcLoginCandidate(identity *domain.OIDCIdentity) string {
+	source := identity.PreferredUsername
+	if source == "" {
+		source, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	var b strings.Builder
+	for _, c := range strings.ToLower(source) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '.' || c == '-' || c == '_' {
+			b.WriteRune(c)
+		}
+	}
+	login := b.String()
+	if len(login) < 3 {
+		login = "user" + login
+	}
+	return truncate(login, maxLoginLength)
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
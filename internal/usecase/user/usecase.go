@@ -2,26 +2,98 @@ package user
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"finance-backend/internal/domain"
+	"finance-backend/internal/gateways/mail_gateway"
+	"finance-backend/internal/gateways/oidc_gateway"
+	attemptRepo "finance-backend/internal/repository/attempt"
+	mfaRepo "finance-backend/internal/repository/mfa"
+	oidcRepo "finance-backend/internal/repository/oidc"
+	orgRepo "finance-backend/internal/repository/organization"
+	tokenRepo "finance-backend/internal/repository/token"
 	repo "finance-backend/internal/repository/user"
+	"finance-backend/pkg/utils"
+	"finance-backend/pkg/validation"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// TokenSigner подписывает токены доступа текущим ключом.
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+// Settings — параметры сессий и восстановления доступа.
+type Settings struct {
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	PasswordResetTTL time.Duration
+	PasswordResetURL string // страница фронтенда, к ней добавляется параметр token
+	TOTPIssuer       string // название сервиса в приложении-аутентификаторе
+	LoginLimits      LoginLimits
+	OIDC             OIDCSettings
+}
+
 type UserUseCase struct {
-	repo       repo.IUserRepository
-	privateKey *rsa.PrivateKey
-	tokenTTL   time.Duration
+	repo             repo.IUserRepository
+	tokens           tokenRepo.ITokenRepository
+	mfa              mfaRepo.IMFARepository
+	attempts         attemptRepo.IAttemptRepository
+	identities       oidcRepo.IOIDCRepository
+	orgs             orgRepo.IOrganizationRepository
+	audit            domain.AuditRecorder
+	signer           TokenSigner
+	mailer           mail_gateway.IMailGateway
+	sso              oidc_gateway.IOIDCGateway // nil, если вход через провайдера не настроен
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+	passwordResetTTL time.Duration
+	passwordResetURL string
+	totpIssuer       string
+	limits           LoginLimits
+	oidcSettings     OIDCSettings
 }
 
-func NewUserUseCase(repo repo.IUserRepository, privateKey *rsa.PrivateKey, tokenTTL time.Duration) *UserUseCase {
+func NewUserUseCase(
+	repo repo.IUserRepository,
+	tokens tokenRepo.ITokenRepository,
+	mfa mfaRepo.IMFARepository,
+	attempts attemptRepo.IAttemptRepository,
+	identities oidcRepo.IOIDCRepository,
+	orgs orgRepo.IOrganizationRepository,
+	audit domain.AuditRecorder,
+	signer TokenSigner,
+	mailer mail_gateway.IMailGateway,
+	sso oidc_gateway.IOIDCGateway,
+	settings Settings,
+) *UserUseCase {
 	return &UserUseCase{
-		repo:       repo,
-		privateKey: privateKey,
-		tokenTTL:   tokenTTL,
+		repo:             repo,
+		tokens:           tokens,
+		mfa:              mfa,
+		attempts:         attempts,
+		identities:       identities,
+		orgs:             orgs,
+		audit:            audit,
+		signer:           signer,
+		mailer:           mailer,
+		sso:              sso,
+		accessTokenTTL:   settings.AccessTokenTTL,
+		refreshTokenTTL:  settings.RefreshTokenTTL,
+		passwordResetTTL: settings.PasswordResetTTL,
+		passwordResetURL: settings.PasswordResetURL,
+		totpIssuer:       settings.TOTPIssuer,
+		limits:           settings.LoginLimits,
+		oidcSettings:     settings.OIDC,
 	}
 }
 
@@ -38,7 +110,11 @@ func (u *UserUseCase) GetSubjectTypes() ([]map[string]string, error) {
 	}, nil
 }
 
-func (u *UserUseCase) RegisterUser(ctx context.Context, data *domain.UserCreationData) (*domain.AccessToken, error) {
+func (u *UserUseCase) RegisterUser(ctx context.Context, data *domain.UserCreationData) (*domain.TokenPair, error) {
+	if err := u.checkRegistrationAllowed(ctx, data.Login); err != nil {
+		return nil, err
+	}
+
 	existingUser, err := u.repo.GetRawUserByLogin(ctx, data.Login)
 	if err != nil {
 		return nil, err
@@ -46,6 +122,15 @@ func (u *UserUseCase) RegisterUser(ctx context.Context, data *domain.UserCreatio
 	if existingUser != nil {
 		return nil, domain.ErrUserAlreadyExists
 	}
+	if data.Email != "" {
+		existingUser, err = u.repo.GetRawUserByEmail(ctx, data.Email)
+		if err != nil {
+			return nil, err
+		}
+		if existingUser != nil {
+			return nil, domain.ErrEmailAlreadyUsed
+		}
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -53,47 +138,533 @@ func (u *UserUseCase) RegisterUser(ctx context.Context, data *domain.UserCreatio
 	}
 
 	data.Password = string(hashedPassword)
+	data.Phone = validation.NormalizePhoneOrKeep(data.Phone)
 	if err := u.repo.CreateUser(ctx, data); err != nil {
 		return nil, err
 	}
+	u.recordUserEvent(ctx, data.Login, domain.AuditActionCreate, data.Login, nil, newUserState(data, domain.RoleUser))
 
-	return u.issueToken(data.Login, false)
+	return u.startSession(ctx, data.Login, domain.RoleUser, activeOrg{})
 }
 
-func (u *UserUseCase) GetAccessToken(ctx context.Context, login, password string) (*domain.AccessToken, error) {
+func (u *UserUseCase) GetAccessToken(ctx context.Context, login, password string) (*domain.LoginResult, error) {
+	if err := u.checkLoginAllowed(ctx, login); err != nil {
+		return nil, err
+	}
+
 	rawUser, err := u.repo.GetRawUserByLogin(ctx, login)
 	if err != nil {
 		return nil, err
 	}
 	if rawUser == nil {
-		return nil, domain.ErrWrongLoginOrPassword
+		return nil, u.rejectLogin(ctx, login, "unknown login")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(rawUser.PasswordHash), []byte(password)); err != nil {
-		return nil, domain.ErrWrongLoginOrPassword
+		return nil, u.rejectLogin(ctx, login, "wrong password")
+	}
+	if rawUser.Blocked {
+		return nil, domain.ErrUserBlocked
+	}
+
+	state, err := u.mfa.GetTOTPState(ctx, rawUser.Login)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled() {
+		return u.startMFAChallenge(ctx, rawUser.Login)
+	}
+
+	pair, err := u.completeLogin(ctx, rawUser)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{Tokens: pair}, nil
+}
+
+// rejectLogin учитывает неудачный вход и возвращает ошибку для клиента.
+func (u *UserUseCase) rejectLogin(ctx context.Context, login string, reason string) error {
+	if err := u.registerLoginFailure(ctx, login, reason); err != nil {
+		return err
+	}
+	return domain.ErrWrongLoginOrPassword
+}
+
+// completeLogin открывает сессию после проверки всех факторов. Счетчик неудач логина
+// сбрасывается только здесь: верный пароль без второго фактора его не обнуляет.
+func (u *UserUseCase) completeLogin(ctx context.Context, rawUser *domain.RawUser) (*domain.TokenPair, error) {
+	if err := u.resetLoginFailures(ctx, rawUser.Login); err != nil {
+		return nil, err
+	}
+	pair, err := u.startSession(ctx, rawUser.Login, rawUser.Role, activeOrg{})
+	if err != nil {
+		return nil, err
+	}
+	u.recordUserEvent(ctx, rawUser.Login, domain.AuditActionLogin, rawUser.Login, nil, nil)
+	pair.PasswordChangeRequired = rawUser.PasswordResetRequired
+	return pair, nil
+}
+
+func (u *UserUseCase) RefreshTokens(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	current, err := u.tokens.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.ExpiresAt.Before(time.Now()) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if current.RevokedAt != nil {
+		// Использованный токен предъявлен повторно — вероятно, он утек.
+		// Отзываем всю цепочку, чтобы выйти и у злоумышленника, и у владельца.
+		if err := u.tokens.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	rawUser, err := u.repo.GetRawUserByLogin(ctx, current.Login)
+	if err != nil {
+		return nil, err
+	}
+	if rawUser == nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if rawUser.Blocked {
+		return nil, domain.ErrUserBlocked
+	}
+
+	// Участника могли исключить или сменить ему роль — проверяем членство заново.
+	// Исключенный из организации продолжает работу в личном учете.
+	org, err := u.resolveOrg(ctx, rawUser.Login, current.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, next, err := u.issueTokens(rawUser.Login, rawUser.Role, org, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.tokens.RotateRefreshToken(ctx, current.ID, next)
+	if errors.Is(err, domain.ErrInvalidRefreshToken) {
+		// Токен успели использовать параллельно — поступаем так же, как при повторном предъявлении.
+		if err := u.tokens.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+func (u *UserUseCase) Logout(ctx context.Context, claims domain.TokenClaims, refreshToken string) error {
+	if err := u.endSession(ctx, claims, refreshToken); err != nil {
+		return err
+	}
+	u.recordUserEvent(ctx, claims.Login, domain.AuditActionLogout, claims.Login, nil, nil)
+	return nil
+}
+
+// endSession отзывает токен доступа claims и, если передан, refresh-токен той же сессии.
+func (u *UserUseCase) endSession(ctx context.Context, claims domain.TokenClaims, refreshToken string) error {
+	if refreshToken != "" {
+		if err := u.tokens.RevokeRefreshToken(ctx, claims.Login, hashToken(refreshToken)); err != nil {
+			return err
+		}
+	}
+	return u.tokens.RevokeAccessTokens(ctx, []string{claims.ID}, claims.ExpiresAt)
+}
+
+func (u *UserUseCase) SwitchOrganization(ctx context.Context, claims domain.TokenClaims, refreshToken string, orgID int64) (*domain.TokenPair, error) {
+	rawUser, err := u.repo.GetRawUserByLogin(ctx, claims.Login)
+	if err != nil {
+		return nil, err
+	}
+	if rawUser == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	org, err := u.resolveOrg(ctx, rawUser.Login, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org.id != orgID {
+		return nil, domain.ErrOrganizationNotFound
+	}
+
+	// Старая сессия закрывается, чтобы токены с прежней организацией не оставались в обороте.
+	if err := u.endSession(ctx, claims, refreshToken); err != nil {
+		return nil, err
+	}
+	pair, err := u.startSession(ctx, rawUser.Login, rawUser.Role, org)
+	if err != nil {
+		return nil, err
+	}
+	u.recordUserEvent(ctx, rawUser.Login, domain.AuditActionSwitchOrg, rawUser.Login,
+		map[string]interface{}{"organizationId": claims.OrgID},
+		map[string]interface{}{"organizationId": org.id})
+	return pair, nil
+}
+
+func (u *UserUseCase) LogoutAll(ctx context.Context, claims domain.TokenClaims) error {
+	if err := u.revokeSessions(ctx, claims.Login, claims.ID); err != nil {
+		return err
+	}
+	u.recordUserEvent(ctx, claims.Login, domain.AuditActionLogoutAll, claims.Login, nil, nil)
+	return nil
+}
+
+func (u *UserUseCase) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return u.tokens.IsAccessTokenRevoked(ctx, tokenID)
+}
+
+func (u *UserUseCase) IsUserBlocked(ctx context.Context, login string) (bool, error) {
+	return u.repo.IsUserBlocked(ctx, login)
+}
+
+func (u *UserUseCase) RequestPasswordReset(ctx context.Context, email string) error {
+	rawUser, err := u.repo.GetRawUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if rawUser == nil || rawUser.Blocked {
+		return nil
+	}
+
+	token, err := generateSecret(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(u.passwordResetTTL)
+	if err := u.tokens.CreatePasswordResetToken(ctx, rawUser.Login, hashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(u.passwordResetURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return u.mailer.Send(ctx, mail_gateway.Message{
+		To:      rawUser.Email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf(
+			"Здравствуйте, %s!\n\n"+
+				"Чтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+				"Ссылка действует до %s и может быть использована один раз.\n"+
+				"Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.\n",
+			rawUser.Login, link.String(), expiresAt.Format("02.01.2006 15:04 MST"),
+		),
+	})
+}
+
+func (u *UserUseCase) ResetPassword(ctx context.Context, token string, password string) error {
+	login, err := u.tokens.ConsumePasswordResetToken(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+
+	if err := u.setPassword(ctx, login, password, false); err != nil {
+		return err
+	}
+	if err := u.revokeSessions(ctx, login); err != nil {
+		return err
+	}
+	u.recordUserEvent(ctx, login, domain.AuditActionPasswordReset, login, nil, nil)
+	return nil
+}
+
+func (u *UserUseCase) ChangePassword(ctx context.Context, claims domain.TokenClaims, currentPassword, newPassword string) (*domain.TokenPair, error) {
+	rawUser, err := u.repo.GetRawUserByLogin(ctx, claims.Login)
+	if err != nil {
+		return nil, err
+	}
+	if rawUser == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(rawUser.PasswordHash), []byte(currentPassword)); err != nil {
+		return nil, domain.ErrWrongPassword
+	}
+
+	if err := u.setPassword(ctx, rawUser.Login, newPassword, false); err != nil {
+		return nil, err
+	}
+	// Старый пароль мог быть известен кому-то еще: завершаем все сессии, включая текущую,
+	// и сразу открываем новую, чтобы пользователю не пришлось входить заново.
+	if err := u.revokeSessions(ctx, rawUser.Login, claims.ID); err != nil {
+		return nil, err
+	}
+	u.recordUserEvent(ctx, rawUser.Login, domain.AuditActionPasswordChange, rawUser.Login, nil, nil)
+	org, err := u.resolveOrg(ctx, rawUser.Login, claims.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	return u.startSession(ctx, rawUser.Login, rawUser.Role, org)
+}
+
+func (u *UserUseCase) SearchUsers(ctx context.Context, limit int, offset int, filter domain.UserSearchFilter) (utils.PaginatedEntities[domain.UserAccount], error) {
+	return u.repo.SearchUsersPaginated(ctx, limit, offset, filter)
+}
+
+func (u *UserUseCase) GetUser(ctx context.Context, login string) (*domain.UserAccount, error) {
+	user, err := u.repo.GetUserAccount(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (u *UserUseCase) ChangeUserRole(ctx context.Context, admin domain.User, login string, role domain.Role) (*domain.UserAccount, error) {
+	if !role.IsValid() {
+		return nil, domain.ErrRoleInvalid
+	}
+	// Иначе последний администратор может случайно лишить себя доступа к управлению.
+	if admin.Login == login {
+		return nil, domain.ErrOwnAccountModification
 	}
 
-	return u.issueToken(rawUser.Login, rawUser.IsAdmin)
+	before, err := u.GetUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.UpdateUserRole(ctx, login, role); err != nil {
+		return nil, err
+	}
+	// Роль передается в токене доступа: без отзыва сессий пониженный пользователь сохранил бы
+	// прежние права до истечения токена.
+	if before.Role != role {
+		if err := u.revokeSessions(ctx, login); err != nil {
+			return nil, err
+		}
+	}
+	return u.recordAccountChange(ctx, domain.AuditActionRoleChange, before)
+}
+
+func (u *UserUseCase) BlockUser(ctx context.Context, admin domain.User, login string) (*domain.UserAccount, error) {
+	if admin.Login == login {
+		return nil, domain.ErrOwnAccountModification
+	}
+	before, err := u.GetUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.SetUserBlocked(ctx, login, true); err != nil {
+		return nil, err
+	}
+	if err := u.revokeSessions(ctx, login); err != nil {
+		return nil, err
+	}
+	return u.recordAccountChange(ctx, domain.AuditActionBlock, before)
+}
+
+func (u *UserUseCase) UnblockUser(ctx context.Context, login string) (*domain.UserAccount, error) {
+	before, err := u.GetUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.SetUserBlocked(ctx, login, false); err != nil {
+		return nil, err
+	}
+	return u.recordAccountChange(ctx, domain.AuditActionUnblock, before)
 }
 
-func (u *UserUseCase) issueToken(login string, isAdmin bool) (*domain.AccessToken, error) {
+func (u *UserUseCase) ResetUserPassword(ctx context.Context, login string) (string, error) {
+	before, err := u.GetUser(ctx, login)
+	if err != nil {
+		return "", err
+	}
+	password, err := generateSecret(12)
+	if err != nil {
+		return "", err
+	}
+
+	if err := u.setPassword(ctx, login, password, true); err != nil {
+		return "", err
+	}
+	if err := u.revokeSessions(ctx, login); err != nil {
+		return "", err
+	}
+	after := *before
+	after.PasswordResetRequired = true
+	u.recordUserEvent(ctx, "", domain.AuditActionPasswordReset, login, accountState(before), accountState(&after))
+	return password, nil
+}
+
+// recordAccountChange перечитывает учетную запись после изменения администратором
+// и пишет в журнал аудита ее состояние до и после.
+func (u *UserUseCase) recordAccountChange(ctx context.Context, action domain.AuditAction, before *domain.UserAccount) (*domain.UserAccount, error) {
+	after, err := u.GetUser(ctx, before.Login)
+	if err != nil {
+		return nil, err
+	}
+	u.recordUserEvent(ctx, "", action, before.Login, accountState(before), accountState(after))
+	return after, nil
+}
+
+// recordUserEvent пишет в журнал аудита событие учетной записи login. Пустой actor —
+// пользователь из контекста запроса (администратор).
+func (u *UserUseCase) recordUserEvent(ctx context.Context, actor string, action domain.AuditAction, login string, before, after interface{}) {
+	u.audit.Record(ctx, domain.AuditEvent{
+		Actor:      actor,
+		Action:     action,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   login,
+		Before:     before,
+		After:      after,
+	})
+}
+
+// accountState — поля учетной записи, которые меняет администратор.
+func accountState(account *domain.UserAccount) map[string]interface{} {
+	return map[string]interface{}{
+		"role":                  account.Role,
+		"blocked":               account.BlockedAt != nil,
+		"passwordResetRequired": account.PasswordResetRequired,
+	}
+}
+
+// newUserState — данные новой учетной записи для журнала аудита, без пароля.
+func newUserState(data *domain.UserCreationData, role domain.Role) map[string]interface{} {
+	return map[string]interface{}{
+		"loginName": data.Login,
+		"userType":  data.UserType,
+		"name":      data.Name,
+		"inn":       data.INN,
+		"email":     data.Email,
+		"role":      role,
+	}
+}
+
+// setPassword сохраняет bcrypt-хеш пароля; resetRequired требует сменить пароль после входа.
+func (u *UserUseCase) setPassword(ctx context.Context, login string, password string, resetRequired bool) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return u.repo.SetUserPassword(ctx, login, string(hashedPassword), resetRequired)
+}
+
+// revokeSessions отзывает все refresh-токены пользователя и еще действующие токены доступа,
+// а также токены с перечисленными jti.
+func (u *UserUseCase) revokeSessions(ctx context.Context, login string, accessTokenIDs ...string) error {
+	// Токены доступа, выданные раньше accessTokenTTL, уже истекли сами.
+	now := time.Now()
+	ids, err := u.tokens.RevokeUserRefreshTokens(ctx, login, now.Add(-u.accessTokenTTL))
+	if err != nil {
+		return err
+	}
+
+	ids = append(ids, accessTokenIDs...)
+	return u.tokens.RevokeAccessTokens(ctx, ids, now.Add(u.accessTokenTTL))
+}
+
+// activeOrg — организация, в которой работает сессия; нулевое значение — личный учет.
+type activeOrg struct {
+	id   int64
+	role domain.OrgRole
+}
+
+// resolveOrg проверяет, что пользователь состоит в организации, и возвращает его роль в ней.
+// Если не состоит, сессия остается в личном учете.
+func (u *UserUseCase) resolveOrg(ctx context.Context, login string, orgID int64) (activeOrg, error) {
+	if orgID == 0 {
+		return activeOrg{}, nil
+	}
+	role, err := u.orgs.GetMemberRole(ctx, orgID, login)
+	if err != nil {
+		return activeOrg{}, err
+	}
+	if role == "" {
+		return activeOrg{}, nil
+	}
+	return activeOrg{id: orgID, role: role}, nil
+}
+
+// startSession выдает пару токенов новой цепочки обновлений.
+func (u *UserUseCase) startSession(ctx context.Context, login string, role domain.Role, org activeOrg) (*domain.TokenPair, error) {
+	pair, refresh, err := u.issueTokens(login, role, org, uuid.NewString())
+	if err != nil {
+		return nil, err
+	}
+	if err := u.tokens.CreateRefreshToken(ctx, refresh); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+func (u *UserUseCase) issueTokens(login string, role domain.Role, org activeOrg, familyID string) (*domain.TokenPair, *domain.RefreshToken, error) {
+	now := time.Now()
+	accessExpiresAt := now.Add(u.accessTokenTTL)
+	accessTokenID := uuid.NewString()
+
 	claims := jwt.MapClaims{
 		"sub":      login,
-		"is_admin": isAdmin,
-		"exp":      time.Now().Add(u.tokenTTL).Unix(),
-		"iat":      time.Now().Unix(),
+		"role":     role.String(),
+		"is_admin": role == domain.RoleAdmin,
+		"jti":      accessTokenID,
+		"exp":      accessExpiresAt.Unix(),
+		"iat":      now.Unix(),
+	}
+	if org.id != 0 {
+		claims["org"] = org.id
+		claims["org_role"] = org.role.String()
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	signed, err := u.signer.Sign(claims)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	signed, err := token.SignedString(u.privateKey)
+	refreshToken, err := generateSecret(32)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	t := domain.AccessToken(signed)
+	refresh := &domain.RefreshToken{
+		Login:         login,
+		TokenHash:     hashToken(refreshToken),
+		OrgID:         org.id,
+		FamilyID:      familyID,
+		AccessTokenID: accessTokenID,
+		ExpiresAt:     now.Add(u.refreshTokenTTL),
+	}
+
+	return &domain.TokenPair{
+		AccessToken:           domain.AccessToken(signed),
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refresh.ExpiresAt,
+	}, refresh, nil
+}
+
+// generateSecret возвращает size случайных байт в base64url: refresh-токены, токены
+// сброса пароля, временные пароли.
+func generateSecret(size int) (string, error) {
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
 
-	return &t, nil
+// hashToken — refresh-токены хранятся только в виде SHA-256: утечка таблицы не дает войти.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 var _ IUserUseCase = (*UserUseCase)(nil)
+var _ IUserAdminUseCase = (*UserUseCase)(nil)
@@ -0,0 +1,119 @@
+// Package fiscal разбирает данные кассовых чеков ФНС.
+package fiscal
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Признак расчета (тег 1054): значение параметра n в QR-коде чека.
+const (
+	OperationIncome        = 1 // Приход
+	OperationIncomeReturn  = 2 // Возврат прихода
+	OperationExpense       = 3 // Расход
+	OperationExpenseReturn = 4 // Возврат расхода
+)
+
+// ErrInvalidQR — строка не является QR-кодом кассового чека.
+var ErrInvalidQR = errors.New("invalid receipt qr")
+
+// Время в QR-коде указывается без часового пояса; считаем его московским,
+// как это делает сервис проверки чеков ФНС.
+var receiptLocation = time.FixedZone("MSK", 3*60*60)
+
+var receiptTimeLayouts = []string{"20060102T150405", "20060102T1504"}
+
+// Receipt — реквизиты чека из QR-кода.
+type Receipt struct {
+	DateTime       time.Time
+	Amount         float64
+	FiscalDrive    string // fn — номер фискального накопителя
+	FiscalDocument string // i — номер фискального документа
+	FiscalSign     string // fp — фискальный признак документа
+	Operation      int    // n — признак расчета
+}
+
+// IsExpense сообщает, уходят ли по чеку деньги покупателя: приход у продавца
+// или возврат расхода.
+func (r *Receipt) IsExpense() bool {
+	return r.Operation == OperationIncome || r.Operation == OperationExpenseReturn
+}
+
+// ParseQR разбирает строку вида t=20250418T1230&s=1234.00&fn=...&i=...&fp=...&n=1.
+// Допускается и ссылка, в параметрах которой передана такая строка.
+func ParseQR(payload string) (*Receipt, error) {
+	payload = strings.TrimSpace(payload)
+	if i := strings.IndexByte(payload, '?'); i >= 0 {
+		payload = payload[i+1:]
+	}
+
+	values, err := url.ParseQuery(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQR, err)
+	}
+
+	receipt := &Receipt{
+		FiscalDrive:    values.Get("fn"),
+		FiscalDocument: values.Get("i"),
+		FiscalSign:     values.Get("fp"),
+	}
+
+	if receipt.DateTime, err = parseTime(values.Get("t")); err != nil {
+		return nil, err
+	}
+	if receipt.Amount, err = parseAmount(values.Get("s")); err != nil {
+		return nil, err
+	}
+	if !isDigits(receipt.FiscalDrive, 16, 16) {
+		return nil, fmt.Errorf("%w: fn must be 16 digits", ErrInvalidQR)
+	}
+	if !isDigits(receipt.FiscalDocument, 1, 10) {
+		return nil, fmt.Errorf("%w: i must be up to 10 digits", ErrInvalidQR)
+	}
+	if !isDigits(receipt.FiscalSign, 1, 10) {
+		return nil, fmt.Errorf("%w: fp must be up to 10 digits", ErrInvalidQR)
+	}
+
+	receipt.Operation, err = strconv.Atoi(values.Get("n"))
+	if err != nil || receipt.Operation < OperationIncome || receipt.Operation > OperationExpenseReturn {
+		return nil, fmt.Errorf("%w: unknown operation type %q", ErrInvalidQR, values.Get("n"))
+	}
+
+	return receipt, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range receiptTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, receiptLocation); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: bad time %q", ErrInvalidQR, value)
+}
+
+// parseAmount принимает сумму в рублях с точкой, а также с запятой, которую
+// пишут некоторые кассы.
+func parseAmount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("%w: bad amount %q", ErrInvalidQR, value)
+	}
+	return amount, nil
+}
+
+// isDigits проверяет, что s состоит только из цифр и имеет длину от minLen до maxLen.
+func isDigits(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package jwtkeys
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"finance-backend/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ParsePrivateKey разбирает закрытый ключ RSA в PEM. Допускаются экранированные
+// переводы строк (\n), как принято хранить ключи в переменных окружения.
+func ParsePrivateKey(data string) (*rsa.PrivateKey, error) {
+	return jwt.ParseRSAPrivateKeyFromPEM([]byte(unescapeNewlines(data)))
+}
+
+// ParsePublicKeys разбирает один или несколько открытых ключей RSA, записанных подряд в PEM.
+func ParsePublicKeys(data string) ([]*rsa.PublicKey, error) {
+	rest := []byte(unescapeNewlines(data))
+	var keys []*rsa.PublicKey
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 && strings.TrimSpace(string(rest)) != "" {
+		return nil, errors.New("no PEM public keys found")
+	}
+	return keys, nil
+}
+
+// LoadDir читает ключи из файлов *.pem каталога. Ключом подписи становится закрытый ключ
+// из файла, последнего по имени (удобно называть файлы датой выпуска ключа); остальные
+// закрытые ключи и все открытые ключи используются только для проверки.
+func LoadDir(dir string) (*rsa.PrivateKey, []*rsa.PublicKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+
+	var signing *rsa.PrivateKey
+	var verification []*rsa.PublicKey
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, nil, fmt.Errorf("%s: not a PEM file", path)
+		}
+
+		if strings.Contains(block.Type, "PRIVATE KEY") {
+			key, err := ParsePrivateKey(string(data))
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if signing != nil {
+				verification = append(verification, &signing.PublicKey)
+			}
+			signing = key
+			continue
+		}
+
+		keys, err := ParsePublicKeys(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		verification = append(verification, keys...)
+	}
+
+	if signing == nil {
+		return nil, nil, fmt.Errorf("%s: %w", dir, ErrNoSigningKey)
+	}
+	return signing, verification, nil
+}
+
+// WatchDir периодически перечитывает каталог ключей, пока не отменен ctx.
+// Ошибка чтения не сбрасывает уже загруженные ключи.
+func (m *KeyManager) WatchDir(ctx context.Context, dir string, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		signing, verification, err := LoadDir(dir)
+		if err != nil {
+			log.Error(ctx, "failed to reload jwt keys", map[string]interface{}{"error": err.Error(), "dir": dir})
+			continue
+		}
+
+		before, beforeIDs := m.SigningKeyID(), m.KeyIDs()
+		if err := m.SetKeys(signing, verification); err != nil {
+			log.Error(ctx, "failed to reload jwt keys", map[string]interface{}{"error": err.Error(), "dir": dir})
+			continue
+		}
+		if m.SigningKeyID() != before || !slices.Equal(m.KeyIDs(), beforeIDs) {
+			log.Info(ctx, "jwt_keys_reloaded", map[string]interface{}{"signing_kid": m.SigningKeyID(), "kids": m.KeyIDs()})
+		}
+	}
+}
+
+func unescapeNewlines(data string) string {
+	return strings.ReplaceAll(data, `\n`, "\n")
+}
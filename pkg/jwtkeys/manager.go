@@ -0,0 +1,181 @@
+// Package jwtkeys хранит ключи подписи JWT и публикует их в формате JWKS.
+//
+// Токены подписываются текущим ключом и несут его идентификатор (kid) в заголовке,
+// проверка выбирает открытый ключ по kid. Поэтому ключ можно сменить без простоя:
+// сначала новый открытый ключ добавляется в проверочные на всех экземплярах, затем он
+// становится ключом подписи, а старый остается проверочным, пока не истекут его токены.
+package jwtkeys
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrNoSigningKey — ключ подписи не задан.
+	ErrNoSigningKey = errors.New("jwt signing key is not configured")
+
+	// ErrMissingKeyID — в заголовке токена нет kid.
+	ErrMissingKeyID = errors.New("jwt has no kid header")
+
+	// ErrUnknownKey — токен подписан ключом, которого нет среди проверочных.
+	ErrUnknownKey = errors.New("jwt is signed with unknown key")
+
+	// ErrUnexpectedSigningMethod — токен подписан не RSA.
+	ErrUnexpectedSigningMethod = errors.New("unexpected jwt signing method")
+)
+
+// KeyManager — набор ключей: один ключ подписи и любое число проверочных.
+// Безопасен для одновременного использования; набор заменяется целиком через SetKeys.
+type KeyManager struct {
+	mu         sync.RWMutex
+	signingKID string
+	signingKey *rsa.PrivateKey
+	publicKeys map[string]*rsa.PublicKey
+}
+
+func NewKeyManager() *KeyManager {
+	return &KeyManager{publicKeys: map[string]*rsa.PublicKey{}}
+}
+
+// SetKeys заменяет набор ключей. Открытая часть ключа подписи добавляется к проверочным.
+func (m *KeyManager) SetKeys(signing *rsa.PrivateKey, verification []*rsa.PublicKey) error {
+	if signing == nil {
+		return ErrNoSigningKey
+	}
+
+	signingKID := KeyID(&signing.PublicKey)
+	publicKeys := map[string]*rsa.PublicKey{signingKID: &signing.PublicKey}
+	for _, key := range verification {
+		publicKeys[KeyID(key)] = key
+	}
+
+	m.mu.Lock()
+	m.signingKID = signingKID
+	m.signingKey = signing
+	m.publicKeys = publicKeys
+	m.mu.Unlock()
+
+	return nil
+}
+
+// SigningKeyID возвращает kid текущего ключа подписи.
+func (m *KeyManager) SigningKeyID() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.signingKID
+}
+
+// KeyIDs возвращает отсортированный список kid всех проверочных ключей.
+func (m *KeyManager) KeyIDs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.publicKeys))
+	for kid := range m.publicKeys {
+		ids = append(ids, kid)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Sign подписывает claims текущим ключом (RS256) и проставляет kid в заголовок.
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	kid, key := m.signingKID, m.signingKey
+	m.mu.RUnlock()
+
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
+}
+
+// Keyfunc выбирает открытый ключ для проверки токена по его kid.
+func (m *KeyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, ErrUnexpectedSigningMethod
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrMissingKeyID
+	}
+
+	m.mu.RLock()
+	key, ok := m.publicKeys[kid]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKS возвращает проверочные ключи в формате JSON Web Key Set (RFC 7517).
+func (m *KeyManager) JWKS() ([]byte, error) {
+	m.mu.RLock()
+	set := jwkSet{Keys: make([]jwk, 0, len(m.publicKeys))}
+	for kid, key := range m.publicKeys {
+		n, e := encodePublicKey(key)
+		set.Keys = append(set.Keys, jwk{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: kid, N: n, E: e})
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return json.Marshal(set)
+}
+
+// ServeHTTP отдает JWKS. Кэш короткий, чтобы клиенты быстро узнавали о новых ключах.
+func (m *KeyManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := m.JWKS()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(body)
+}
+
+// KeyID вычисляет kid как отпечаток ключа по RFC 7638, поэтому он одинаков
+// на всех экземплярах сервиса и не требует отдельной настройки.
+func KeyID(key *rsa.PublicKey) string {
+	n, e := encodePublicKey(key)
+	// Поля в каноническом виде: только обязательные и в лексикографическом порядке.
+	canonical := `{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func encodePublicKey(key *rsa.PublicKey) (n, e string) {
+	n = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	return n, e
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"finance-backend/pkg/utils"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPMiddleware кладет в контекст адрес клиента. X-Forwarded-For и X-Real-IP
+// учитываются только при trustProxyHeaders: без прокси клиент может подставить их сам.
+func ClientIPMiddleware(trustProxyHeaders bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), utils.ContextKeyClientIP, clientIP(r, trustProxyHeaders))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func clientIP(r *http.Request, trustProxyHeaders bool) string {
+	if trustProxyHeaders {
+		// Первый адрес в X-Forwarded-For — клиент, остальные добавлены прокси по пути.
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			if ip := net.ParseIP(strings.TrimSpace(first)); ip != nil {
+				return ip.String()
+			}
+		}
+		if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+			return ip.String()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -2,83 +2,166 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
-	delivery "finance-backend/internal/delivery/http"
+	"errors"
 	"finance-backend/internal/domain"
 	"finance-backend/pkg/logger"
 	"finance-backend/pkg/utils"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func JWTParserMiddleware(next http.Handler) http.Handler {
+// SessionChecker сообщает, отозван ли токен доступа с данным jti и заблокирован ли пользователь.
+type SessionChecker interface {
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	IsUserBlocked(ctx context.Context, login string) (bool, error)
+}
+
+// APIKeyAuthenticator находит пользователя по ключу API; nil, nil — ключ недействителен.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.User, error)
+}
+
+// JWTParserMiddleware проверяет подпись токена доступа ключом, выбранным keyfunc
+// (по kid из заголовка), и отклоняет отозванные токены и токены заблокированных пользователей.
+// Вместо токена можно передать ключ API (заголовок X-API-Key или Authorization: Bearer fin_...),
+// его проверяет apiKeys.
+func JWTParserMiddleware(keyfunc jwt.Keyfunc, sessions SessionChecker, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return jwtParser(next, keyfunc, sessions, apiKeys)
+	}
+}
+
+func jwtParser(next http.Handler, keyfunc jwt.Keyfunc, sessions SessionChecker, apiKeys APIKeyAuthenticator) http.Handler {
+	log := logger.NewLogger()
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		publicKey := strings.ReplaceAll(os.Getenv("JWT_PUBLIC"), `\n`, "\n")
+		if key := apiKeyFromRequest(r); key != "" && apiKeys != nil {
+			user, err := apiKeys.AuthenticateAPIKey(r.Context(), key)
+			if errors.Is(err, domain.ErrUserBlocked) {
+				writeError(w, http.StatusForbidden, "user is blocked")
+				return
+			}
+			if err != nil {
+				log.Error(r.Context(), "error on checking api key", map[string]interface{}{"error": err.Error()})
+				writeError(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			if user == nil {
+				writeError(w, http.StatusUnauthorized, "invalid api key")
+				return
+			}
 
-		log := logger.NewLogger()
+			ctx := context.WithValue(r.Context(), utils.ContextKeyUser, *user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
 
 		tokenString := r.Header.Get("Authorization")
 
 		if tokenString == "" {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "no auth token in request"})
+			writeError(w, http.StatusUnauthorized, "no auth token in request")
 			return
 		}
 
 		if !strings.HasPrefix(tokenString, "Bearer ") {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "invalid token format"})
+			writeError(w, http.StatusUnauthorized, "invalid token format")
 			return
 		}
 
 		jwtString := strings.Split(tokenString, "Bearer ")[1]
 
-		token, err := jwt.Parse(jwtString, func(token *jwt.Token) (interface{}, error) {
+		token, err := jwt.Parse(jwtString, keyfunc, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
 
-			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-				log.Error(r.Context(), "error on parsing jwt token", nil)
-				return nil, delivery.ErrJwtTokenParsing
-			}
+		if err != nil {
+			log.Warn(r.Context(), "error on parsing jwt token", map[string]interface{}{"error": err.Error()})
+			writeError(w, http.StatusUnauthorized, "error on parsing jwt token")
+			return
+		}
 
-			parsedSecter, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKey))
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			// Без jti токен нельзя отозвать, такие токены не принимаем.
+			tokenID, _ := claims["jti"].(string)
+			if tokenID == "" {
+				writeError(w, http.StatusUnauthorized, "token has no id")
+				return
+			}
 
+			revoked, err := sessions.IsTokenRevoked(r.Context(), tokenID)
 			if err != nil {
-				return nil, err
+				log.Error(r.Context(), "error on checking jwt token revocation", map[string]interface{}{"error": err.Error()})
+				writeError(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			if revoked {
+				writeError(w, http.StatusUnauthorized, "token has been revoked")
+				return
 			}
 
-			return parsedSecter, nil
-		})
+			userLogin, _ := claims["sub"].(string)
+			blocked, err := sessions.IsUserBlocked(r.Context(), userLogin)
+			if err != nil {
+				log.Error(r.Context(), "error on checking user status", map[string]interface{}{"error": err.Error()})
+				writeError(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			if blocked {
+				writeError(w, http.StatusForbidden, "user is blocked")
+				return
+			}
 
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "error on parsing jwt token"})
-			return
-		}
+			user := domain.NewUser(userLogin, roleFromClaims(claims))
+			user.OrgID, user.OrgRole = orgFromClaims(claims)
+			ctx := context.WithValue(r.Context(), utils.ContextKeyUser, user)
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			var ctx context.Context
-			if claims["is_admin"] != "" {
-				userLogin, _ := claims["sub"].(string)
-				isAdmin, _ := claims["is_admin"].(bool)
-				user := domain.User{
-					Login:   userLogin,
-					IsAdmin: isAdmin,
-				}
-				ctx = context.WithValue(r.Context(), utils.ContextKeyUser, user)
-			} else {
-				ctx = context.WithValue(r.Context(), utils.ContextKeyUser, nil)
+			expiresAt, _ := claims.GetExpirationTime()
+			tokenClaims := domain.TokenClaims{ID: tokenID, Login: userLogin, OrgID: user.OrgID}
+			if expiresAt != nil {
+				tokenClaims.ExpiresAt = expiresAt.Time
 			}
+			ctx = context.WithValue(ctx, utils.ContextKeyToken, tokenClaims)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		} else {
 			log.Error(r.Context(), "error on getting jwt token claims", nil)
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "error on parsing jwt token"})
+			writeError(w, http.StatusUnauthorized, "error on parsing jwt token")
 			return
 		}
 	})
 }
+
+// roleFromClaims читает роль из токена. В токенах, выданных до появления ролей,
+// есть только признак is_admin.
+func roleFromClaims(claims jwt.MapClaims) domain.Role {
+	if role, _ := claims["role"].(string); role != "" {
+		return domain.Role(role)
+	}
+	if isAdmin, _ := claims["is_admin"].(bool); isAdmin {
+		return domain.RoleAdmin
+	}
+	return domain.RoleUser
+}
+
+// orgFromClaims читает активную организацию сессии; без нее запрос работает с личным учетом.
+func orgFromClaims(claims jwt.MapClaims) (int64, domain.OrgRole) {
+	orgID, _ := claims["org"].(float64)
+	role, _ := claims["org_role"].(string)
+	if orgID <= 0 || !domain.OrgRole(role).IsValid() {
+		return 0, ""
+	}
+	return int64(orgID), domain.OrgRole(role)
+}
+
+// apiKeyFromRequest возвращает ключ API из X-API-Key или из Authorization, если там
+// вместо JWT передан ключ (скрипты и интеграции часто умеют только Bearer).
+func apiKeyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && strings.HasPrefix(bearer, domain.APIKeyPrefix) {
+		return strings.TrimSpace(bearer)
+	}
+	return ""
+}
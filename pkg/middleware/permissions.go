@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"encoding/json"
+	"finance-backend/internal/domain"
+	"finance-backend/pkg/utils"
+	"net/http"
+)
+
+// RequirePermission пропускает запрос, только если у пользователя есть все перечисленные права.
+// Ставится после JWTParserMiddleware: без пользователя в контексте ответ 401, без прав — 403.
+func RequirePermission(perms ...domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := utils.GetUserFromContext(r.Context())
+			if !ok || user.Login == "" {
+				writeError(w, http.StatusUnauthorized, "authentication required")
+				return
+			}
+
+			for _, perm := range perms {
+				if !user.Can(perm) {
+					writeError(w, http.StatusForbidden, "permission denied: "+string(perm))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession пропускает только запросы с токеном доступа. Ставится на маршруты
+// управления учетной записью: ключ API не должен выходить за пределы своих областей.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := utils.GetUserFromContext(r.Context())
+		if !ok || user.Login == "" {
+			writeError(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		if user.ViaAPIKey() {
+			writeError(w, http.StatusForbidden, "api keys are not allowed here")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeError отдает ошибку аутентификации или авторизации в едином формате {"error": ...}.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
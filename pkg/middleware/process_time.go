@@ -20,7 +20,9 @@ func (rw *responseWriterWrapper) WriteHeader(statusCode int) {
 	processTime := time.Since(rw.start)
 	rw.Duration = strconv.FormatFloat(processTime.Seconds(), 'f', 6, 64)
 	rw.ResponseWriter.Header().Set("X-Process-Time", rw.Duration)
-	rw.ResponseWriter.Header().Set("Content-Type", "application/json")
+	if rw.ResponseWriter.Header().Get("Content-Type") == "" {
+		rw.ResponseWriter.Header().Set("Content-Type", "application/json")
+	}
 	rw.ResponseWriter.WriteHeader(statusCode)
 
 }
@@ -0,0 +1,52 @@
+// Package qrdecode распознает QR-коды на изображениях.
+//
+// Распознавание выполняет gozxing (порт ZXing); пакет только подбирает бинаризацию
+// и приводит ошибки к двум случаям: код не найден или найден, но не читается.
+package qrdecode
+
+import (
+	"errors"
+	"image"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+var (
+	// ErrNotFound — на изображении не найден QR-код.
+	ErrNotFound = errors.New("qr code not found")
+
+	// ErrUnreadable — QR-код найден, но поврежден сильнее, чем позволяет коррекция ошибок.
+	ErrUnreadable = errors.New("qr code is unreadable")
+)
+
+var hints = map[gozxing.DecodeHintType]interface{}{
+	gozxing.DecodeHintType_TRY_HARDER: true,
+}
+
+// Decode находит на изображении QR-код и возвращает его содержимое. Сначала пробуется
+// локальная бинаризация, устойчивая к неравномерному освещению фото, затем глобальная —
+// для контрастных сканов и скриншотов.
+func Decode(img image.Image) (string, error) {
+	source := gozxing.NewLuminanceSourceFromImage(img)
+
+	result := ErrNotFound
+	for _, binarizer := range []func(gozxing.LuminanceSource) gozxing.Binarizer{
+		gozxing.NewHybridBinarizer,
+		gozxing.NewGlobalHistgramBinarizer,
+	} {
+		bitmap, err := gozxing.NewBinaryBitmap(binarizer(source))
+		if err != nil {
+			return "", err
+		}
+
+		decoded, err := qrcode.NewQRCodeReader().Decode(bitmap, hints)
+		if err == nil {
+			return decoded.GetText(), nil
+		}
+		if _, notFound := err.(gozxing.NotFoundException); !notFound {
+			result = ErrUnreadable
+		}
+	}
+	return "", result
+}
@@ -0,0 +1,93 @@
+package qrdecode
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// receiptQR — содержимое QR-кода кассового чека в формате ФНС.
+const receiptQR = "t=20240315T1430&s=1234.56&fn=9289000100123456&i=12345&fp=1234567890&n=1"
+
+func encode(t *testing.T, content string, level qrcode.RecoveryLevel, size int) image.Image {
+	t.Helper()
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q.Image(size)
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		level   qrcode.RecoveryLevel
+		size    int
+	}{
+		{name: "receipt", content: receiptQR, level: qrcode.Medium, size: 256},
+		{name: "numeric", content: "0123456789012345678901234567890", level: qrcode.Low, size: 128},
+		{name: "alphanumeric", content: "ST00012|NAME:OOO ROMASHKA", level: qrcode.High, size: 200},
+		{name: "utf8", content: "ST00012|Name=ООО «Ромашка»|PersonalAcc=40702810938000000001", level: qrcode.Medium, size: 300},
+		{name: "large version", content: strings.Repeat("fn=9289000100123456&", 30), level: qrcode.Highest, size: 600},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Decode(encode(t, c.content, c.level, c.size))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got != c.content {
+				t.Fatalf("Decode() = %q, want %q", got, c.content)
+			}
+		})
+	}
+}
+
+func TestDecodeOnPhotoBackground(t *testing.T) {
+	code := encode(t, receiptQR, qrcode.Medium, 256)
+
+	// Код в середине серого снимка, как на фото чека.
+	img := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.Gray{Y: 170}}, image.Point{}, draw.Src)
+	draw.Draw(img, code.Bounds().Add(image.Pt(300, 150)), code, image.Point{}, draw.Src)
+
+	got, err := Decode(img)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got != receiptQR {
+		t.Fatalf("Decode() = %q, want %q", got, receiptQR)
+	}
+}
+
+func TestDecodeDamaged(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	draw.Draw(img, img.Bounds(), encode(t, receiptQR, qrcode.High, 256), image.Point{}, draw.Src)
+
+	// Пятно на части данных: уровень коррекции High восстанавливает до 30% кодовых слов.
+	draw.Draw(img, image.Rect(150, 150, 175, 175), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+
+	got, err := Decode(img)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got != receiptQR {
+		t.Fatalf("Decode() = %q, want %q", got, receiptQR)
+	}
+}
+
+func TestDecodeNotFound(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 200, 200))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+
+	if _, err := Decode(img); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Decode() error = %v, want ErrNotFound", err)
+	}
+}
@@ -11,6 +11,8 @@ func (c contextKey) String() string {
 const (
 	ContextKeyRequestId = contextKey("RequestID")
 	ContextKeyUser      = contextKey("User")
+	ContextKeyToken     = contextKey("Token")
+	ContextKeyClientIP  = contextKey("ClientIP")
 )
 
 func GetRequestIDFromContext(ctx context.Context) (string, bool) {
@@ -20,3 +22,11 @@ func GetRequestIDFromContext(ctx context.Context) (string, bool) {
 	requestID, ok := ctx.Value(ContextKeyRequestId).(string)
 	return requestID, ok
 }
+
+func GetClientIPFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	ip, ok := ctx.Value(ContextKeyClientIP).(string)
+	return ip, ok
+}
@@ -9,3 +9,13 @@ func CheckIsAdmin(context context.Context) bool {
 	user, ok := context.Value(ContextKeyUser).(domain.User)
 	return ok && user.IsAdmin
 }
+
+func GetUserFromContext(context context.Context) (domain.User, bool) {
+	user, ok := context.Value(ContextKeyUser).(domain.User)
+	return user, ok
+}
+
+func GetTokenClaimsFromContext(context context.Context) (domain.TokenClaims, bool) {
+	claims, ok := context.Value(ContextKeyToken).(domain.TokenClaims)
+	return claims, ok
+}
@@ -0,0 +1,53 @@
+package validation
+
+import "strings"
+
+const russianCountryCode = "+7"
+
+// NormalizePhone приводит российский номер к формату E.164 (+7XXXXXXXXXX).
+//
+// Принимаются номера вида 8XXXXXXXXXX, +7XXXXXXXXXX, 7XXXXXXXXXX и 10-значные
+// номера без кода страны, в том числе с пробелами, скобками и дефисами.
+func NormalizePhone(phone string) (string, bool) {
+	phone = strings.TrimSpace(phone)
+	hasPlus := strings.HasPrefix(phone, "+")
+	if hasPlus {
+		phone = phone[1:]
+	}
+
+	var b strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return "", false
+		}
+	}
+	digits := b.String()
+
+	switch {
+	case len(digits) == 11 && digits[0] == '7':
+		return russianCountryCode + digits[1:], true
+	case len(digits) == 11 && digits[0] == '8' && !hasPlus:
+		return russianCountryCode + digits[1:], true
+	case len(digits) == 10 && !hasPlus:
+		return russianCountryCode + digits, true
+	}
+	return "", false
+}
+
+// NormalizePhoneOrKeep возвращает номер в E.164 или исходную строку, если номер не распознан.
+func NormalizePhoneOrKeep(phone string) string {
+	if normalized, ok := NormalizePhone(phone); ok {
+		return normalized
+	}
+	return phone
+}
+
+// IsValidPhone проверяет, что номер можно привести к E.164.
+func IsValidPhone(phone string) bool {
+	_, ok := NormalizePhone(phone)
+	return ok
+}
@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+)
+
+const correspondentAccountPrefix = "301"
+
+var (
+	kppPattern = regexp.MustCompile(`^\d{4}[\dA-Z]{2}\d{3}$`)
+	bicPattern = regexp.MustCompile(`^04\d{7}$`)
+)
+
+var (
+	inn10Weights   = []int{2, 4, 10, 3, 5, 9, 4, 6, 8}
+	inn12Weights1  = []int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}
+	inn12Weights2  = []int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}
+	accountWeights = []int{7, 1, 3}
+)
+
+// IsValidINN проверяет ИНН юрлица (10 цифр) или физлица (12 цифр) по контрольным разрядам.
+func IsValidINN(inn string) bool {
+	digits, ok := toDigits(inn)
+	if !ok {
+		return false
+	}
+
+	switch len(digits) {
+	case 10:
+		return innControlDigit(digits, inn10Weights) == digits[9]
+	case 12:
+		return innControlDigit(digits, inn12Weights1) == digits[10] &&
+			innControlDigit(digits, inn12Weights2) == digits[11]
+	default:
+		return false
+	}
+}
+
+// IsValidLegalINN проверяет 10-значный ИНН юридического лица.
+func IsValidLegalINN(inn string) bool {
+	return len(inn) == 10 && IsValidINN(inn)
+}
+
+// IsValidIndividualINN проверяет 12-значный ИНН физического лица или ИП.
+func IsValidIndividualINN(inn string) bool {
+	return len(inn) == 12 && IsValidINN(inn)
+}
+
+// IsValidKPP проверяет формат КПП: код налогового органа, причина постановки и порядковый номер.
+func IsValidKPP(kpp string) bool {
+	return kppPattern.MatchString(kpp)
+}
+
+// IsValidBIC проверяет формат БИК банка РФ (9 цифр, код страны 04).
+func IsValidBIC(bic string) bool {
+	return bicPattern.MatchString(bic)
+}
+
+// IsValidAccount проверяет контрольный ключ 20-значного счета относительно БИК банка.
+func IsValidAccount(account, bic string) bool {
+	if !IsValidBIC(bic) || len(account) != 20 {
+		return false
+	}
+
+	accountDigits, ok := toDigits(account)
+	if !ok {
+		return false
+	}
+
+	// Корреспондентский счет проверяется по коду подразделения Банка России
+	// из БИК, расчетный — по условному номеру кредитной организации.
+	prefix := bic[6:]
+	if strings.HasPrefix(account, correspondentAccountPrefix) {
+		prefix = "0" + bic[4:6]
+	}
+
+	prefixDigits, _ := toDigits(prefix)
+	digits := append(prefixDigits, accountDigits...)
+
+	sum := 0
+	for i, d := range digits {
+		sum += (d * accountWeights[i%len(accountWeights)]) % 10
+	}
+
+	return sum%10 == 0
+}
+
+func innControlDigit(digits []int, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += digits[i] * w
+	}
+	return sum % 11 % 10
+}
+
+func toDigits(s string) ([]int, bool) {
+	if s == "" {
+		return nil, false
+	}
+
+	digits := make([]int, len(s))
+	for i, r := range s {
+		if r < '0' || r > '9' {
+			return nil, false
+		}
+		digits[i] = int(r - '0')
+	}
+	return digits, true
+}
@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// Теги валидации реквизитов, доступные в схемах запросов.
+const (
+	TagINN           = "inn"
+	TagLegalINN      = "inn_legal"
+	TagIndividualINN = "inn_individual"
+	TagKPP           = "kpp"
+	TagBIC           = "bic"
+	TagAccount       = "account_key"
+	TagPhone         = "ru_phone"
+)
+
+// New создает валидатор с зарегистрированными проверками банковских реквизитов.
+func New() *validator.Validate {
+	validate := validator.New()
+	RegisterValidations(validate)
+	return validate
+}
+
+// RegisterValidations регистрирует теги реквизитов в переданном валидаторе.
+//
+// Тег account_key принимает имя поля с БИК: `validate:"account_key=BIC"`.
+// Счет без БИК не проходит проверку: без БИК нельзя вычислить контрольный ключ.
+func RegisterValidations(validate *validator.Validate) {
+	validate.RegisterValidation(TagINN, stringValidation(IsValidINN))
+	validate.RegisterValidation(TagLegalINN, stringValidation(IsValidLegalINN))
+	validate.RegisterValidation(TagIndividualINN, stringValidation(IsValidIndividualINN))
+	validate.RegisterValidation(TagKPP, stringValidation(IsValidKPP))
+	validate.RegisterValidation(TagBIC, stringValidation(IsValidBIC))
+	validate.RegisterValidation(TagAccount, validateAccountKey)
+	validate.RegisterValidation(TagPhone, stringValidation(IsValidPhone))
+}
+
+func stringValidation(check func(string) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return check(fl.Field().String())
+	}
+}
+
+func validateAccountKey(fl validator.FieldLevel) bool {
+	bicField, _, _, ok := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+	if !ok || bicField.String() == "" {
+		return false
+	}
+	return IsValidAccount(fl.Field().String(), bicField.String())
+}